@@ -3,15 +3,40 @@ package config
 import (
 	"fmt"
 	"github.com/caarlos0/env/v11"
+	"slices"
+	"time"
 )
 
 type Config struct {
-	Environment string `env:"ENVIRONMENT" envDefault:"dev"`
-	DebugMode   bool   `env:"DEBUG_MODE" envDefault:"false"`
-	DbConfig    DatabaseConfig
-	Port        int    `env:"PORT" envDefault:"8080"`
-	JWTSecret   string `env:"JWT_SECRET" envDefault:"secret"`
-	Migrate     bool   `env:"MIGRATE" envDefault:"true"`
+	Environment   string `env:"ENVIRONMENT" envDefault:"dev"`
+	DebugMode     bool   `env:"DEBUG_MODE" envDefault:"false"`
+	DbConfig      DatabaseConfig
+	Port          int           `env:"PORT" envDefault:"8080"`
+	JWTSecret     string        `env:"JWT_SECRET" envDefault:"secret"`
+	JWTAccessTTL  time.Duration `env:"JWT_ACCESS_TTL" envDefault:"15m"`
+	JWTRefreshTTL time.Duration `env:"JWT_REFRESH_TTL" envDefault:"720h"`
+	Migrate       bool          `env:"MIGRATE" envDefault:"true"`
+	JWTKeys       JWTKeyConfig
+	RatingConfig  RatingConfig
+	MovieCache    MovieCacheConfig
+	RedisConfig   RedisConfig
+	Mail          MailConfig
+	Account       AccountConfig
+	Login         LoginConfig
+	TwoFactor     TwoFactorConfig
+	APIKeys       APIKeyConfig
+	OIDC          OIDCConfig
+	Password      PasswordConfig
+	Proxy         ProxyConfig
+}
+
+// JWTKeyConfig configures the key ring access tokens are signed with. Each key signs for Rotation
+// and is published in the JWKS until the tokens it signed have expired. Every replica reloads the
+// keys every RefreshInterval. Private keys are encrypted with JWT_SECRET.
+type JWTKeyConfig struct {
+	Algorithm       string        `env:"JWT_ALGORITHM" envDefault:"EdDSA"`
+	Rotation        time.Duration `env:"JWT_KEY_ROTATION" envDefault:"720h"`
+	RefreshInterval time.Duration `env:"JWT_KEY_REFRESH_INTERVAL" envDefault:"1m"`
 }
 
 type DatabaseConfig struct {
@@ -23,6 +48,120 @@ type DatabaseConfig struct {
 	SSLMode  string `env:"DB_SSLMODE" envDefault:"disable"`
 }
 
+// RatingConfig tunes the Bayesian weighted rating: a movie's score is pulled towards the prior mean
+// until it has collected around MinVotes ratings. The prior is the global mean of all ratings unless
+// PriorMean pins it to a fixed value, which may be 0.
+type RatingConfig struct {
+	PriorMean *float64 `env:"RATING_PRIOR_MEAN"`
+	MinVotes  int      `env:"RATING_MIN_VOTES" envDefault:"10"`
+}
+
+// MovieCacheConfig configures the movie cache. Backend "memory" keeps a bounded cache per replica,
+// kept in sync through Postgres notifications; "redis" shares one cache between all replicas. Not-found
+// IDs are cached for NegativeTTL so repeated lookups of missing movies do not reach the database.
+type MovieCacheConfig struct {
+	Backend       string        `env:"MOVIE_CACHE_BACKEND" envDefault:"memory"`
+	Size          int           `env:"MOVIE_CACHE_SIZE" envDefault:"10000"`
+	TTL           time.Duration `env:"MOVIE_CACHE_TTL" envDefault:"30s"`
+	NegativeTTL   time.Duration `env:"MOVIE_CACHE_NEGATIVE_TTL" envDefault:"5s"`
+	SweepInterval time.Duration `env:"MOVIE_CACHE_SWEEP_INTERVAL" envDefault:"1m"`
+}
+
+type RedisConfig struct {
+	Addr     string `env:"REDIS_ADDR" envDefault:"localhost:6379"`
+	Password string `env:"REDIS_PASSWORD" envDefault:""`
+	DB       int    `env:"REDIS_DB" envDefault:"0"`
+}
+
+// MailConfig selects how mail is sent. Backend "smtp" delivers through an SMTP server, using
+// STARTTLS when the server offers it; "file" writes every message to Dir as an .eml file and "log"
+// only logs it, both meant for local development.
+type MailConfig struct {
+	Backend      string `env:"MAIL_BACKEND" envDefault:"log"`
+	From         string `env:"MAIL_FROM" envDefault:"no-reply@movie-rating.local"`
+	Dir          string `env:"MAIL_DIR" envDefault:"mail"`
+	SMTPHost     string `env:"SMTP_HOST" envDefault:"localhost"`
+	SMTPPort     int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME" envDefault:""`
+	SMTPPassword string `env:"SMTP_PASSWORD" envDefault:""`
+}
+
+// AccountConfig configures the tokens mailed for password resets and email verification. With
+// LinkBaseURL set, mails link to LinkBaseURL/password/reset?token=... and
+// LinkBaseURL/user/verify?token=... on the frontend; otherwise they only contain the token. With
+// RequireVerifiedEmail, users cannot rate until they verified their email.
+type AccountConfig struct {
+	LinkBaseURL          string        `env:"ACCOUNT_LINK_BASE_URL" envDefault:""`
+	PasswordResetTTL     time.Duration `env:"PASSWORD_RESET_TTL" envDefault:"1h"`
+	EmailVerificationTTL time.Duration `env:"EMAIL_VERIFICATION_TTL" envDefault:"72h"`
+	RequireVerifiedEmail bool          `env:"REQUIRE_VERIFIED_EMAIL" envDefault:"false"`
+}
+
+// LoginConfig throttles password guessing. Once a username has MaxFailures failed logins, or a
+// client address MaxIPFailures, its logins are refused for Lockout, doubling with every further
+// failure up to MaxLockout. Failures are forgotten after Window without a failure or lockout.
+type LoginConfig struct {
+	MaxFailures   int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	MaxIPFailures int           `env:"LOGIN_MAX_IP_FAILURES" envDefault:"50"`
+	Lockout       time.Duration `env:"LOGIN_LOCKOUT" envDefault:"1m"`
+	MaxLockout    time.Duration `env:"LOGIN_MAX_LOCKOUT" envDefault:"1h"`
+	Window        time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+}
+
+// TwoFactorConfig configures TOTP two-factor authentication. Issuer names the service in
+// authenticator apps. Users holding one of RequiredRoles get tokens that grant nothing until they
+// enabled it. A login challenge has to be answered with a code within ChallengeTTL.
+type TwoFactorConfig struct {
+	Issuer        string        `env:"TWO_FACTOR_ISSUER" envDefault:"Movie Rating Service"`
+	RequiredRoles []string      `env:"TWO_FACTOR_REQUIRED_ROLES" envSeparator:","`
+	ChallengeTTL  time.Duration `env:"TWO_FACTOR_CHALLENGE_TTL" envDefault:"5m"`
+}
+
+// APIKeyConfig bounds the lifetime of API keys: a key created without an expiry expires after
+// DefaultTTL, and none may be valid for longer than MaxTTL.
+type APIKeyConfig struct {
+	DefaultTTL time.Duration `env:"API_KEY_DEFAULT_TTL" envDefault:"2160h"`
+	MaxTTL     time.Duration `env:"API_KEY_MAX_TTL" envDefault:"8760h"`
+}
+
+// OIDCConfig enables login through an OpenID Connect provider when Issuer is set. RedirectURL is the
+// callback URL registered with the provider; ClientSecret may be empty for a public client. GroupRoles
+// maps groups in the GroupsClaim of the ID token to roles, e.g. "movie-admins:admin,movie-curators:curator".
+// A login has to be completed within LoginTTL.
+type OIDCConfig struct {
+	Issuer       string            `env:"OIDC_ISSUER" envDefault:""`
+	ClientID     string            `env:"OIDC_CLIENT_ID" envDefault:""`
+	ClientSecret string            `env:"OIDC_CLIENT_SECRET" envDefault:""`
+	RedirectURL  string            `env:"OIDC_REDIRECT_URL" envDefault:""`
+	Scopes       []string          `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,profile,email"`
+	GroupsClaim  string            `env:"OIDC_GROUPS_CLAIM" envDefault:"groups"`
+	GroupRoles   map[string]string `env:"OIDC_GROUP_ROLES"`
+	LoginTTL     time.Duration     `env:"OIDC_LOGIN_TTL" envDefault:"10m"`
+}
+
+// PasswordConfig configures password hashing and the policy for new passwords. New hashes use
+// Algorithm, argon2id (Argon2Memory in KiB) or bcrypt; a stored hash made with another algorithm
+// or other parameters is replaced at the user's next login. New passwords must be MinLength to
+// MaxLength characters long and must not appear in BreachedList, a file with one password or
+// SHA-1 hash per line.
+type PasswordConfig struct {
+	Algorithm     string `env:"PASSWORD_HASH_ALGORITHM" envDefault:"argon2id"`
+	Argon2Memory  uint32 `env:"PASSWORD_ARGON2_MEMORY" envDefault:"19456"`
+	Argon2Time    uint32 `env:"PASSWORD_ARGON2_TIME" envDefault:"2"`
+	Argon2Threads uint8  `env:"PASSWORD_ARGON2_THREADS" envDefault:"1"`
+	BcryptCost    int    `env:"PASSWORD_BCRYPT_COST" envDefault:"10"`
+	MinLength     int    `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	MaxLength     int    `env:"PASSWORD_MAX_LENGTH" envDefault:"72"`
+	BreachedList  string `env:"PASSWORD_BREACHED_LIST" envDefault:""`
+}
+
+// ProxyConfig tells where the client address comes from behind a reverse proxy. Header (e.g.
+// X-Forwarded-For) is only trusted on requests from TrustedProxies, IPs or CIDR ranges.
+type ProxyConfig struct {
+	Header         string   `env:"PROXY_HEADER" envDefault:""`
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:","`
+}
+
 var Cfg Config
 
 func Init() error {
@@ -30,5 +169,71 @@ func Init() error {
 	if err != nil {
 		return fmt.Errorf("error occurred while parsing environment variables: %w", err)
 	}
+	if Cfg.Environment != "dev" && Cfg.JWTSecret == "secret" {
+		return fmt.Errorf("JWT_SECRET must be changed from its default outside the dev environment")
+	}
+	if Cfg.JWTKeys.Algorithm != "RS256" && Cfg.JWTKeys.Algorithm != "EdDSA" {
+		return fmt.Errorf("JWT_ALGORITHM must be RS256 or EdDSA")
+	}
+	if Cfg.JWTKeys.Rotation <= 0 || Cfg.JWTKeys.RefreshInterval <= 0 {
+		return fmt.Errorf("JWT_KEY_ROTATION and JWT_KEY_REFRESH_INTERVAL must be positive")
+	}
+	if prior := Cfg.RatingConfig.PriorMean; prior != nil && (*prior < 0 || *prior > 5) {
+		return fmt.Errorf("RATING_PRIOR_MEAN must be between 0 and 5")
+	}
+	if Cfg.RatingConfig.MinVotes < 0 {
+		return fmt.Errorf("RATING_MIN_VOTES must not be negative")
+	}
+	if Cfg.MovieCache.Backend != "memory" && Cfg.MovieCache.Backend != "redis" {
+		return fmt.Errorf("MOVIE_CACHE_BACKEND must be memory or redis")
+	}
+	if Cfg.MovieCache.Size < 1 {
+		return fmt.Errorf("MOVIE_CACHE_SIZE must be at least 1")
+	}
+	if Cfg.Mail.Backend != "smtp" && Cfg.Mail.Backend != "file" && Cfg.Mail.Backend != "log" {
+		return fmt.Errorf("MAIL_BACKEND must be smtp, file or log")
+	}
+	if Cfg.Environment != "dev" && Cfg.Mail.Backend != "smtp" {
+		return fmt.Errorf("MAIL_BACKEND must be smtp outside the dev environment")
+	}
+	if Cfg.Account.PasswordResetTTL <= 0 || Cfg.Account.EmailVerificationTTL <= 0 {
+		return fmt.Errorf("PASSWORD_RESET_TTL and EMAIL_VERIFICATION_TTL must be positive")
+	}
+	if Cfg.Login.MaxFailures < 1 || Cfg.Login.MaxIPFailures < 1 {
+		return fmt.Errorf("LOGIN_MAX_FAILURES and LOGIN_MAX_IP_FAILURES must be at least 1")
+	}
+	if Cfg.Login.Lockout <= 0 || Cfg.Login.MaxLockout < Cfg.Login.Lockout || Cfg.Login.Window <= 0 {
+		return fmt.Errorf("LOGIN_LOCKOUT and LOGIN_FAILURE_WINDOW must be positive and LOGIN_MAX_LOCKOUT at least LOGIN_LOCKOUT")
+	}
+	if Cfg.TwoFactor.Issuer == "" || Cfg.TwoFactor.ChallengeTTL <= 0 {
+		return fmt.Errorf("TWO_FACTOR_ISSUER must not be empty and TWO_FACTOR_CHALLENGE_TTL must be positive")
+	}
+	if Cfg.APIKeys.DefaultTTL <= 0 || Cfg.APIKeys.MaxTTL < Cfg.APIKeys.DefaultTTL {
+		return fmt.Errorf("API_KEY_DEFAULT_TTL must be positive and API_KEY_MAX_TTL at least API_KEY_DEFAULT_TTL")
+	}
+	if Cfg.OIDC.Issuer != "" && (Cfg.OIDC.ClientID == "" || Cfg.OIDC.RedirectURL == "") {
+		return fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL must be set when OIDC_ISSUER is")
+	}
+	if Cfg.OIDC.Issuer != "" && !slices.Contains(Cfg.OIDC.Scopes, "openid") {
+		return fmt.Errorf("OIDC_SCOPES must contain openid")
+	}
+	if Cfg.OIDC.LoginTTL <= 0 {
+		return fmt.Errorf("OIDC_LOGIN_TTL must be positive")
+	}
+	if Cfg.Password.Algorithm != "argon2id" && Cfg.Password.Algorithm != "bcrypt" {
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be argon2id or bcrypt")
+	}
+	if Cfg.Password.Argon2Time < 1 || Cfg.Password.Argon2Threads < 1 || Cfg.Password.Argon2Memory < 8*uint32(Cfg.Password.Argon2Threads) {
+		return fmt.Errorf("PASSWORD_ARGON2_TIME and PASSWORD_ARGON2_THREADS must be at least 1 and PASSWORD_ARGON2_MEMORY at least 8 KiB per thread")
+	}
+	if Cfg.Password.BcryptCost < 4 || Cfg.Password.BcryptCost > 31 {
+		return fmt.Errorf("PASSWORD_BCRYPT_COST must be between 4 and 31")
+	}
+	if Cfg.Password.MinLength < 1 || Cfg.Password.MaxLength < Cfg.Password.MinLength {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be at least 1 and PASSWORD_MAX_LENGTH at least PASSWORD_MIN_LENGTH")
+	}
+	if Cfg.Proxy.Header != "" && len(Cfg.Proxy.TrustedProxies) == 0 {
+		return fmt.Errorf("TRUSTED_PROXIES must be set when PROXY_HEADER is")
+	}
 	return nil
 }
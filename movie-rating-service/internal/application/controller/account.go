@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+)
+
+type accountController struct {
+	accountService service.AccountService
+}
+
+func NewAccountController(app *fiber.App, accountService service.AccountService, authMiddleware middleware.AuthMiddleware) {
+	controller := &accountController{accountService: accountService}
+
+	app.Post("/password/forgot", controller.ForgotPassword)
+	app.Post("/password/reset", controller.ResetPassword)
+
+	app.Post("/user/verify", controller.VerifyEmail)
+	app.Post("/user/verify/resend", authMiddleware.UserHandler, controller.ResendVerification)
+}
+
+// @Summary Forgot Password
+// @Description Mails a password reset token to the accounts using the email. Always succeeds, whether or not the email belongs to an account.
+// @Tags Account
+// @Param body body request.ForgotPassword true "Forgot password payload"
+// @Success 202 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /password/forgot [post]
+func (c *accountController) ForgotPassword(ctx *fiber.Ctx) error {
+	var req request.ForgotPassword
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.accountService.ForgotPassword(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(response.Success(struct{}{}))
+}
+
+// @Summary Reset Password
+// @Description Sets a new password, which must satisfy the password policy, with a token from the reset mail and ends every session of the user. Each token works once.
+// @Tags Account
+// @Param body body request.ResetPassword true "Reset password payload"
+// @Success 200 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /password/reset [post]
+func (c *accountController) ResetPassword(ctx *fiber.Ctx) error {
+	var req request.ResetPassword
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.accountService.ResetPassword(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Password could not reset")
+		return err
+	}
+
+	slog.Info("Password reset")
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(struct{}{}))
+}
+
+// @Summary Verify Email
+// @Description Marks the email as verified with a token from the verification mail. Tokens issued before refreshing reflect the change only after the next refresh.
+// @Tags Account
+// @Param body body request.VerifyEmail true "Verify email payload"
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /user/verify [post]
+func (c *accountController) VerifyEmail(ctx *fiber.Ctx) error {
+	var req request.VerifyEmail
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.accountService.VerifyEmail(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Email could not verify")
+		return err
+	}
+
+	slog.Info("Email verified", "user_id", res.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Resend Verification Mail
+// @Tags Account
+// @Success 202 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/verify/resend [post]
+func (c *accountController) ResendVerification(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.SendVerification{UserID: cast.ToUint(claims["user_id"])}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.accountService.SendVerification(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusAccepted).JSON(response.Success(struct{}{}))
+}
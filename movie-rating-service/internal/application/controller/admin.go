@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
+)
+
+type adminController struct {
+	reconcileService service.ReconcileService
+	userAdminService service.UserAdminService
+}
+
+func NewAdminController(app *fiber.App, reconcileService service.ReconcileService, userAdminService service.UserAdminService, authMiddleware middleware.AuthMiddleware) {
+	controller := &adminController{reconcileService: reconcileService, userAdminService: userAdminService}
+
+	app.Post("/admin/reconcile", authMiddleware.RequirePermission(domain.PermissionRatingReconcile), controller.Reconcile)
+
+	app.Get("/admin/users", authMiddleware.RequirePermission(domain.PermissionUserRead), controller.ListUsers)
+	app.Put("/admin/users/:id/roles/:role", authMiddleware.RequirePermission(domain.PermissionUserManage), controller.GrantRole)
+	app.Delete("/admin/users/:id/roles/:role", authMiddleware.RequirePermission(domain.PermissionUserManage), controller.RevokeRole)
+	app.Put("/admin/users/:id/status", authMiddleware.RequirePermission(domain.PermissionUserManage), controller.UpdateUserStatus)
+	app.Post("/admin/users/:id/password-reset", authMiddleware.RequirePermission(domain.PermissionUserManage), controller.ForcePasswordReset)
+}
+
+// @Summary Reconcile Movie Ratings
+// @Description Recomputes rating, rating_count and weighted_rating of every movie from the ratings table and reports discrepancies.
+// @Tags Admin
+// @Param dry_run    query bool false "Only report discrepancies, do not fix them"
+// @Param batch_size query int  false "Movies per batch (1-5000, default 500)"
+// @Success 200 {object} response.SuccessResponse{data=response.Reconcile}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/reconcile [post]
+func (c *adminController) Reconcile(ctx *fiber.Ctx) error {
+	var req request.Reconcile
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.reconcileService.Reconcile(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Ratings could not reconciled")
+		return err
+	}
+
+	slog.Info("Ratings reconciled", "scanned", res.Scanned, "mismatched", res.Mismatched, "fixed", res.Fixed, "dry_run", res.DryRun)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary List Users
+// @Description Lists and searches user accounts.
+// @Tags Admin
+// @Param q      query string false "Matches username, name, surname and email"
+// @Param role   query string false "Only users with this role"
+// @Param status query string false "Only users with this status" Enums(active, disabled, banned)
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} response.SuccessResponse{data=[]response.GetUser,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users [get]
+func (c *adminController) ListUsers(ctx *fiber.Ctx) error {
+	var req request.ListUsers
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userAdminService.List(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Users, res.Pagination))
+}
+
+// @Summary Grant Role
+// @Tags Admin
+// @Param id   path string true "User Id"
+// @Param role path string true "Role name"
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles/{role} [put]
+func (c *adminController) GrantRole(ctx *fiber.Ctx) error {
+	req := c.userRoleRequest(ctx)
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userAdminService.GrantRole(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Role could not grant")
+		return err
+	}
+
+	slog.Info("Role granted", "user_id", req.UserID, "role", req.Role, "by", req.ActorID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Revoke Role
+// @Description Removes a role; the user's current access tokens are revoked so the change applies at once.
+// @Tags Admin
+// @Param id   path string true "User Id"
+// @Param role path string true "Role name"
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (c *adminController) RevokeRole(ctx *fiber.Ctx) error {
+	req := c.userRoleRequest(ctx)
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userAdminService.RevokeRole(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Role could not revoke")
+		return err
+	}
+
+	slog.Info("Role revoked", "user_id", req.UserID, "role", req.Role, "by", req.ActorID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Update User Status
+// @Description Disables, bans or reactivates an account. Disabling or banning ends all of the user's sessions.
+// @Tags Admin
+// @Param id   path string                   true "User Id"
+// @Param body body request.UpdateUserStatus true "Status payload"
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/status [put]
+func (c *adminController) UpdateUserStatus(ctx *fiber.Ctx) error {
+	var req request.UpdateUserStatus
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+
+	req.ActorID = cast.ToUint(claims["user_id"])
+	req.UserID = cast.ToUint(ctx.Params("id"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userAdminService.UpdateStatus(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User status could not update")
+		return err
+	}
+
+	slog.Info("User status updated", "user_id", req.UserID, "status", req.Status, "by", req.ActorID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Force Password Reset
+// @Description Ends all of the user's sessions. After logging in again their token grants nothing until the password is changed.
+// @Tags Admin
+// @Param id path string true "User Id"
+// @Success 200 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/password-reset [post]
+func (c *adminController) ForcePasswordReset(ctx *fiber.Ctx) error {
+	req := request.ForcePasswordReset{UserID: cast.ToUint(ctx.Params("id"))}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.userAdminService.ForcePasswordReset(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Password reset could not force")
+		return err
+	}
+
+	slog.Info("Password reset forced", "user_id", req.UserID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(struct{}{}))
+}
+
+func (c *adminController) userRoleRequest(ctx *fiber.Ctx) request.UserRole {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+
+	return request.UserRole{
+		ActorID: cast.ToUint(claims["user_id"]),
+		UserID:  cast.ToUint(ctx.Params("id")),
+		Role:    ctx.Params("role"),
+	}
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
+)
+
+type apiKeyController struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyController(app *fiber.App, apiKeyService service.APIKeyService, authMiddleware middleware.AuthMiddleware) {
+	controller := &apiKeyController{apiKeyService: apiKeyService}
+
+	app.Post("/user/me/api-keys", authMiddleware.UserHandler, controller.CreateMine)
+	app.Get("/user/me/api-keys", authMiddleware.UserHandler, controller.ListMine)
+	app.Delete("/user/me/api-keys/:id", authMiddleware.UserHandler, controller.RevokeMine)
+
+	// Keys for service accounts, which do not log in themselves. Keys are managed with a login only,
+	// so that a leaked key cannot mint further keys.
+	app.Post("/admin/users/:id/api-keys", authMiddleware.RequireLoginPermission(domain.PermissionUserManage), controller.Create)
+	app.Get("/admin/users/:id/api-keys", authMiddleware.RequireLoginPermission(domain.PermissionUserRead), controller.List)
+	app.Delete("/admin/users/:id/api-keys/:keyId", authMiddleware.RequireLoginPermission(domain.PermissionUserManage), controller.Revoke)
+}
+
+// @Summary Create API Key
+// @Description Creates a key that authenticates as you with the X-API-Key header, on routes that require a permission. It grants only the given scopes, which must be permissions you hold. The key is returned only this once.
+// @Tags APIKey
+// @Param body body request.CreateAPIKey true "API key payload"
+// @Success 200 {object} response.SuccessResponse{data=response.CreatedAPIKey}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/api-keys [post]
+func (c *apiKeyController) CreateMine(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	return c.create(ctx, cast.ToUint(claims["user_id"]))
+}
+
+// @Summary List API Keys
+// @Description Lists your API keys, revoked and expired ones included, newest first.
+// @Tags APIKey
+// @Success 200 {object} response.SuccessResponse{data=[]response.APIKey}
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/api-keys [get]
+func (c *apiKeyController) ListMine(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	return c.list(ctx, cast.ToUint(claims["user_id"]))
+}
+
+// @Summary Revoke API Key
+// @Tags APIKey
+// @Param id path string true "API key Id"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/api-keys/{id} [delete]
+func (c *apiKeyController) RevokeMine(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	return c.revoke(ctx, cast.ToUint(claims["user_id"]), cast.ToUint(ctx.Params("id")))
+}
+
+// @Summary Create API Key for User
+// @Description Creates a key for another user, typically a service account. The user may not hold any permission you do not, and the key's scopes must be permissions you both hold. The key is returned only this once.
+// @Tags Admin
+// @Param id   path string               true "User Id"
+// @Param body body request.CreateAPIKey true "API key payload"
+// @Success 200 {object} response.SuccessResponse{data=response.CreatedAPIKey}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/api-keys [post]
+func (c *apiKeyController) Create(ctx *fiber.Ctx) error {
+	return c.create(ctx, cast.ToUint(ctx.Params("id")))
+}
+
+// @Summary List API Keys of User
+// @Tags Admin
+// @Param id path string true "User Id"
+// @Success 200 {object} response.SuccessResponse{data=[]response.APIKey}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/api-keys [get]
+func (c *apiKeyController) List(ctx *fiber.Ctx) error {
+	return c.list(ctx, cast.ToUint(ctx.Params("id")))
+}
+
+// @Summary Revoke API Key of User
+// @Tags Admin
+// @Param id    path string true "User Id"
+// @Param keyId path string true "API key Id"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /admin/users/{id}/api-keys/{keyId} [delete]
+func (c *apiKeyController) Revoke(ctx *fiber.Ctx) error {
+	return c.revoke(ctx, cast.ToUint(ctx.Params("id")), cast.ToUint(ctx.Params("keyId")))
+}
+
+func (c *apiKeyController) create(ctx *fiber.Ctx, userID uint) error {
+	var req request.CreateAPIKey
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = userID
+	req.GrantorID = cast.ToUint(claims["user_id"])
+	req.GrantorPermissions = cast.ToStringSlice(claims["permissions"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.apiKeyService.Create(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("API key could not be created")
+		return err
+	}
+
+	slog.Info("API key created", "user_id", req.UserID, "api_key_id", res.ID, "scopes", res.Scopes, "by", req.GrantorID)
+	return ctx.JSON(response.Success(res))
+}
+
+func (c *apiKeyController) list(ctx *fiber.Ctx, userID uint) error {
+	req := request.ListAPIKeys{UserID: userID}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.apiKeyService.List(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(response.Success(res))
+}
+
+func (c *apiKeyController) revoke(ctx *fiber.Ctx, userID, keyID uint) error {
+	req := request.RevokeAPIKey{UserID: userID, KeyID: keyID}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.apiKeyService.Revoke(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("API key could not be revoked")
+		return err
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	slog.Info("API key revoked", "user_id", req.UserID, "api_key_id", req.KeyID, "by", cast.ToUint(claims["user_id"]))
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"movie-rating-service/internal/application/service"
+)
+
+type keyController struct {
+	keyRing service.KeyRing
+}
+
+func NewKeyController(app *fiber.App, keyRing service.KeyRing) {
+	controller := &keyController{keyRing: keyRing}
+
+	app.Get("/.well-known/jwks.json", controller.JWKS)
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys access tokens are signed with, including the next key before it is used and retired keys until their tokens expire.
+// @Tags Auth
+// @Success 200 {object} response.JWKS
+// @Router /.well-known/jwks.json [get]
+func (c *keyController) JWKS(ctx *fiber.Ctx) error {
+	// Keys are published an hour before use, so verifiers may cache the set for a few minutes.
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(c.keyRing.JWKS())
+}
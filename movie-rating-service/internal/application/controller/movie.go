@@ -4,28 +4,143 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/cast"
 	"log/slog"
-	"movie-rating-service/config"
 	"movie-rating-service/internal/application/middleware"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
 	"movie-rating-service/internal/application/service"
 	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
 )
 
 type movieController struct {
 	movieService service.MovieService
 }
 
-func NewMovieController(app *fiber.App, movieService service.MovieService) {
-	authMiddleware := middleware.NewAuthMiddleware(config.Cfg.JWTSecret)
-
+func NewMovieController(app *fiber.App, movieService service.MovieService, authMiddleware middleware.AuthMiddleware) {
 	controller := &movieController{movieService: movieService}
 
-	app.Post("/movie", authMiddleware.AdminHandler, controller.CreateMovie)
-	app.Put("/movie/:id", authMiddleware.AdminHandler, controller.UpdateMovie)
-	app.Delete("/movie/:id", authMiddleware.AdminHandler, controller.DeleteMovie)
+	app.Post("/movie", authMiddleware.RequirePermission(domain.PermissionMovieWrite), controller.CreateMovie)
+	app.Put("/movie/:id", authMiddleware.RequirePermission(domain.PermissionMovieWrite), controller.UpdateMovie)
+	app.Delete("/movie/:id", authMiddleware.RequirePermission(domain.PermissionMovieWrite), controller.DeleteMovie)
+	app.Get("/movie", controller.ListMovies)
+	app.Get("/movie/top", controller.TopMovies)
+	app.Get("/movie/search", controller.SearchMovies)
+	app.Get("/movie/suggest", controller.SuggestMovies)
 	app.Get("/movie/:id", controller.GetMovie)
+	app.Get("/movie/:id/stats", controller.GetMovieStats)
+
+}
+
+// @Summary List Movies
+// @Tags Movie
+// @Param limit            query int    false "Page size (1-100, default 20)"
+// @Param cursor           query string false "Cursor returned as next_cursor by the previous page"
+// @Param genre            query string false "Genre (case-insensitive exact match)"
+// @Param director         query string false "Director (case-insensitive partial match)"
+// @Param year_from        query int    false "Minimum release year"
+// @Param year_to          query int    false "Maximum release year"
+// @Param min_rating       query number false "Minimum average rating"
+// @Param min_rating_count query int    false "Minimum number of ratings"
+// @Param sort_by          query string false "Sort key" Enums(rating, weighted_rating, year, title, created_at)
+// @Param order            query string false "Sort direction" Enums(asc, desc)
+// @Success 200 {object} response.SuccessResponse{data=[]response.GetMovie,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie [get]
+func (c *movieController) ListMovies(ctx *fiber.Ctx) error {
+	var req request.ListMovies
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieService.List(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Movies, res.Pagination))
+}
+
+// @Summary Top Movies
+// @Description Top-N chart ranked by the Bayesian weighted rating.
+// @Tags Movie
+// @Param limit query int    false "Number of movies (1-100, default 10)"
+// @Param genre query string false "Restrict the chart to a genre"
+// @Success 200 {object} response.SuccessResponse{data=[]response.GetMovie}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie/top [get]
+func (c *movieController) TopMovies(ctx *fiber.Ctx) error {
+	var req request.TopMovies
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieService.Top(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Search Movies
+// @Description Full-text search over title, description, director and genre with typo tolerance. highlight and snippet are HTML: the movie text is escaped and the matches are wrapped in <mark>.
+// @Tags Movie
+// @Param q      query string true  "Search query"
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} response.SuccessResponse{data=[]response.SearchMovie,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie/search [get]
+func (c *movieController) SearchMovies(ctx *fiber.Ctx) error {
+	var req request.SearchMovies
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieService.Search(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Movies, res.Pagination))
+}
+
+// @Summary Suggest Movies
+// @Description Title autocomplete by prefix, tolerant to small typos.
+// @Tags Movie
+// @Param prefix query string true  "Title prefix"
+// @Param limit  query int    false "Number of suggestions (1-20, default 10)"
+// @Success 200 {object} response.SuccessResponse{data=[]response.SuggestMovie}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie/suggest [get]
+func (c *movieController) SuggestMovies(ctx *fiber.Ctx) error {
+	var req request.SuggestMovies
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
 
+	res, err := c.movieService.Suggest(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
 }
 
 // @Summary GetByID Movie
@@ -51,6 +166,30 @@ func (c *movieController) GetMovie(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
 }
 
+// @Summary GetStats Movie
+// @Description Rating histogram (half-star buckets), median, standard deviation and monthly rating series.
+// @Tags Movie
+// @Param id path string true "Movie Id"
+// @Success 200 {object} response.SuccessResponse{data=response.MovieStats}
+// @Success 400 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie/{id}/stats [get]
+func (c *movieController) GetMovieStats(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	req := request.GetMovieStats{ID: cast.ToUint(id)}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieService.GetStats(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
 // @Summary Update Movie
 // @Tags Movie
 // @Param id   path     int  true  "Movie ID"
@@ -59,6 +198,7 @@ func (c *movieController) GetMovie(ctx *fiber.Ctx) error {
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie/{id} [put]
 func (c *movieController) UpdateMovie(ctx *fiber.Ctx) error {
 	var req request.UpdateMovie
@@ -90,6 +230,7 @@ func (c *movieController) UpdateMovie(ctx *fiber.Ctx) error {
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie/{id} [delete]
 func (c *movieController) DeleteMovie(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
@@ -116,6 +257,8 @@ func (c *movieController) DeleteMovie(ctx *fiber.Ctx) error {
 // @Success 200 {object} response.SuccessResponse{data=response.CreateMovie}
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie [post]
 func (c *movieController) CreateMovie(ctx *fiber.Ctx) error {
 	var req request.CreateMovie
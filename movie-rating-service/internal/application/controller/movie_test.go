@@ -0,0 +1,124 @@
+//go:build unit_test
+
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/mocks"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAuth stands in for middleware.AuthMiddleware: it authenticates every request as claims, and
+// rejects requests that need a user when claims is nil.
+type fakeAuth struct {
+	claims jwt.MapClaims
+}
+
+func (a *fakeAuth) UserHandler(ctx *fiber.Ctx) error {
+	if a.claims == nil {
+		return ctx.SendStatus(fiber.StatusUnauthorized)
+	}
+	ctx.Locals("user", a.claims)
+	return ctx.Next()
+}
+
+func (a *fakeAuth) RequirePermission(...string) fiber.Handler {
+	return a.UserHandler
+}
+
+func (a *fakeAuth) RequireLoginPermission(...string) fiber.Handler {
+	return a.UserHandler
+}
+
+func (a *fakeAuth) OptionalUserHandler(ctx *fiber.Ctx) error {
+	if a.claims != nil {
+		ctx.Locals("user", a.claims)
+	}
+	return ctx.Next()
+}
+
+type MovieControllerTest struct {
+	suite.Suite
+	app     *fiber.App
+	service *mocks.MovieService
+}
+
+func (c *MovieControllerTest) SetupTest() {
+	c.service = new(mocks.MovieService)
+
+	c.app = fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler()})
+	NewMovieController(c.app, c.service, &fakeAuth{})
+}
+
+func Test_RunMovieControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieControllerTest))
+}
+
+func (c *MovieControllerTest) get(path string) int {
+	res, err := c.app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+	c.Require().NoError(err)
+	return res.StatusCode
+}
+
+func (c *MovieControllerTest) TestListMovies_Success() {
+	t := c.T()
+
+	c.service.On("List", mock.Anything, request.ListMovies{
+		Pagination: request.Pagination{Limit: 5},
+		Director:   "mann",
+		YearFrom:   1990,
+		YearTo:     1999,
+	}).Return(&response.ListMovies{Movies: []response.GetMovie{{ID: 1, Title: "Heat"}}}, nil).Once()
+
+	status := c.get("/movie?limit=5&director=mann&year_from=1990&year_to=1999")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	c.service.AssertExpectations(t)
+}
+
+func (c *MovieControllerTest) TestListMovies_Error_Year_Range() {
+	t := c.T()
+
+	status := c.get("/movie?year_from=2000&year_to=1990")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	c.service.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func (c *MovieControllerTest) TestListMovies_Error_Sort_Column() {
+	t := c.T()
+
+	status := c.get("/movie?sort_by=password")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	c.service.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func (c *MovieControllerTest) TestTopMovies_Success() {
+	t := c.T()
+
+	c.service.On("Top", mock.Anything, request.TopMovies{Limit: 5, Genre: "crime"}).
+		Return([]response.GetMovie{{ID: 2, Title: "Heat", WeightedRating: 4.797}}, nil).Once()
+
+	status := c.get("/movie/top?limit=5&genre=crime")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	c.service.AssertExpectations(t)
+}
+
+func (c *MovieControllerTest) TestTopMovies_Error_Limit() {
+	t := c.T()
+
+	status := c.get("/movie/top?limit=1000")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	c.service.AssertNotCalled(t, "Top", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,624 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
+	"net/url"
+)
+
+type movieListController struct {
+	movieListService service.MovieListService
+}
+
+func NewMovieListController(app *fiber.App, movieListService service.MovieListService, authMiddleware middleware.AuthMiddleware) {
+	controller := &movieListController{movieListService: movieListService}
+
+	app.Get("/lists", controller.BrowseLists)
+	app.Post("/lists", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.CreateList)
+	app.Get("/user/me/lists", authMiddleware.UserHandler, controller.ListMyLists)
+	// Before /lists/:id/items, which would match /lists/shared/items.
+	app.Get("/lists/shared/:slug", authMiddleware.OptionalUserHandler, controller.GetSharedList)
+	app.Get("/lists/shared/:slug/items", authMiddleware.OptionalUserHandler, controller.ListSharedItems)
+	app.Post("/lists/shared/:slug/clone", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.CloneSharedList)
+	app.Get("/lists/:id", authMiddleware.OptionalUserHandler, controller.GetList)
+	app.Patch("/lists/:id", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.UpdateList)
+	app.Delete("/lists/:id", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.DeleteList)
+	app.Post("/lists/:id/clone", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.CloneList)
+
+	app.Get("/lists/:id/items", authMiddleware.OptionalUserHandler, controller.ListItems)
+	app.Post("/lists/:id/items", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.AddItem)
+	app.Put("/lists/:id/items/order", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.ReorderList)
+	app.Patch("/lists/:id/items/:movieId", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.UpdateItem)
+	app.Delete("/lists/:id/items/:movieId", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.RemoveItem)
+
+	app.Post("/lists/:id/editors", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.AddEditor)
+	app.Delete("/lists/:id/editors/:username", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.RemoveEditor)
+}
+
+// @Summary Browse Lists
+// @Description Lists the public lists, newest first.
+// @Tags List
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param q      query string false "Name (case-insensitive partial match)"
+// @Success 200 {object} response.SuccessResponse{data=[]response.MovieList,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /lists [get]
+func (c *movieListController) BrowseLists(ctx *fiber.Ctx) error {
+	var req request.BrowseMovieLists
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.Browse(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Lists, res.Pagination))
+}
+
+// @Summary Create List
+// @Description Creates a list owned by you. Lists are private unless another visibility is given: public lists can be browsed, unlisted ones can be seen by anyone with their slug.
+// @Tags List
+// @Param body body request.CreateMovieList true "List payload"
+// @Success 201 {object} response.SuccessResponse{data=response.MovieList}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists [post]
+func (c *movieListController) CreateList(ctx *fiber.Ctx) error {
+	var req request.CreateMovieList
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.Create(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List could not create")
+		return err
+	}
+
+	slog.Info("List created", "user_id", req.UserID, "list_id", res.ID)
+	return ctx.Status(fiber.StatusCreated).JSON(response.Success(res))
+}
+
+// @Summary List Own Lists
+// @Description Lists the lists you own or edit, newest first.
+// @Tags List
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} response.SuccessResponse{data=[]response.MovieList,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/lists [get]
+func (c *movieListController) ListMyLists(ctx *fiber.Ctx) error {
+	var req request.ListMyMovieLists
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.ListMine(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Lists, res.Pagination))
+}
+
+// @Summary Get List
+// @Description Public lists can be seen by anyone; unlisted and private ones only by their owner and editors, and are not found for everybody else. Unlisted lists are shared by their slug instead.
+// @Tags List
+// @Param id path string true "List Id"
+// @Success 200 {object} response.SuccessResponse{data=response.MovieList}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id} [get]
+func (c *movieListController) GetList(ctx *fiber.Ctx) error {
+	return c.getList(ctx, request.GetMovieList{ListID: cast.ToUint(ctx.Params("id"))})
+}
+
+// @Summary Get Shared List
+// @Description Public and unlisted lists can be seen by anyone with their slug; private ones only by their owner and editors, and are not found for everybody else.
+// @Tags List
+// @Param slug path string true "List slug"
+// @Success 200 {object} response.SuccessResponse{data=response.MovieList}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/shared/{slug} [get]
+func (c *movieListController) GetSharedList(ctx *fiber.Ctx) error {
+	return c.getList(ctx, request.GetMovieList{Slug: ctx.Params("slug")})
+}
+
+func (c *movieListController) getList(ctx *fiber.Ctx, req request.GetMovieList) error {
+	req.UserID = optionalUserID(ctx)
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.Get(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Update List
+// @Description Changes the name, description or visibility of a list you own. Fields left out of the payload are kept.
+// @Tags List
+// @Param id   path string                  true "List Id"
+// @Param body body request.UpdateMovieList true "List payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id} [patch]
+func (c *movieListController) UpdateList(ctx *fiber.Ctx) error {
+	var req request.UpdateMovieList
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = cast.ToUint(ctx.Params("id"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.Update(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List could not update")
+		return err
+	}
+
+	slog.Info("List updated", "user_id", req.UserID, "list_id", req.ListID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Delete List
+// @Tags List
+// @Param id path string true "List Id"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id} [delete]
+func (c *movieListController) DeleteList(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.DeleteMovieList{
+		ListID: cast.ToUint(ctx.Params("id")),
+		UserID: cast.ToUint(claims["user_id"]),
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.Delete(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List could not delete")
+		return err
+	}
+
+	slog.Info("List deleted", "user_id", req.UserID, "list_id", req.ListID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Clone List
+// @Description Copies a list you can see, with its notes and order, into a new list owned by you. The name defaults to the original's and the visibility to private.
+// @Tags List
+// @Param id   path string                 true "List Id"
+// @Param body body request.CloneMovieList false "Clone payload"
+// @Success 201 {object} response.SuccessResponse{data=response.MovieList}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/clone [post]
+func (c *movieListController) CloneList(ctx *fiber.Ctx) error {
+	return c.cloneList(ctx, cast.ToUint(ctx.Params("id")), "")
+}
+
+// @Summary Clone Shared List
+// @Description Copies a list found by its slug, e.g. an unlisted one, like Clone List.
+// @Tags List
+// @Param slug path string                 true  "List slug"
+// @Param body body request.CloneMovieList false "Clone payload"
+// @Success 201 {object} response.SuccessResponse{data=response.MovieList}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/shared/{slug}/clone [post]
+func (c *movieListController) CloneSharedList(ctx *fiber.Ctx) error {
+	return c.cloneList(ctx, 0, ctx.Params("slug"))
+}
+
+func (c *movieListController) cloneList(ctx *fiber.Ctx, listID uint, slug string) error {
+	var req request.CloneMovieList
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = listID
+	req.Slug = slug
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.Clone(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List could not clone")
+		return err
+	}
+
+	slog.Info("List cloned", "user_id", req.UserID, "list_id", res.ID, "cloned_from_id", req.ListID)
+	return ctx.Status(fiber.StatusCreated).JSON(response.Success(res))
+}
+
+// @Summary List Items
+// @Description Lists the movies on a list in its order, with their notes. Movies deleted since they were added are left out.
+// @Tags List
+// @Param id     path  string true  "List Id"
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} response.SuccessResponse{data=[]response.MovieListItem,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/items [get]
+func (c *movieListController) ListItems(ctx *fiber.Ctx) error {
+	return c.listItems(ctx, cast.ToUint(ctx.Params("id")), "")
+}
+
+// @Summary List Shared Items
+// @Description Lists the movies on a list found by its slug, like List Items.
+// @Tags List
+// @Param slug   path  string true  "List slug"
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Success 200 {object} response.SuccessResponse{data=[]response.MovieListItem,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/shared/{slug}/items [get]
+func (c *movieListController) ListSharedItems(ctx *fiber.Ctx) error {
+	return c.listItems(ctx, 0, ctx.Params("slug"))
+}
+
+func (c *movieListController) listItems(ctx *fiber.Ctx, listID uint, slug string) error {
+	var req request.ListMovieListItems
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	req.ListID = listID
+	req.Slug = slug
+	req.UserID = optionalUserID(ctx)
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.movieListService.ListItems(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Items, res.Pagination))
+}
+
+// @Summary Add List Item
+// @Description Adds the movie at the end of a list you own or edit.
+// @Tags List
+// @Param id   path string                   true "List Id"
+// @Param body body request.AddMovieListItem true "List item payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/items [post]
+func (c *movieListController) AddItem(ctx *fiber.Ctx) error {
+	var req request.AddMovieListItem
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = cast.ToUint(ctx.Params("id"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.AddItem(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List item could not add")
+		return err
+	}
+
+	slog.Info("List item added", "user_id", req.UserID, "list_id", req.ListID, "movie_id", req.MovieID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Reorder List
+// @Description Moves the given movies to the top of a list you own or edit, in the given order. The movies left out follow in their current order.
+// @Tags List
+// @Param id   path string                   true "List Id"
+// @Param body body request.ReorderMovieList true "List order payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/items/order [put]
+func (c *movieListController) ReorderList(ctx *fiber.Ctx) error {
+	var req request.ReorderMovieList
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = cast.ToUint(ctx.Params("id"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.Reorder(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List could not reorder")
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Update List Item
+// @Description Replaces the note of a movie on a list you own or edit.
+// @Tags List
+// @Param id      path string                      true "List Id"
+// @Param movieId path string                      true "Movie Id"
+// @Param body    body request.UpdateMovieListItem true "List item payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/items/{movieId} [patch]
+func (c *movieListController) UpdateItem(ctx *fiber.Ctx) error {
+	var req request.UpdateMovieListItem
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = cast.ToUint(ctx.Params("id"))
+	req.MovieID = cast.ToUint(ctx.Params("movieId"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.UpdateItem(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List item could not update")
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Remove List Item
+// @Tags List
+// @Param id      path string true "List Id"
+// @Param movieId path string true "Movie Id"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/items/{movieId} [delete]
+func (c *movieListController) RemoveItem(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.RemoveMovieListItem{
+		ListID:  cast.ToUint(ctx.Params("id")),
+		UserID:  cast.ToUint(claims["user_id"]),
+		MovieID: cast.ToUint(ctx.Params("movieId")),
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.RemoveItem(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List item could not remove")
+		return err
+	}
+
+	slog.Info("List item removed", "user_id", req.UserID, "list_id", req.ListID, "movie_id", req.MovieID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Add List Editor
+// @Description Lets another user change the movies on a list you own. Editors see the list even when it is private.
+// @Tags List
+// @Param id   path string                     true "List Id"
+// @Param body body request.AddMovieListEditor true "Editor payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/editors [post]
+func (c *movieListController) AddEditor(ctx *fiber.Ctx) error {
+	var req request.AddMovieListEditor
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.ListID = cast.ToUint(ctx.Params("id"))
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.AddEditor(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List editor could not add")
+		return err
+	}
+
+	slog.Info("List editor added", "user_id", req.UserID, "list_id", req.ListID, "editor", req.Username)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Remove List Editor
+// @Description The owner can remove any editor; editors can remove themselves.
+// @Tags List
+// @Param id       path string true "List Id"
+// @Param username path string true "Username"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /lists/{id}/editors/{username} [delete]
+func (c *movieListController) RemoveEditor(ctx *fiber.Ctx) error {
+	username, err := url.PathUnescape(ctx.Params("username"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.RemoveMovieListEditor{
+		ListID:   cast.ToUint(ctx.Params("id")),
+		UserID:   cast.ToUint(claims["user_id"]),
+		Username: username,
+	}
+
+	err = validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.movieListService.RemoveEditor(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("List editor could not remove")
+		return err
+	}
+
+	slog.Info("List editor removed", "user_id", req.UserID, "list_id", req.ListID, "editor", req.Username)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// optionalUserID returns the caller behind middleware.AuthMiddleware.OptionalUserHandler, or 0 for
+// anonymous requests.
+func optionalUserID(ctx *fiber.Ctx) uint {
+	claims, ok := ctx.Locals("user").(jwt.MapClaims)
+	if !ok {
+		return 0
+	}
+	return cast.ToUint(claims["user_id"])
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"time"
+)
+
+// oidcStateCookie binds a login to the browser that started it. Lax lets the browser send it along
+// with the provider's top-level redirect back to the callback.
+const oidcStateCookie = "oidc_state"
+
+type oidcController struct {
+	oidcService      service.OIDCService
+	tokenService     service.TokenService
+	twoFactorService service.TwoFactorService
+}
+
+func NewOIDCController(app *fiber.App, oidcService service.OIDCService, tokenService service.TokenService, twoFactorService service.TwoFactorService) {
+	controller := &oidcController{oidcService: oidcService, tokenService: tokenService, twoFactorService: twoFactorService}
+
+	app.Get("/auth/oidc/login", controller.Login)
+	app.Get("/auth/oidc/callback", controller.Callback)
+}
+
+// @Summary Login with Identity Provider
+// @Description Redirects the browser to the OpenID Connect provider, which redirects back to the callback. Sets the oidc_state cookie, which the callback requires. Only available when OIDC_ISSUER is set.
+// @Tags User
+// @Success 302
+// @Success 500 {object} response.ErrorResponse
+// @Router /auth/oidc/login [get]
+func (c *oidcController) Login(ctx *fiber.Ctx) error {
+	url, state, err := c.oidcService.Start(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+	ctx.Cookie(c.stateCookie(ctx, state))
+	return ctx.Redirect(url, fiber.StatusFound)
+}
+
+// @Summary Identity Provider Callback
+// @Description Completes a login with the identity provider and returns the session, or a two-factor challenge like POST /login. The account is created at the first login.
+// @Tags User
+// @Param state             query string true  "State of the login"
+// @Param code              query string false "Authorization code"
+// @Param error             query string false "Error from the provider"
+// @Param error_description query string false "Error description from the provider"
+// @Success 200 {object} response.SuccessResponse{data=response.Login}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /auth/oidc/callback [get]
+func (c *oidcController) Callback(ctx *fiber.Ctx) error {
+	var req request.OIDCCallback
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	req.BrowserState = ctx.Cookies(oidcStateCookie)
+	// The state is used up either way.
+	expired := c.stateCookie(ctx, "")
+	expired.Expires = time.Unix(0, 0)
+	ctx.Cookie(expired)
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.oidcService.Callback(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not log in with identity provider", "error", err)
+		return err
+	}
+
+	if user.TwoFactorEnabled {
+		res, err := c.twoFactorService.Challenge(ctx.UserContext(), *user)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(response.Success(res))
+	}
+
+	res, err := c.tokenService.Issue(ctx.UserContext(), *user)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(response.Success(res))
+}
+
+func (c *oidcController) stateCookie(ctx *fiber.Ctx, state string) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc",
+		Secure:   ctx.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	}
+}
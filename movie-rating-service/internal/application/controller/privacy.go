@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type privacyController struct {
+	privacyService service.PrivacyService
+}
+
+func NewPrivacyController(app *fiber.App, privacyService service.PrivacyService, authMiddleware middleware.AuthMiddleware) {
+	controller := &privacyController{privacyService: privacyService}
+
+	app.Get("/user/me/export", authMiddleware.UserHandler, controller.Export)
+	app.Post("/user/me/erase", authMiddleware.UserHandler, controller.EraseAccount)
+
+	app.Post("/admin/users/:id/erase", authMiddleware.RequirePermission(domain.PermissionUserManage), controller.EraseUser)
+}
+
+// @Summary Export Own Data
+// @Description Returns the profile, ratings, helpful votes, watchlist and lists of the user, as JSON or as a ZIP archive with one JSON file per section.
+// @Tags User
+// @Param format query string false "Export format (default json)" Enums(json, zip)
+// @Success 200 {object} response.SuccessResponse{data=response.UserExport}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/export [get]
+func (c *privacyController) Export(ctx *fiber.Ctx) error {
+	var req request.ExportUserData
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.privacyService.Export(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User data could not export")
+		return err
+	}
+
+	slog.Info("User data exported", "user_id", req.UserID, "format", req.Format)
+	if req.Format != "zip" {
+		return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+	}
+
+	archive, err := exportArchive(res)
+	if err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Attachment(fmt.Sprintf("user-%d-export.zip", req.UserID))
+	return ctx.Status(fiber.StatusOK).Send(archive)
+}
+
+// @Summary Erase Own Data
+// @Description Requires the password. Permanently deletes the account, its personal data, ratings, helpful votes, watchlist and lists; the affected movies' ratings are recomputed.
+// @Tags User
+// @Param body body request.EraseAccount true "Erasure payload"
+// @Success 200 {object} response.SuccessResponse{data=response.Erasure}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/erase [post]
+func (c *privacyController) EraseAccount(ctx *fiber.Ctx) error {
+	var req request.EraseAccount
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.privacyService.EraseAccount(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User data could not erase")
+		return err
+	}
+
+	slog.Info("User data erased", "user_id", req.UserID, "ratings", res.RatingsErased)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Erase User Data
+// @Description Permanently deletes a user, including an already deleted account, with their personal data, ratings, helpful votes, watchlist and lists; the affected movies' ratings are recomputed.
+// @Tags Admin
+// @Param id path string true "User Id"
+// @Success 200 {object} response.SuccessResponse{data=response.Erasure}
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/erase [post]
+func (c *privacyController) EraseUser(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.EraseUser{
+		ActorID: cast.ToUint(claims["user_id"]),
+		UserID:  cast.ToUint(ctx.Params("id")),
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.privacyService.Erase(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User data could not erase")
+		return err
+	}
+
+	slog.Info("User data erased", "user_id", req.UserID, "ratings", res.RatingsErased, "by", req.ActorID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// exportArchive writes each section of the export to its own JSON file in a ZIP archive.
+func exportArchive(export *response.UserExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", struct {
+			ExportedAt       time.Time        `json:"exported_at"`
+			AccountCreatedAt time.Time        `json:"account_created_at"`
+			Profile          response.GetUser `json:"profile"`
+		}{export.ExportedAt, export.AccountCreatedAt, export.Profile}},
+		{"ratings.json", export.Ratings},
+		{"helpful_votes.json", export.HelpfulVotes},
+		{"watchlist.json", export.Watchlist},
+		{"lists.json", export.Lists},
+	}
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(file.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -5,30 +5,31 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/cast"
 	"log/slog"
-	"movie-rating-service/config"
 	"movie-rating-service/internal/application/middleware"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
 	"movie-rating-service/internal/application/service"
 	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/domain"
 )
 
 type ratingController struct {
 	ratingService service.RatingService
 }
 
-func NewRatingController(app *fiber.App, ratingService service.RatingService) {
-	authMiddleware := middleware.NewAuthMiddleware(config.Cfg.JWTSecret)
-
+func NewRatingController(app *fiber.App, ratingService service.RatingService, authMiddleware middleware.AuthMiddleware) {
 	controller := &ratingController{ratingService: ratingService}
 
 	// Good improvement will be separating it from different groups, maybe separating it to movie and user.
 	// It depends on the team choice
 
-	app.Post("movie/:id/rating", authMiddleware.UserHandler, controller.CreateRating)
-	app.Patch("movie/:id/rating", authMiddleware.UserHandler, controller.UpdateRating)
-	app.Delete("movie/:id/rating", authMiddleware.UserHandler, controller.DeleteRating)
+	app.Post("movie/:id/rating", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.CreateRating)
+	app.Patch("movie/:id/rating", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.UpdateRating)
+	app.Delete("movie/:id/rating", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.DeleteRating)
 	app.Get("user/rating", authMiddleware.UserHandler, controller.GetUserRatings)
+	app.Get("movie/:id/ratings", controller.ListMovieRatings)
+	app.Post("rating/:id/helpful", authMiddleware.RequirePermission(domain.PermissionReviewWrite), controller.VoteHelpful)
+	app.Delete("rating/:id", authMiddleware.RequirePermission(domain.PermissionReviewModerate), controller.ModerateRating)
 }
 
 // @Summary Create Rating
@@ -39,6 +40,7 @@ func NewRatingController(app *fiber.App, ratingService service.RatingService) {
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie/{id}/rating [post]
 func (c *ratingController) CreateRating(ctx *fiber.Ctx) error {
 	var req request.CreateRating
@@ -76,6 +78,7 @@ func (c *ratingController) CreateRating(ctx *fiber.Ctx) error {
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie/{id}/rating [patch]
 func (c *ratingController) UpdateRating(ctx *fiber.Ctx) error {
 	var req request.UpdateRating
@@ -112,6 +115,7 @@ func (c *ratingController) UpdateRating(ctx *fiber.Ctx) error {
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /movie/{id}/rating [delete]
 func (c *ratingController) DeleteRating(ctx *fiber.Ctx) error {
 	var req request.DeleteRating
@@ -138,6 +142,39 @@ func (c *ratingController) DeleteRating(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(response.Success(struct{}{}))
 }
 
+// @Summary Moderate Rating
+// @Description Removes any user's rating and review.
+// @Tags Rating
+// @Param id path string true "Rating Id"
+// @Success 200 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /rating/{id} [delete]
+func (c *ratingController) ModerateRating(ctx *fiber.Ctx) error {
+	var req request.ModerateRating
+
+	id := ctx.Params("id")
+	req.RatingID = cast.ToUint(id)
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.ratingService.Moderate(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Rating could not moderate")
+		return err
+	}
+
+	slog.Info("Rating moderated", "rating_id", req.RatingID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(struct{}{}))
+}
+
 // @Summary GetUserRatings User
 // @Tags Rating
 // @Success 200 {object} response.SuccessResponse{data=response.GetUserRatings}
@@ -161,3 +198,71 @@ func (c *ratingController) GetUserRatings(ctx *fiber.Ctx) error {
 	}
 	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
 }
+
+// @Summary List Movie Ratings
+// @Tags Rating
+// @Param id     path  string true  "Movie Id"
+// @Param limit  query int    false "Page size (1-100, default 20)"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param sort   query string false "Sort order (default newest)" Enums(newest, highest, lowest, most_helpful)
+// @Success 200 {object} response.SuccessResponse{data=[]response.MovieRating,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Router /movie/{id}/ratings [get]
+func (c *ratingController) ListMovieRatings(ctx *fiber.Ctx) error {
+	var req request.ListMovieRatings
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	id := ctx.Params("id")
+	req.MovieID = cast.ToUint(id)
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.ratingService.ListByMovieID(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Ratings, res.Pagination))
+}
+
+// @Summary Vote Rating Helpful
+// @Tags Rating
+// @Param id path string true "Rating Id"
+// @Success 200 {object} response.SuccessResponse
+// @Success 400 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 409 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /rating/{id}/helpful [post]
+func (c *ratingController) VoteHelpful(ctx *fiber.Ctx) error {
+	var req request.VoteRatingHelpful
+
+	id := ctx.Params("id")
+	req.RatingID = cast.ToUint(id)
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.ratingService.VoteHelpful(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Helpful vote could not create")
+		return err
+	}
+
+	slog.Info("Helpful vote created", "rating_id", req.RatingID)
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(struct{}{}))
+}
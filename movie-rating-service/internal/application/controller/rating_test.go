@@ -0,0 +1,97 @@
+//go:build unit_test
+
+package controller
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/mocks"
+	"net/http/httptest"
+	"testing"
+)
+
+type RatingControllerTest struct {
+	suite.Suite
+	app     *fiber.App
+	service *mocks.RatingService
+}
+
+func (c *RatingControllerTest) SetupTest() {
+	c.service = new(mocks.RatingService)
+
+	c.app = fiber.New(fiber.Config{ErrorHandler: common.ErrorHandler()})
+	NewRatingController(c.app, c.service, &fakeAuth{claims: jwt.MapClaims{"user_id": float64(1)}})
+}
+
+func Test_RunRatingControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(RatingControllerTest))
+}
+
+func (c *RatingControllerTest) do(method, path string) int {
+	res, err := c.app.Test(httptest.NewRequest(method, path, nil))
+	c.Require().NoError(err)
+	return res.StatusCode
+}
+
+func (c *RatingControllerTest) TestListMovieRatings_Success() {
+	t := c.T()
+
+	c.service.On("ListByMovieID", mock.Anything, request.ListMovieRatings{
+		Pagination: request.Pagination{Limit: 10},
+		MovieID:    42,
+		Sort:       "most_helpful",
+	}).Return(&response.ListMovieRatings{Ratings: []response.MovieRating{{ID: 7, Username: "alice"}}}, nil).Once()
+
+	status := c.do(fiber.MethodGet, "/movie/42/ratings?limit=10&sort=most_helpful")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	c.service.AssertExpectations(t)
+}
+
+func (c *RatingControllerTest) TestListMovieRatings_Error_Invalid_Sort() {
+	t := c.T()
+
+	status := c.do(fiber.MethodGet, "/movie/42/ratings?sort=random")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	c.service.AssertNotCalled(t, "ListByMovieID", mock.Anything, mock.Anything)
+}
+
+func (c *RatingControllerTest) TestListMovieRatings_Error_Movie_Not_Found() {
+	t := c.T()
+
+	c.service.On("ListByMovieID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("failed to get movie: %w", gorm.ErrRecordNotFound)).Once()
+
+	status := c.do(fiber.MethodGet, "/movie/42/ratings")
+
+	assert.Equal(t, fiber.StatusNotFound, status)
+}
+
+func (c *RatingControllerTest) TestVoteHelpful_Success() {
+	t := c.T()
+
+	c.service.On("VoteHelpful", mock.Anything, request.VoteRatingHelpful{RatingID: 7, UserID: 1}).Return(nil).Once()
+
+	status := c.do(fiber.MethodPost, "/rating/7/helpful")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	c.service.AssertExpectations(t)
+}
+
+func (c *RatingControllerTest) TestVoteHelpful_Error_Own_Review() {
+	t := c.T()
+
+	c.service.On("VoteHelpful", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: cannot vote on your own review", common.ErrBadRequest)).Once()
+
+	status := c.do(fiber.MethodPost, "/rating/7/helpful")
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
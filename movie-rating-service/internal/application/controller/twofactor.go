@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+	"movie-rating-service/internal/common"
+)
+
+type twoFactorController struct {
+	twoFactorService service.TwoFactorService
+	tokenService     service.TokenService
+}
+
+func NewTwoFactorController(app *fiber.App, twoFactorService service.TwoFactorService, tokenService service.TokenService, authMiddleware middleware.AuthMiddleware) {
+	controller := &twoFactorController{twoFactorService: twoFactorService, tokenService: tokenService}
+
+	app.Post("/user/me/2fa", authMiddleware.UserHandler, controller.Enroll)
+	app.Post("/user/me/2fa/confirm", authMiddleware.UserHandler, controller.Confirm)
+	app.Delete("/user/me/2fa", authMiddleware.UserHandler, controller.Disable)
+	app.Post("/user/me/2fa/recovery-codes", authMiddleware.UserHandler, controller.RegenerateRecoveryCodes)
+
+	app.Post("/login/2fa", controller.Login)
+}
+
+// @Summary Enroll Two-Factor Authentication
+// @Description Requires the password. Returns a new TOTP secret and its otpauth:// URI to show as a QR code. Two-factor authentication is enabled once a code from the authenticator is confirmed.
+// @Tags TwoFactor
+// @Param body body request.EnrollTwoFactor true "Enrollment payload"
+// @Success 200 {object} response.SuccessResponse{data=response.TwoFactorEnrollment}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/2fa [post]
+func (c *twoFactorController) Enroll(ctx *fiber.Ctx) error {
+	var req request.EnrollTwoFactor
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.twoFactorService.Enroll(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not enroll two-factor authentication")
+		return err
+	}
+	return ctx.JSON(response.Success(res))
+}
+
+// @Summary Confirm Two-Factor Authentication
+// @Description Enables two-factor authentication with a first code from the authenticator. Returns recovery codes, which are shown only this once.
+// @Tags TwoFactor
+// @Param body body request.ConfirmTwoFactor true "Confirmation payload"
+// @Success 200 {object} response.SuccessResponse{data=response.RecoveryCodes}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/2fa/confirm [post]
+func (c *twoFactorController) Confirm(ctx *fiber.Ctx) error {
+	var req request.ConfirmTwoFactor
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.twoFactorService.Confirm(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not confirm two-factor authentication")
+		return err
+	}
+
+	slog.Info("User enabled two-factor authentication", "user_id", req.UserID)
+	return ctx.JSON(response.Success(res))
+}
+
+// @Summary Disable Two-Factor Authentication
+// @Description Requires the password and a code from the authenticator or a recovery code. Not allowed for roles that require two-factor authentication.
+// @Tags TwoFactor
+// @Param body body request.DisableTwoFactor true "Disable payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/2fa [delete]
+func (c *twoFactorController) Disable(ctx *fiber.Ctx) error {
+	var req request.DisableTwoFactor
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.twoFactorService.Disable(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not disable two-factor authentication")
+		return err
+	}
+
+	slog.Info("User disabled two-factor authentication", "user_id", req.UserID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Requires a code from the authenticator. Replaces every recovery code, used or not, with new ones.
+// @Tags TwoFactor
+// @Param body body request.RegenerateRecoveryCodes true "Regeneration payload"
+// @Success 200 {object} response.SuccessResponse{data=response.RecoveryCodes}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/2fa/recovery-codes [post]
+func (c *twoFactorController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	var req request.RegenerateRecoveryCodes
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.twoFactorService.RegenerateRecoveryCodes(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(response.Success(res))
+}
+
+// @Summary Login Second Step
+// @Description Answers the challenge from POST /login with a code from the authenticator, or a recovery code, and returns the session. Wrong codes count as failed logins.
+// @Tags User
+// @Param body body request.LoginTwoFactor true "Second step payload"
+// @Success 200 {object} response.SuccessResponse{data=response.Login}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 429 {object} response.ErrorResponse
+// @Router /login/2fa [post]
+func (c *twoFactorController) Login(ctx *fiber.Ctx) error {
+	var req request.LoginTwoFactor
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+	req.IP = ctx.IP()
+
+	user, err := c.twoFactorService.VerifyLogin(ctx.UserContext(), req)
+	if isLockedOut(ctx, err) || errors.Is(err, common.ErrForbidden) || errors.Is(err, common.ErrBadRequest) {
+		return err
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid credentials"})
+	}
+
+	res, err := c.tokenService.Issue(ctx.UserContext(), *user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.Success(res))
+}
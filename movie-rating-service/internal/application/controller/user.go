@@ -1,18 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/cast"
-	"golang.org/x/crypto/bcrypt"
 	"log/slog"
-	"movie-rating-service/config"
 	"movie-rating-service/internal/application/middleware"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
 	"movie-rating-service/internal/application/service"
 	validate "movie-rating-service/internal/application/validator"
-	"time"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"strconv"
 )
 
 /*
@@ -23,21 +24,29 @@ No need to create unnecessary interface for controllers, because:
 */
 
 type userController struct {
-	userService service.UserService
+	userService      service.UserService
+	tokenService     service.TokenService
+	accountService   service.AccountService
+	twoFactorService service.TwoFactorService
 }
 
-func NewUserController(app *fiber.App, userService service.UserService) {
-	authMiddleware := middleware.NewAuthMiddleware(config.Cfg.JWTSecret)
-
-	controller := &userController{userService: userService}
+func NewUserController(app *fiber.App, userService service.UserService, tokenService service.TokenService, accountService service.AccountService, twoFactorService service.TwoFactorService, authMiddleware middleware.AuthMiddleware) {
+	controller := &userController{userService: userService, tokenService: tokenService, accountService: accountService, twoFactorService: twoFactorService}
 
 	app.Post("/user", controller.CreateUser)
-	app.Get("/user/:id", authMiddleware.AdminHandler, controller.GetUser)
+	app.Get("/user/me", authMiddleware.UserHandler, controller.GetMe)
+	app.Patch("/user/me", authMiddleware.UserHandler, controller.UpdateMe)
+	app.Post("/user/me/password", authMiddleware.UserHandler, controller.ChangePassword)
+	app.Delete("/user/me", authMiddleware.UserHandler, controller.DeleteMe)
+	app.Get("/user/:id", authMiddleware.RequirePermission(domain.PermissionUserRead), controller.GetUser)
 
 	app.Post("/login", controller.Login)
+	app.Post("/token/refresh", controller.RefreshToken)
+	app.Post("/logout", authMiddleware.UserHandler, controller.Logout)
 }
 
 // @Summary Create User
+// @Description The password must satisfy the password policy: by default 8 to 72 characters and not a known breached password.
 // @Tags User
 // @Param body body request.CreateUser true "User create payload"
 // @Success 200 {object} response.SuccessResponse{data=response.CreateUser}
@@ -54,13 +63,6 @@ func (c *userController) CreateUser(ctx *fiber.Ctx) error {
 		return err
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to hash password"})
-	}
-	req.Password = string(hashedPassword)
-
 	// Save user
 	res, err := c.userService.Create(ctx.UserContext(), req)
 	if err != nil {
@@ -69,6 +71,11 @@ func (c *userController) CreateUser(ctx *fiber.Ctx) error {
 	}
 
 	slog.Info("User created", "user_id", res.ID)
+
+	// The account exists either way; the user can ask for another mail.
+	if err = c.accountService.SendVerification(ctx.UserContext(), request.SendVerification{UserID: res.ID}); err != nil {
+		slog.Warn("Verification mail could not send", "user_id", res.ID, "error", err)
+	}
 	return ctx.Status(fiber.StatusCreated).JSON(response.Success(res))
 }
 
@@ -78,6 +85,8 @@ func (c *userController) CreateUser(ctx *fiber.Ctx) error {
 // @Success 200 {object} response.SuccessResponse{data=response.GetUser}
 // @Success 400 {object} response.ErrorResponse
 // @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /user/{id} [get]
 func (c *userController) GetUser(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
@@ -95,12 +104,153 @@ func (c *userController) GetUser(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
 }
 
+// @Summary Get Own Profile
+// @Tags User
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me [get]
+func (c *userController) GetMe(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.GetUser{ID: cast.ToUint(claims["user_id"])}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userService.Get(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Update Own Profile
+// @Description Updates only the fields present in the payload. A changed email has to be verified again; a verification mail is sent to it.
+// @Tags User
+// @Param body body request.UpdateProfile true "Profile update payload"
+// @Success 200 {object} response.SuccessResponse{data=response.GetUser}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me [patch]
+func (c *userController) UpdateMe(ctx *fiber.Ctx) error {
+	var req request.UpdateProfile
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.userService.UpdateProfile(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not update profile")
+		return err
+	}
+
+	slog.Info("User profile updated", "user_id", req.UserID)
+
+	if req.Email != nil && !res.EmailVerified {
+		if err = c.accountService.SendVerification(ctx.UserContext(), request.SendVerification{UserID: req.UserID}); err != nil {
+			slog.Warn("Verification mail could not send", "user_id", req.UserID, "error", err)
+		}
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.Success(res))
+}
+
+// @Summary Change Own Password
+// @Description Requires the current password; the new one must satisfy the password policy. Ends every session of the user, including other devices, and returns a new one.
+// @Tags User
+// @Param body body request.ChangePassword true "Password change payload"
+// @Success 200 {object} response.SuccessResponse{data=response.Login}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/password [post]
+func (c *userController) ChangePassword(ctx *fiber.Ctx) error {
+	var req request.ChangePassword
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.userService.ChangePassword(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not change password")
+		return err
+	}
+
+	res, err := c.tokenService.Issue(ctx.UserContext(), *user)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("User changed password", "user_id", req.UserID)
+	return ctx.JSON(response.Success(res))
+}
+
+// @Summary Delete Own Account
+// @Description Requires the password. Erases the profile and ends every session; ratings stay, without the author's personal data.
+// @Tags User
+// @Param body body request.DeleteAccount true "Account deletion payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me [delete]
+func (c *userController) DeleteMe(ctx *fiber.Ctx) error {
+	var req request.DeleteAccount
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.userService.Delete(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("User could not delete account")
+		return err
+	}
+
+	slog.Info("User deleted account", "user_id", req.UserID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
 // @Summary Login
+// @Description Returns a short-lived access token and a refresh token to renew it with. For an account with two-factor authentication it only returns two_factor_required and a challenge_token for POST /login/2fa. After repeated failures the username or client address is locked out for a while; 429 responses carry a Retry-After header.
 // @Tags User
 // @Param body body request.Login true "User login payload"
-// @Success 200 {object} response.SuccessResponse{data=string} // JWT token
+// @Success 200 {object} response.SuccessResponse{data=response.Login}
 // @Success 400 {object} response.ErrorResponse
 // @Success 401 {object} response.ErrorResponse
+// @Success 403 {object} response.ErrorResponse
+// @Success 429 {object} response.ErrorResponse
 // @Router /login [post]
 func (c *userController) Login(ctx *fiber.Ctx) error {
 	var req request.Login
@@ -111,24 +261,106 @@ func (c *userController) Login(ctx *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	req.IP = ctx.IP()
 
 	user, err := c.userService.IsAuthorized(ctx.UserContext(), req)
+	if isLockedOut(ctx, err) || errors.Is(err, common.ErrForbidden) {
+		return err
+	}
 	if err != nil {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid credentials"})
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"username": user.Username,
-		"isAdmin":  user.IsAdmin,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(),
-	})
-	tokenString, err := token.SignedString([]byte(config.Cfg.JWTSecret))
+	// The password is only the first step; the session is started by POST /login/2fa.
+	if user.TwoFactorEnabled {
+		res, err := c.twoFactorService.Challenge(ctx.UserContext(), *user)
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(response.Success(res))
+	}
+
+	res, err := c.tokenService.Issue(ctx.UserContext(), *user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.Success(res))
+}
+
+// isLockedOut reports whether a login was refused for too many failures, and tells the client when
+// to retry.
+func isLockedOut(ctx *fiber.Ctx, err error) bool {
+	var locked *service.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(locked.RetryAfterSeconds()))
+	return true
+}
+
+// @Summary Refresh Token
+// @Description Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one ends the session.
+// @Tags User
+// @Param body body request.RefreshToken true "Refresh token payload"
+// @Success 200 {object} response.SuccessResponse{data=response.Login}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Router /token/refresh [post]
+func (c *userController) RefreshToken(ctx *fiber.Ctx) error {
+	var req request.RefreshToken
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.tokenService.Refresh(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(response.Success(res))
+}
+
+// @Summary Logout
+// @Description Revokes the access token used for this request, plus the session of the given refresh token, or every session of the user with all=true.
+// @Tags User
+// @Param body body request.Logout false "Logout payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /logout [post]
+func (c *userController) Logout(ctx *fiber.Ctx) error {
+	var req request.Logout
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+
+	req.UserID = cast.ToUint(claims["user_id"])
+	req.JTI = cast.ToString(claims["jti"])
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		req.ExpiresAt = exp.Time
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.tokenService.Logout(ctx.UserContext(), req)
 	if err != nil {
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Token could not be signed"})
+		return err
 	}
 
-	return ctx.JSON(response.Success(response.Login{
-		Username: req.Username,
-		Token:    tokenString}))
+	slog.Info("User logged out", "user_id", req.UserID, "all", req.All)
+	return ctx.SendStatus(fiber.StatusNoContent)
 }
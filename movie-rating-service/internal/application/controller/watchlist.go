@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/spf13/cast"
+	"log/slog"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/application/service"
+	validate "movie-rating-service/internal/application/validator"
+)
+
+type watchlistController struct {
+	watchlistService service.WatchlistService
+}
+
+func NewWatchlistController(app *fiber.App, watchlistService service.WatchlistService, authMiddleware middleware.AuthMiddleware) {
+	controller := &watchlistController{watchlistService: watchlistService}
+
+	app.Get("/user/me/watchlist", authMiddleware.UserHandler, controller.ListWatchlist)
+	app.Post("/user/me/watchlist", authMiddleware.UserHandler, controller.AddToWatchlist)
+	app.Put("/user/me/watchlist/order", authMiddleware.UserHandler, controller.ReorderWatchlist)
+	app.Delete("/user/me/watchlist/:movieId", authMiddleware.UserHandler, controller.RemoveFromWatchlist)
+}
+
+// @Summary List Watchlist
+// @Description Lists the movies on your watchlist in your order. Movies deleted since you added them are left out.
+// @Tags Watchlist
+// @Param limit    query int     false "Page size (1-100, default 20)"
+// @Param cursor   query string  false "Cursor returned as next_cursor by the previous page"
+// @Param genre    query string  false "Genre (case-insensitive exact match)"
+// @Param released query boolean false "Only movies released (true) or not yet released (false) by the current year; movies without a year count as not yet released"
+// @Success 200 {object} response.SuccessResponse{data=[]response.WatchlistItem,pagination=response.Pagination}
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/watchlist [get]
+func (c *watchlistController) ListWatchlist(ctx *fiber.Ctx) error {
+	var req request.ListWatchlist
+	if err := ctx.QueryParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.watchlistService.List(ctx.UserContext(), req)
+	if err != nil {
+		return err
+	}
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessWithPagination(res.Items, res.Pagination))
+}
+
+// @Summary Add to Watchlist
+// @Description Adds the movie at the end of your watchlist. Adding a movie that is already on it changes nothing. Rating a movie takes it off the watchlist.
+// @Tags Watchlist
+// @Param body body request.AddToWatchlist true "Watchlist payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/watchlist [post]
+func (c *watchlistController) AddToWatchlist(ctx *fiber.Ctx) error {
+	var req request.AddToWatchlist
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.watchlistService.Add(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Watchlist item could not add")
+		return err
+	}
+
+	slog.Info("Watchlist item added", "user_id", req.UserID, "movie_id", req.MovieID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Reorder Watchlist
+// @Description Moves the given movies to the top of your watchlist in the given order. The movies left out follow in their current order.
+// @Tags Watchlist
+// @Param body body request.ReorderWatchlist true "Watchlist order payload"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/watchlist/order [put]
+func (c *watchlistController) ReorderWatchlist(ctx *fiber.Ctx) error {
+	var req request.ReorderWatchlist
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req.UserID = cast.ToUint(claims["user_id"])
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.watchlistService.Reorder(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Watchlist could not reorder")
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// @Summary Remove from Watchlist
+// @Tags Watchlist
+// @Param movieId path string true "Movie Id"
+// @Success 204
+// @Success 400 {object} response.ErrorResponse
+// @Success 401 {object} response.ErrorResponse
+// @Success 404 {object} response.ErrorResponse
+// @Success 500 {object} response.ErrorResponse
+// @Security BearerAuth
+// @Router /user/me/watchlist/{movieId} [delete]
+func (c *watchlistController) RemoveFromWatchlist(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("user").(jwt.MapClaims)
+	req := request.RemoveFromWatchlist{
+		UserID:  cast.ToUint(claims["user_id"]),
+		MovieID: cast.ToUint(ctx.Params("movieId")),
+	}
+
+	err := validate.V.Struct(req)
+	if err != nil {
+		return err
+	}
+
+	err = c.watchlistService.Remove(ctx.UserContext(), req)
+	if err != nil {
+		slog.Info("Watchlist item could not remove")
+		return err
+	}
+
+	slog.Info("Watchlist item removed", "user_id", req.UserID, "movie_id", req.MovieID)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
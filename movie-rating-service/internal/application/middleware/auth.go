@@ -1,43 +1,124 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
-	"movie-rating-service/config"
+	"github.com/spf13/cast"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"slices"
 	"strings"
+	"time"
 )
 
 type AuthMiddleware interface {
-	AdminHandler(ctx *fiber.Ctx) error
 	UserHandler(ctx *fiber.Ctx) error
+	// RequirePermission authenticates like UserHandler, or with an API key in the X-API-Key header,
+	// and additionally requires the token or key to grant every one of the given permissions.
+	RequirePermission(permissions ...string) fiber.Handler
+	// RequireLoginPermission is RequirePermission without API keys, for routes a key must not reach,
+	// e.g. those that create keys.
+	RequireLoginPermission(permissions ...string) fiber.Handler
+	// OptionalUserHandler lets anonymous requests through. A request that does carry a token or an
+	// API key must authenticate with it, and then sets the user like UserHandler does.
+	OptionalUserHandler(ctx *fiber.Ctx) error
+}
+
+// RevocationChecker reports whether an access token was revoked before it expired, e.g. by logout
+// or because its user was banned.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error)
+}
+
+// KeySet resolves the public key a token was signed with, see service.KeyRing.
+type KeySet interface {
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	Methods() []string
+}
+
+// APIKeyAuthenticator resolves an API key to the user it acts as, see service.APIKeyService.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*response.APIKeyIdentity, error)
+}
+
+// authError is a rejected token; its text is returned to the client with 401.
+type authError string
+
+func (e authError) Error() string {
+	return string(e)
 }
 
 type authMiddleware struct {
-	Secret string
+	keys        KeySet
+	revocations RevocationChecker
+	apiKeys     APIKeyAuthenticator
 }
 
-func NewAuthMiddleware(secret string) AuthMiddleware {
-	return &authMiddleware{Secret: secret}
+// NewAuthMiddleware creates the middleware. API keys are only accepted by RequirePermission and
+// OptionalUserHandler: the routes behind UserHandler manage the account itself, which takes a login.
+func NewAuthMiddleware(keys KeySet, revocations RevocationChecker, apiKeys APIKeyAuthenticator) AuthMiddleware {
+	return &authMiddleware{keys: keys, revocations: revocations, apiKeys: apiKeys}
 }
 
-func (a *authMiddleware) AdminHandler(ctx *fiber.Ctx) error {
-	claims, err := authBase(ctx)
-	if err != nil {
-		return err
+func (a *authMiddleware) RequirePermission(permissions ...string) fiber.Handler {
+	return a.requirePermission(true, permissions)
+}
+
+func (a *authMiddleware) RequireLoginPermission(permissions ...string) fiber.Handler {
+	return a.requirePermission(false, permissions)
+}
+
+func (a *authMiddleware) requirePermission(allowAPIKey bool, permissions []string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		var claims jwt.MapClaims
+		var err error
+		if key := ctx.Get("X-API-Key"); key != "" && allowAPIKey {
+			claims, err = a.authAPIKey(ctx, key)
+		} else {
+			claims, err = a.authBase(ctx)
+		}
+		if err != nil {
+			return reject(ctx, err)
+		}
+
+		// A token without a permissions claim grants nothing.
+		granted, _ := claims["permissions"].([]interface{})
+		for _, permission := range permissions {
+			if !slices.Contains(granted, interface{}(permission)) {
+				return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "You are not allowed to access this resource"})
+			}
+		}
+		ctx.Locals("user", claims)
+
+		return ctx.Next()
 	}
+}
 
-	if val, ok := claims["isAdmin"]; ok && val.(bool) == false {
-		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "You are not allowed to access this resource"})
+func (a *authMiddleware) UserHandler(ctx *fiber.Ctx) error {
+	claims, err := a.authBase(ctx)
+	if err != nil {
+		return reject(ctx, err)
 	}
+
 	ctx.Locals("user", claims)
 
 	return ctx.Next()
 }
 
-func (a *authMiddleware) UserHandler(ctx *fiber.Ctx) error {
-	claims, err := authBase(ctx)
+func (a *authMiddleware) OptionalUserHandler(ctx *fiber.Ctx) error {
+	var claims jwt.MapClaims
+	var err error
+	if key := ctx.Get("X-API-Key"); key != "" {
+		claims, err = a.authAPIKey(ctx, key)
+	} else if ctx.Get("Authorization") != "" {
+		claims, err = a.authBase(ctx)
+	} else {
+		return ctx.Next()
+	}
 	if err != nil {
-		return err
+		return reject(ctx, err)
 	}
 
 	ctx.Locals("user", claims)
@@ -45,31 +126,83 @@ func (a *authMiddleware) UserHandler(ctx *fiber.Ctx) error {
 	return ctx.Next()
 }
 
-func authBase(ctx *fiber.Ctx) (jwt.MapClaims, error) {
+func (a *authMiddleware) authBase(ctx *fiber.Ctx) (jwt.MapClaims, error) {
 	authHeader := ctx.Get("Authorization")
+	if authHeader == "" && ctx.Get("X-API-Key") != "" {
+		return nil, authError("API keys are not accepted here")
+	}
 	if authHeader == "" {
-		return nil, ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing Authorization header"})
+		return nil, authError("Missing Authorization header")
 	}
 
 	tokenString := removeBearer(authHeader)
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fiber.NewError(fiber.StatusUnauthorized, "Unexpected signing method")
-		}
-		return []byte(config.Cfg.JWTSecret), nil
-	})
+	token, err := jwt.Parse(tokenString, a.keys.Keyfunc, jwt.WithValidMethods(a.keys.Methods()))
 
 	if err != nil || !token.Valid {
-		return nil, ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		return nil, authError("Invalid or expired token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return nil, ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token claims"})
+		return nil, authError("Invalid token claims")
+	}
+
+	// Tokens without a jti cannot be revoked, so they are not accepted at all.
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, authError("Invalid token claims")
+	}
+	var issuedAt time.Time
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		issuedAt = iat.Time
+	}
+	revoked, err := a.revocations.IsRevoked(ctx.UserContext(), jti, cast.ToUint(claims["user_id"]), issuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, authError("Token has been revoked")
 	}
 	return claims, nil
 }
 
+// authAPIKey returns claims like those of an access token, so that handlers need not care how the
+// request was authenticated. api_key_id tells them apart.
+func (a *authMiddleware) authAPIKey(ctx *fiber.Ctx, key string) (jwt.MapClaims, error) {
+	identity, err := a.apiKeys.Authenticate(ctx.UserContext(), key)
+	if errors.Is(err, common.ErrUnauthorized) {
+		return nil, authError("Invalid or expired API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return jwt.MapClaims{
+		"user_id":     identity.UserID,
+		"username":    identity.Username,
+		"roles":       toClaimList(identity.Roles),
+		"permissions": toClaimList(identity.Permissions),
+		"api_key_id":  identity.KeyID,
+	}, nil
+}
+
+// toClaimList converts to the type a list claim has once parsed from a token.
+func toClaimList(values []string) []interface{} {
+	list := make([]interface{}, len(values))
+	for i, value := range values {
+		list[i] = value
+	}
+	return list
+}
+
+// reject answers 401 for a rejected token and leaves any other failure to the error handler.
+func reject(ctx *fiber.Ctx, err error) error {
+	var authErr authError
+	if errors.As(err, &authErr) {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": authErr.Error()})
+	}
+	return err
+}
+
 func removeBearer(tokenStr string) string {
 	if strings.HasPrefix(strings.ToLower(tokenStr), "bearer ") {
 		tokenStr = strings.Split(tokenStr, " ")[1]
@@ -0,0 +1,327 @@
+//go:build unit_test
+
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/mocks"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type AuthMiddlewareTest struct {
+	suite.Suite
+	app         *fiber.App
+	revocations *mocks.RevocationChecker
+	apiKeys     *mocks.APIKeyAuthenticator
+	private     ed25519.PrivateKey
+	reached     bool
+	user        jwt.MapClaims
+}
+
+// staticKeys verifies tokens against a single Ed25519 key with kid "test".
+type staticKeys struct {
+	public ed25519.PublicKey
+}
+
+func (k staticKeys) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Header["kid"] != "test" {
+		return nil, errors.New("unknown signing key")
+	}
+	return k.public, nil
+}
+
+func (k staticKeys) Methods() []string {
+	return []string{jwt.SigningMethodEdDSA.Alg()}
+}
+
+func (a *AuthMiddlewareTest) SetupTest() {
+	a.revocations = new(mocks.RevocationChecker)
+	a.apiKeys = new(mocks.APIKeyAuthenticator)
+	a.reached = false
+	a.user = nil
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	a.Require().NoError(err)
+	a.private = private
+
+	auth := NewAuthMiddleware(staticKeys{public: public}, a.revocations, a.apiKeys)
+	a.app = fiber.New()
+	handler := func(ctx *fiber.Ctx) error {
+		a.reached = true
+		return ctx.SendStatus(fiber.StatusOK)
+	}
+	a.app.Get("/user", auth.UserHandler, handler)
+	a.app.Get("/movie", auth.RequirePermission("movie:write", "review:write"), handler)
+	a.app.Get("/api-keys", auth.RequireLoginPermission("user:manage"), handler)
+	a.app.Get("/lists", auth.OptionalUserHandler, func(ctx *fiber.Ctx) error {
+		a.user, _ = ctx.Locals("user").(jwt.MapClaims)
+		return handler(ctx)
+	})
+}
+
+func Test_RunAuthMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthMiddlewareTest))
+}
+
+func (a *AuthMiddlewareTest) token(claims jwt.MapClaims) string {
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(a.private)
+	a.Require().NoError(err)
+	return signed
+}
+
+func (a *AuthMiddlewareTest) do(path, token string) int {
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := a.app.Test(req)
+	a.Require().NoError(err)
+	return res.StatusCode
+}
+
+func (a *AuthMiddlewareTest) doWithAPIKey(path, key string) int {
+	req := httptest.NewRequest(fiber.MethodGet, path, nil)
+	req.Header.Set("X-API-Key", key)
+	res, err := a.app.Test(req)
+	a.Require().NoError(err)
+	return res.StatusCode
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Success() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/user", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1}))
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_Missing_Header() {
+	t := a.T()
+
+	status := a.do("/user", "")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_Invalid_Token() {
+	t := a.T()
+
+	status := a.do("/user", "not-a-token")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_HMAC_Token() {
+	t := a.T()
+
+	claims := jwt.MapClaims{"jti": "jti", "user_id": 1, "exp": time.Now().Add(time.Minute).Unix()}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString([]byte("secret"))
+	a.Require().NoError(err)
+
+	status := a.do("/user", signed)
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_Missing_JTI() {
+	t := a.T()
+
+	status := a.do("/user", a.token(jwt.MapClaims{"user_id": 1}))
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_Revoked() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(true, nil).Once()
+
+	status := a.do("/user", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1}))
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_Revocation_Check_Failed() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, errors.New("there is an error")).Once()
+
+	status := a.do("/user", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1}))
+
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_Success() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/movie", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1, "permissions": []string{"movie:write", "review:write", "user:read"}}))
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_Error_Missing_Permission() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/movie", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1, "permissions": []string{"review:write"}}))
+
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_Error_Missing_Claim() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/movie", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1}))
+
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_Error_Unauthenticated() {
+	t := a.T()
+
+	status := a.do("/movie", "")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_API_Key_Success() {
+	t := a.T()
+
+	a.apiKeys.On("Authenticate", mock.Anything, "mrs_key").Return(&response.APIKeyIdentity{KeyID: 7, UserID: 1, Permissions: []string{"movie:write", "review:write"}}, nil).Once()
+
+	status := a.doWithAPIKey("/movie", "mrs_key")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+	a.revocations.AssertNotCalled(t, "IsRevoked", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_API_Key_Error_Missing_Scope() {
+	t := a.T()
+
+	a.apiKeys.On("Authenticate", mock.Anything, "mrs_key").Return(&response.APIKeyIdentity{KeyID: 7, UserID: 1, Permissions: []string{"review:write"}}, nil).Once()
+
+	status := a.doWithAPIKey("/movie", "mrs_key")
+
+	assert.Equal(t, fiber.StatusForbidden, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequirePermission_API_Key_Error_Invalid() {
+	t := a.T()
+
+	a.apiKeys.On("Authenticate", mock.Anything, "mrs_key").Return(nil, common.ErrUnauthorized).Once()
+
+	status := a.doWithAPIKey("/movie", "mrs_key")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequireLoginPermission_Success() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/api-keys", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1, "permissions": []string{"user:manage"}}))
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+}
+
+func (a *AuthMiddlewareTest) TestRequireLoginPermission_Error_API_Key() {
+	t := a.T()
+
+	status := a.doWithAPIKey("/api-keys", "mrs_key")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+	a.apiKeys.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
+
+func (a *AuthMiddlewareTest) TestUserHandler_Error_API_Key() {
+	t := a.T()
+
+	status := a.doWithAPIKey("/user", "mrs_key")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+	a.apiKeys.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+}
+
+func (a *AuthMiddlewareTest) TestOptionalUserHandler_Anonymous() {
+	t := a.T()
+
+	status := a.do("/lists", "")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+	assert.Nil(t, a.user)
+}
+
+func (a *AuthMiddlewareTest) TestOptionalUserHandler_Token() {
+	t := a.T()
+
+	a.revocations.On("IsRevoked", mock.Anything, "jti", uint(1), mock.Anything).Return(false, nil).Once()
+
+	status := a.do("/lists", a.token(jwt.MapClaims{"jti": "jti", "user_id": 1}))
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+	assert.EqualValues(t, 1, a.user["user_id"])
+}
+
+func (a *AuthMiddlewareTest) TestOptionalUserHandler_API_Key() {
+	t := a.T()
+
+	a.apiKeys.On("Authenticate", mock.Anything, "mrs_key").Return(&response.APIKeyIdentity{KeyID: 7, UserID: 1}, nil).Once()
+
+	status := a.doWithAPIKey("/lists", "mrs_key")
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, a.reached)
+	assert.EqualValues(t, 1, a.user["user_id"])
+}
+
+func (a *AuthMiddlewareTest) TestOptionalUserHandler_Error_Invalid_Token() {
+	t := a.T()
+
+	status := a.do("/lists", "not-a-token")
+
+	assert.Equal(t, fiber.StatusUnauthorized, status)
+	assert.False(t, a.reached)
+}
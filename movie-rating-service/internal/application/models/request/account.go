@@ -0,0 +1,18 @@
+package request
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+type SendVerification struct {
+	UserID uint `json:"-" validate:"required"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
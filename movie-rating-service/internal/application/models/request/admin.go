@@ -0,0 +1,6 @@
+package request
+
+type Reconcile struct {
+	DryRun    bool `query:"dry_run"`
+	BatchSize int  `query:"batch_size" validate:"omitempty,gte=1,lte=5000"`
+}
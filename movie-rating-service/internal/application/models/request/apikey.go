@@ -0,0 +1,23 @@
+package request
+
+import "time"
+
+// CreateAPIKey without ExpiresAt gets the default lifetime. GrantorID and GrantorPermissions are the
+// user and the permissions of the session creating the key.
+type CreateAPIKey struct {
+	UserID             uint       `json:"-" validate:"required"`
+	Name               string     `json:"name" validate:"required,max=100"`
+	Scopes             []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	GrantorID          uint       `json:"-" validate:"required"`
+	GrantorPermissions []string   `json:"-"`
+}
+
+type ListAPIKeys struct {
+	UserID uint `json:"-" validate:"required"`
+}
+
+type RevokeAPIKey struct {
+	UserID uint `json:"-" validate:"required"`
+	KeyID  uint `json:"-" validate:"required"`
+}
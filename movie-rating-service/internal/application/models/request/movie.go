@@ -23,3 +23,34 @@ type DeleteMovie struct {
 type GetMovie struct {
 	ID uint `param:"id" validate:"required"`
 }
+
+type GetMovieStats struct {
+	ID uint `param:"id" validate:"required"`
+}
+
+type ListMovies struct {
+	Pagination
+	Genre          string  `query:"genre"`
+	Director       string  `query:"director"`
+	YearFrom       int     `query:"year_from" validate:"omitempty,gte=0"`
+	YearTo         int     `query:"year_to" validate:"omitempty,gte=0,gtefield=YearFrom"`
+	MinRating      float64 `query:"min_rating" validate:"omitempty,gte=0,lte=5"`
+	MinRatingCount int64   `query:"min_rating_count" validate:"omitempty,gte=0"`
+	SortBy         string  `query:"sort_by" validate:"omitempty,oneof=rating weighted_rating year title created_at"`
+	Order          string  `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+type SearchMovies struct {
+	Pagination
+	Query string `query:"q" validate:"required,min=2,max=200"`
+}
+
+type SuggestMovies struct {
+	Prefix string `query:"prefix" validate:"required,max=100"`
+	Limit  int    `query:"limit" validate:"omitempty,gte=1,lte=20"`
+}
+
+type TopMovies struct {
+	Limit int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Genre string `query:"genre"`
+}
@@ -0,0 +1,97 @@
+package request
+
+type CreateMovieList struct {
+	UserID      uint   `json:"-" validate:"required"`
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=2000"`
+	Visibility  string `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+}
+
+// UpdateMovieList changes only the fields that are present in the payload.
+type UpdateMovieList struct {
+	ListID      uint    `json:"-" validate:"required"`
+	UserID      uint    `json:"-" validate:"required"`
+	Name        *string `json:"name" validate:"omitnil,min=1,max=100"`
+	Description *string `json:"description" validate:"omitnil,max=2000"`
+	Visibility  *string `json:"visibility" validate:"omitnil,oneof=public unlisted private"`
+}
+
+// GetMovieList is also used by anonymous callers, whose UserID is 0.
+// GetMovieList finds the list by its id or, for unlisted lists shared by link, its slug.
+type GetMovieList struct {
+	ListID uint   `json:"-" validate:"required_without=Slug"`
+	Slug   string `json:"-"`
+	UserID uint   `json:"-"`
+}
+
+type DeleteMovieList struct {
+	ListID uint `json:"-" validate:"required"`
+	UserID uint `json:"-" validate:"required"`
+}
+
+type BrowseMovieLists struct {
+	Pagination
+	Query string `query:"q" validate:"omitempty,max=100"`
+}
+
+type ListMyMovieLists struct {
+	Pagination
+	UserID uint `json:"-" validate:"required"`
+}
+
+// ListMovieListItems is also used by anonymous callers, whose UserID is 0.
+// ListMovieListItems finds the list like GetMovieList.
+type ListMovieListItems struct {
+	Pagination
+	ListID uint   `json:"-" validate:"required_without=Slug"`
+	Slug   string `json:"-"`
+	UserID uint   `json:"-"`
+}
+
+type AddMovieListItem struct {
+	ListID  uint   `json:"-" validate:"required"`
+	UserID  uint   `json:"-" validate:"required"`
+	MovieID uint   `json:"movie_id" validate:"required"`
+	Note    string `json:"note" validate:"max=1000"`
+}
+
+type UpdateMovieListItem struct {
+	ListID  uint   `json:"-" validate:"required"`
+	UserID  uint   `json:"-" validate:"required"`
+	MovieID uint   `json:"-" validate:"required"`
+	Note    string `json:"note" validate:"max=1000"`
+}
+
+type RemoveMovieListItem struct {
+	ListID  uint `json:"-" validate:"required"`
+	UserID  uint `json:"-" validate:"required"`
+	MovieID uint `json:"-" validate:"required"`
+}
+
+type ReorderMovieList struct {
+	ListID   uint   `json:"-" validate:"required"`
+	UserID   uint   `json:"-" validate:"required"`
+	MovieIDs []uint `json:"movie_ids" validate:"required,max=500,unique,dive,required"`
+}
+
+type AddMovieListEditor struct {
+	ListID   uint   `json:"-" validate:"required"`
+	UserID   uint   `json:"-" validate:"required"`
+	Username string `json:"username" validate:"required"`
+}
+
+type RemoveMovieListEditor struct {
+	ListID   uint   `json:"-" validate:"required"`
+	UserID   uint   `json:"-" validate:"required"`
+	Username string `json:"-" validate:"required"`
+}
+
+// CloneMovieList copies a list the caller can see, found by its id or its slug, into a new list of
+// theirs. The name defaults to the original's and the visibility to private.
+type CloneMovieList struct {
+	ListID     uint   `json:"-" validate:"required_without=Slug"`
+	Slug       string `json:"-"`
+	UserID     uint   `json:"-" validate:"required"`
+	Name       string `json:"name" validate:"max=100"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=public unlisted private"`
+}
@@ -0,0 +1,6 @@
+package request
+
+type Pagination struct {
+	Limit  int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Cursor string `query:"cursor"`
+}
@@ -0,0 +1,18 @@
+package request
+
+type ExportUserData struct {
+	UserID uint   `json:"-" validate:"required"`
+	Format string `query:"format" validate:"omitempty,oneof=json zip"`
+}
+
+// EraseAccount is a user's request to erase their own data.
+type EraseAccount struct {
+	UserID   uint   `json:"-" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// EraseUser is an erasure carried out by an admin, e.g. for a request received by mail.
+type EraseUser struct {
+	ActorID uint `json:"-" validate:"required"`
+	UserID  uint `param:"id" validate:"required"`
+}
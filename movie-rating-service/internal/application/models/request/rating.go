@@ -22,3 +22,18 @@ type DeleteRating struct {
 type GetUserRatings struct {
 	UserID uint `param:"id" validate:"required"`
 }
+
+type ListMovieRatings struct {
+	Pagination
+	MovieID uint   `param:"id" validate:"required"`
+	Sort    string `query:"sort" validate:"omitempty,oneof=newest highest lowest most_helpful"`
+}
+
+type ModerateRating struct {
+	RatingID uint `param:"id" validate:"required"`
+}
+
+type VoteRatingHelpful struct {
+	RatingID uint `param:"id" validate:"required"`
+	UserID   uint `json:"-" validate:"required"`
+}
@@ -0,0 +1,16 @@
+package request
+
+import "time"
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type Logout struct {
+	UserID    uint      `json:"-" validate:"required"`
+	JTI       string    `json:"-" validate:"required"`
+	ExpiresAt time.Time `json:"-"`
+	// RefreshToken ends the session it belongs to; All ends every session of the user.
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"`
+}
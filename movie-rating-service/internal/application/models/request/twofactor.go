@@ -0,0 +1,31 @@
+package request
+
+type EnrollTwoFactor struct {
+	UserID   uint   `json:"-" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ConfirmTwoFactor struct {
+	UserID uint   `json:"-" validate:"required"`
+	Code   string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// DisableTwoFactor takes a code from the authenticator or a recovery code.
+type DisableTwoFactor struct {
+	UserID   uint   `json:"-" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=20"`
+}
+
+type RegenerateRecoveryCodes struct {
+	UserID uint   `json:"-" validate:"required"`
+	Code   string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// LoginTwoFactor answers a login challenge with a code from the authenticator, or a recovery code.
+type LoginTwoFactor struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `json:"recovery_code" validate:"required_without=Code,omitempty,max=20"`
+	IP             string `json:"-"`
+}
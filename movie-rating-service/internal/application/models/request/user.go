@@ -8,7 +8,6 @@ type CreateUser struct {
 	Email    string `json:"email" validate:"required,email"`
 	Phone    string `json:"phone"`
 	Address  string `json:"address"`
-	IsAdmin  bool   `json:"is_admin"`
 }
 
 type GetUser struct {
@@ -18,4 +17,60 @@ type GetUser struct {
 type Login struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	IP       string `json:"-"`
+}
+
+type ListUsers struct {
+	Pagination
+	Query  string `query:"q" validate:"omitempty,max=200"`
+	Role   string `query:"role"`
+	Status string `query:"status" validate:"omitempty,oneof=active disabled banned"`
+}
+
+type UserRole struct {
+	ActorID uint   `json:"-" validate:"required"`
+	UserID  uint   `param:"id" validate:"required"`
+	Role    string `param:"role" validate:"required"`
+}
+
+type UpdateUserStatus struct {
+	ActorID uint   `json:"-" validate:"required"`
+	UserID  uint   `json:"-" validate:"required"`
+	Status  string `json:"status" validate:"required,oneof=active disabled banned"`
+	Reason  string `json:"reason" validate:"max=500"`
+}
+
+type ForcePasswordReset struct {
+	UserID uint `param:"id" validate:"required"`
+}
+
+// UpdateProfile changes only the fields that are present in the payload.
+type UpdateProfile struct {
+	UserID  uint    `json:"-" validate:"required"`
+	Name    *string `json:"name" validate:"omitnil,min=1,max=100"`
+	Surname *string `json:"surname" validate:"omitnil,min=1,max=100"`
+	Email   *string `json:"email" validate:"omitnil,email"`
+	Phone   *string `json:"phone" validate:"omitnil,max=50"`
+	Address *string `json:"address" validate:"omitnil,max=500"`
+}
+
+type ChangePassword struct {
+	UserID          uint   `json:"-" validate:"required"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type DeleteAccount struct {
+	UserID   uint   `json:"-" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// OIDCCallback is the redirect back from the identity provider, with either a code or an error.
+type OIDCCallback struct {
+	State            string `query:"state" validate:"required"`
+	Code             string `query:"code" validate:"required_without=Error"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+	// BrowserState is the state from the cookie set when the login started.
+	BrowserState string `query:"-"`
 }
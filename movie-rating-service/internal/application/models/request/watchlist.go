@@ -0,0 +1,24 @@
+package request
+
+type AddToWatchlist struct {
+	UserID  uint `json:"-" validate:"required"`
+	MovieID uint `json:"movie_id" validate:"required"`
+}
+
+type RemoveFromWatchlist struct {
+	UserID  uint `json:"-" validate:"required"`
+	MovieID uint `param:"movieId" validate:"required"`
+}
+
+// ReorderWatchlist lists every movie on the watchlist once, in the new order.
+type ReorderWatchlist struct {
+	UserID   uint   `json:"-" validate:"required"`
+	MovieIDs []uint `json:"movie_ids" validate:"required,max=1000,unique,dive,required"`
+}
+
+type ListWatchlist struct {
+	Pagination
+	UserID   uint   `json:"-" validate:"required"`
+	Genre    string `query:"genre"`
+	Released *bool  `query:"released"`
+}
@@ -0,0 +1,19 @@
+package response
+
+type Reconcile struct {
+	DryRun        bool                `json:"dry_run"`
+	Scanned       int                 `json:"scanned"`
+	Mismatched    int                 `json:"mismatched"`
+	Fixed         int                 `json:"fixed"`
+	Discrepancies []RatingDiscrepancy `json:"discrepancies"`
+}
+
+type RatingDiscrepancy struct {
+	MovieID        uint    `json:"movie_id"`
+	StoredRating   float64 `json:"stored_rating"`
+	ActualRating   float64 `json:"actual_rating"`
+	StoredCount    int64   `json:"stored_count"`
+	ActualCount    int64   `json:"actual_count"`
+	StoredWeighted float64 `json:"stored_weighted_rating"`
+	ActualWeighted float64 `json:"actual_weighted_rating"`
+}
@@ -0,0 +1,32 @@
+package response
+
+import "time"
+
+// APIKey never contains the key itself, only Prefix, its first characters.
+type APIKey struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	CreatedByID *uint      `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// CreatedAPIKey carries the key, which is shown only this once.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyIdentity is the user a request authenticated with an API key acts as. Permissions are the
+// key's scopes that the user currently holds.
+type APIKeyIdentity struct {
+	KeyID       uint
+	UserID      uint
+	Username    string
+	Roles       []string
+	Permissions []string
+}
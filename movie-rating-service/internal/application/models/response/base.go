@@ -5,9 +5,16 @@ import (
 )
 
 type SuccessResponse struct {
-	Status  string      `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Status     string      `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -20,6 +27,10 @@ func Success(data interface{}) SuccessResponse {
 	return SuccessResponse{Status: "success", Data: data}
 }
 
+func SuccessWithPagination(data interface{}, pagination Pagination) SuccessResponse {
+	return SuccessResponse{Status: "success", Data: data, Pagination: &pagination}
+}
+
 func Error(message, cause string) ErrorResponse {
 	res := ErrorResponse{Status: "error", Message: message}
 	if config.Cfg.DebugMode {
@@ -5,11 +5,60 @@ type CreateMovie struct {
 }
 
 type GetMovie struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Genre       string  `json:"genre"`
-	Director    string  `json:"director"`
-	Year        int     `json:"year"`
-	Rating      float64 `json:"rating"`
-	RatingCount int64   `json:"rating_count"`
+	ID             uint    `json:"id"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Genre          string  `json:"genre"`
+	Director       string  `json:"director"`
+	Year           int     `json:"year"`
+	Rating         float64 `json:"rating"`
+	RatingCount    int64   `json:"rating_count"`
+	WeightedRating float64 `json:"weighted_rating"`
+	WatchlistCount int64   `json:"watchlist_count"`
+}
+
+type ListMovies struct {
+	Movies     []GetMovie
+	Pagination Pagination
+}
+
+// SearchMovie carries the title (Highlight) and a part of the description (Snippet) as HTML: the text is
+// escaped and only the matches are wrapped in <mark>.
+type SearchMovie struct {
+	GetMovie
+	Rank      float64 `json:"rank"`
+	Highlight string  `json:"highlight"`
+	Snippet   string  `json:"snippet"`
+}
+
+type SearchMovies struct {
+	Movies     []SearchMovie
+	Pagination Pagination
+}
+
+type SuggestMovie struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type MovieStats struct {
+	RatingCount int64             `json:"rating_count"`
+	Average     float64           `json:"average"`
+	Median      float64           `json:"median"`
+	StdDev      float64           `json:"std_dev"`
+	Histogram   []HistogramBucket `json:"histogram"`
+	Timeline    []RatingPeriod    `json:"timeline"`
+}
+
+type HistogramBucket struct {
+	Score float64 `json:"score"`
+	Count int64   `json:"count"`
+}
+
+type RatingPeriod struct {
+	Period            string  `json:"period"`
+	Count             int64   `json:"count"`
+	Average           float64 `json:"average"`
+	CumulativeCount   int64   `json:"cumulative_count"`
+	CumulativeAverage float64 `json:"cumulative_average"`
 }
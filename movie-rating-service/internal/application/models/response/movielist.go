@@ -0,0 +1,34 @@
+package response
+
+import "time"
+
+type MovieList struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Visibility   string    `json:"visibility"`
+	Slug         string    `json:"slug"`
+	Owner        string    `json:"owner"`
+	Editors      []string  `json:"editors,omitempty"`
+	ItemCount    int64     `json:"item_count"`
+	ClonedFromID *uint     `json:"cloned_from_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ListMovieLists struct {
+	Lists      []MovieList
+	Pagination Pagination
+}
+
+type MovieListItem struct {
+	Position int       `json:"position"`
+	Note     string    `json:"note"`
+	AddedAt  time.Time `json:"added_at"`
+	Movie    GetMovie  `json:"movie"`
+}
+
+type ListMovieListItems struct {
+	Items      []MovieListItem
+	Pagination Pagination
+}
@@ -0,0 +1,58 @@
+package response
+
+import "time"
+
+type UserExport struct {
+	ExportedAt       time.Time               `json:"exported_at"`
+	AccountCreatedAt time.Time               `json:"account_created_at"`
+	Profile          GetUser                 `json:"profile"`
+	Ratings          []ExportedRating        `json:"ratings"`
+	HelpfulVotes     []ExportedHelpfulVote   `json:"helpful_votes"`
+	Watchlist        []ExportedWatchlistItem `json:"watchlist"`
+	Lists            []ExportedMovieList     `json:"lists"`
+}
+
+type ExportedRating struct {
+	ID           uint      `json:"id"`
+	MovieID      uint      `json:"movie_id"`
+	MovieTitle   string    `json:"movie_title"`
+	Score        float64   `json:"score"`
+	Review       string    `json:"review"`
+	HelpfulCount int64     `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ExportedHelpfulVote struct {
+	RatingID  uint      `json:"rating_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportedWatchlistItem struct {
+	MovieID    uint      `json:"movie_id"`
+	MovieTitle string    `json:"movie_title"`
+	Position   int       `json:"position"`
+	AddedAt    time.Time `json:"added_at"`
+}
+
+type ExportedMovieList struct {
+	ID          uint                    `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Visibility  string                  `json:"visibility"`
+	CreatedAt   time.Time               `json:"created_at"`
+	Items       []ExportedMovieListItem `json:"items"`
+}
+
+type ExportedMovieListItem struct {
+	MovieID    uint   `json:"movie_id"`
+	MovieTitle string `json:"movie_title"`
+	Position   int    `json:"position"`
+	Note       string `json:"note"`
+}
+
+type Erasure struct {
+	UserID           uint  `json:"user_id"`
+	RatingsErased    int64 `json:"ratings_erased"`
+	MoviesRecomputed int   `json:"movies_recomputed"`
+}
@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type CreateRating struct {
 	ID uint `json:"id"`
 }
@@ -27,3 +29,17 @@ type Rating struct {
 	Score  float64 `json:"score"`
 	Review string  `json:"review"`
 }
+
+type MovieRating struct {
+	ID           uint      `json:"id"`
+	Username     string    `json:"username"`
+	Score        float64   `json:"score"`
+	Review       string    `json:"review"`
+	HelpfulCount int64     `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ListMovieRatings struct {
+	Ratings    []MovieRating
+	Pagination Pagination
+}
@@ -0,0 +1,13 @@
+package response
+
+// TwoFactorEnrollment is a new TOTP secret. URI is the otpauth:// URI to show as a QR code; Secret
+// is the same secret in base32, for typing it in.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodes are shown once; only their hashes are stored.
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
@@ -1,21 +1,45 @@
 package response
 
+import "time"
+
 type CreateUser struct {
 	ID uint `json:"id"`
 }
 
 type GetUser struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Address  string `json:"address"`
-	IsAdmin  bool   `json:"is_admin"`
+	ID                    uint     `json:"id"`
+	Username              string   `json:"username"`
+	Name                  string   `json:"name"`
+	Surname               string   `json:"surname"`
+	Email                 string   `json:"email"`
+	Phone                 string   `json:"phone"`
+	Address               string   `json:"address"`
+	Roles                 []string `json:"roles"`
+	Permissions           []string `json:"permissions"`
+	Status                string   `json:"status"`
+	PasswordResetRequired bool     `json:"password_reset_required"`
+	EmailVerified         bool     `json:"email_verified"`
+	TwoFactorEnabled      bool     `json:"two_factor_enabled"`
+}
+
+type ListUsers struct {
+	Users      []GetUser
+	Pagination Pagination
 }
 
+// Login is a new session. EmailVerificationRequired is set when the token cannot be used to rate
+// until the email is verified, TwoFactorEnrollmentRequired when it grants nothing until the user
+// set up two-factor authentication. For an account with two-factor authentication, the password
+// alone only gets TwoFactorRequired and a ChallengeToken, valid until ExpiresAt, to be exchanged
+// for the session together with a code.
 type Login struct {
-	Username string `json:"username"`
-	Token    string `json:"token"`
+	Username                    string    `json:"username"`
+	Token                       string    `json:"token,omitempty"`
+	ExpiresAt                   time.Time `json:"expires_at"`
+	RefreshToken                string    `json:"refresh_token,omitempty"`
+	PasswordResetRequired       bool      `json:"password_reset_required,omitempty"`
+	EmailVerificationRequired   bool      `json:"email_verification_required,omitempty"`
+	TwoFactorEnrollmentRequired bool      `json:"two_factor_enrollment_required,omitempty"`
+	TwoFactorRequired           bool      `json:"two_factor_required,omitempty"`
+	ChallengeToken              string    `json:"challenge_token,omitempty"`
 }
@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+type WatchlistItem struct {
+	Position int       `json:"position"`
+	AddedAt  time.Time `json:"added_at"`
+	Movie    GetMovie  `json:"movie"`
+}
+
+type ListWatchlist struct {
+	Items      []WatchlistItem
+	Pagination Pagination
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/mail"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/internal/infrastructure/repository"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountService recovers forgotten passwords and verifies email addresses with tokens sent by mail.
+type AccountService interface {
+	// ForgotPassword mails a reset token to every active account using the email. It succeeds whether
+	// or not there is one, so it cannot be used to find out which addresses have accounts.
+	// The mails are sent in the background, so the call takes as long for unknown addresses.
+	ForgotPassword(ctx context.Context, req request.ForgotPassword) error
+	// ResetPassword sets a new password, which must satisfy the password policy, and ends every
+	// session of the user.
+	ResetPassword(ctx context.Context, req request.ResetPassword) error
+	SendVerification(ctx context.Context, req request.SendVerification) error
+	VerifyEmail(ctx context.Context, req request.VerifyEmail) (*response.GetUser, error)
+	// Wait blocks until the mails sent in the background have been handed to the mailer.
+	Wait()
+}
+
+type accountService struct {
+	txManager       db.TxManager
+	userRepository  repository.UserRepository
+	tokenRepository repository.TokenRepository
+	mailer          mail.Mailer
+	hasher          password.Hasher
+	policy          *password.Policy
+	tokens          actionTokens
+	cfg             config.AccountConfig
+	sending         sync.WaitGroup
+}
+
+func NewAccountService(txManager db.TxManager, userRepository repository.UserRepository, tokenRepository repository.TokenRepository, mailer mail.Mailer, hasher password.Hasher, policy *password.Policy, secret string, cfg config.AccountConfig) AccountService {
+	return &accountService{
+		txManager:       txManager,
+		userRepository:  userRepository,
+		tokenRepository: tokenRepository,
+		mailer:          mailer,
+		hasher:          hasher,
+		policy:          policy,
+		tokens:          newActionTokens(secret),
+		cfg:             cfg,
+	}
+}
+
+func (s *accountService) ForgotPassword(ctx context.Context, req request.ForgotPassword) error {
+	users, err := s.userRepository.ListByEmail(ctx, req.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.PasswordResetTTL)
+	messages := make(map[uint]mail.Message)
+	for _, user := range users {
+		if !user.IsActive() {
+			continue
+		}
+		token, err := s.tokens.issue(actionPasswordReset, user.ID, user.Password, expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to issue reset token: %w", err)
+		}
+		messages[user.ID] = mail.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hello %s,\n\nsomeone asked to reset the password of your account. "+
+				"Use this token within %s to choose a new one:\n\n%s\n\n"+
+				"If this was not you, ignore this mail; your password stays unchanged.\n",
+				user.Username, formatTTL(s.cfg.PasswordResetTTL), s.link("/password/reset", token)),
+		}
+	}
+
+	// Delivering the mails in the request would make it slower for addresses with accounts, so they
+	// are sent in the background and a failure is only logged.
+	if len(messages) > 0 {
+		s.sending.Add(1)
+		go func() {
+			defer s.sending.Done()
+			ctx := context.WithoutCancel(ctx)
+			for userID, message := range messages {
+				if err := s.mailer.Send(ctx, message); err != nil {
+					slog.Warn("Password reset mail could not be sent", "user_id", userID, "error", err)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+func (s *accountService) Wait() {
+	s.sending.Wait()
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, req request.ResetPassword) error {
+	payload, err := s.tokens.verify(req.Token, actionPasswordReset, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, payload.UserID)
+		if err != nil {
+			return errInvalidActionToken
+		}
+		// The token is bound to the password hash, so it stops working once the password changed.
+		if !payload.matches(user.Password) || !user.IsActive() {
+			return errInvalidActionToken
+		}
+
+		hash, err := newPasswordHash(s.hasher, s.policy, req.NewPassword)
+		if err != nil {
+			return err
+		}
+		if err = s.userRepository.UpdatePassword(ctx, user.ID, hash); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		return endSessions(ctx, s.userRepository, s.tokenRepository, user.ID, time.Now())
+	})
+}
+
+func (s *accountService) SendVerification(ctx context.Context, req request.SendVerification) error {
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return fmt.Errorf("%w: email is already verified", common.ErrBadRequest)
+	}
+
+	token, err := s.tokens.issue(actionEmailVerification, user.ID, emailVerificationState(user), time.Now().Add(s.cfg.EmailVerificationTTL))
+	if err != nil {
+		return fmt.Errorf("failed to issue verification token: %w", err)
+	}
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nplease confirm that this is your email address with this token within %s:\n\n%s\n",
+			user.Username, formatTTL(s.cfg.EmailVerificationTTL), s.link("/user/verify", token)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification mail: %w", err)
+	}
+	return nil
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, req request.VerifyEmail) (*response.GetUser, error) {
+	payload, err := s.tokens.verify(req.Token, actionEmailVerification, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetByID(ctx, payload.UserID)
+	if err != nil {
+		return nil, errInvalidActionToken
+	}
+	// The token is bound to the unverified address, so it stops working once used or once the email changed.
+	if !payload.matches(emailVerificationState(user)) {
+		return nil, errInvalidActionToken
+	}
+
+	if err = s.userRepository.MarkEmailVerified(ctx, user.ID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	user, err = s.userRepository.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.GetUserResponse(), nil
+}
+
+func (s *accountService) link(path, token string) string {
+	if s.cfg.LinkBaseURL == "" {
+		return token
+	}
+	return strings.TrimSuffix(s.cfg.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func emailVerificationState(user *domain.User) string {
+	return fmt.Sprintf("%s|%t", strings.ToLower(user.Email), user.EmailVerifiedAt != nil)
+}
+
+// formatTTL renders a token lifetime for a mail, e.g. "1 hour" or "30 minutes".
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		if ttl == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", ttl/time.Hour)
+	}
+	minutes := int((ttl + time.Minute - 1) / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
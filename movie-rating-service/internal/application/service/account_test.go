@@ -0,0 +1,303 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/mail"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+type AccountServiceTest struct {
+	suite.Suite
+	service *accountService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	t       *mocks.TokenRepository
+	mailer  *mocks.Mailer
+	sent    []mail.Message
+	user    *domain.User
+}
+
+func (s *AccountServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.t = new(mocks.TokenRepository)
+	s.mailer = new(mocks.Mailer)
+	s.sent = nil
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	s.mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		s.sent = append(s.sent, args.Get(1).(mail.Message))
+	}).Return(nil).Maybe()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.user = &domain.User{Model: gorm.Model{ID: 1}, Username: "alice", Email: "alice@example.com", Password: string(hash)}
+
+	policy, err := password.NewPolicy(testPasswordConfig)
+	s.Require().NoError(err)
+	s.service = NewAccountService(s.tx, s.u, s.t, s.mailer, password.NewHasher(testPasswordConfig), policy, "secret", config.AccountConfig{
+		LinkBaseURL:          "https://movies.example.com/",
+		PasswordResetTTL:     time.Hour,
+		EmailVerificationTTL: 72 * time.Hour,
+	}).(*accountService)
+}
+
+func Test_RunAccountServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountServiceTest))
+}
+
+// token extracts the token from the link in the last mail sent.
+func (s *AccountServiceTest) token() string {
+	s.service.Wait()
+	s.Require().NotEmpty(s.sent)
+	body := s.sent[len(s.sent)-1].Body
+	_, rest, ok := strings.Cut(body, "?token=")
+	s.Require().True(ok)
+	token, _, _ := strings.Cut(rest, "\n")
+	return token
+}
+
+func (s *AccountServiceTest) TestForgotPassword_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	banned := &domain.User{Model: gorm.Model{ID: 2}, Email: "alice@example.com", Status: domain.UserStatusBanned}
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user, *banned}, nil).Once()
+
+	err := s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"})
+	s.service.Wait()
+
+	assert.NoError(t, err)
+	assert.Len(t, s.sent, 1)
+	assert.Equal(t, "alice@example.com", s.sent[0].To)
+	assert.Contains(t, s.sent[0].Body, "Hello alice")
+	assert.Contains(t, s.sent[0].Body, "https://movies.example.com/password/reset?token=")
+	assert.Contains(t, s.sent[0].Body, "within 1 hour")
+}
+
+func (s *AccountServiceTest) TestForgotPassword_Unknown_Email_Succeeds() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("ListByEmail", ctx, "nobody@example.com").Return([]domain.User{}, nil).Once()
+
+	err := s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "nobody@example.com"})
+	s.service.Wait()
+
+	assert.NoError(t, err)
+	assert.Empty(t, s.sent)
+}
+
+func (s *AccountServiceTest) TestForgotPassword_Does_Not_Wait_For_Mail() {
+	t := s.T()
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	delivered := make(chan struct{})
+	s.mailer.ExpectedCalls = nil
+	s.mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		<-delivered
+		// The request is over by now; the mail must still go out.
+		assert.NoError(t, args.Get(0).(context.Context).Err())
+	}).Return(nil).Once()
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user}, nil).Once()
+
+	err := s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"})
+	cancel()
+	close(delivered)
+	s.service.Wait()
+
+	assert.NoError(t, err)
+	s.mailer.AssertExpectations(t)
+}
+
+func (s *AccountServiceTest) TestForgotPassword_Mail_Failure_Succeeds() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.mailer.ExpectedCalls = nil
+	s.mailer.On("Send", mock.Anything, mock.Anything).Return(errors.New("there is an error")).Once()
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user}, nil).Once()
+
+	err := s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"})
+	s.service.Wait()
+
+	assert.NoError(t, err)
+	s.mailer.AssertExpectations(t)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user}, nil).Once()
+	s.Require().NoError(s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"}))
+
+	var hash string
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.u.On("UpdatePassword", ctx, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		hash = args.String(2)
+	}).Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(1), mock.Anything).Return(nil).Once()
+
+	err := s.service.ResetPassword(ctx, request.ResetPassword{Token: s.token(), NewPassword: "new-password"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+	s.u.AssertExpectations(t)
+	s.t.AssertExpectations(t)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Error_Password_Too_Short() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user}, nil).Once()
+	s.Require().NoError(s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"}))
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	err := s.service.ResetPassword(ctx, request.ResetPassword{Token: s.token(), NewPassword: "short"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Error_Token_Already_Used() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("ListByEmail", ctx, "alice@example.com").Return([]domain.User{*s.user}, nil).Once()
+	s.Require().NoError(s.service.ForgotPassword(ctx, request.ForgotPassword{Email: "alice@example.com"}))
+
+	// The password changed since the token was issued.
+	changed := *s.user
+	changed.Password = "another-hash"
+	s.u.On("GetByID", ctx, uint(1)).Return(&changed, nil).Once()
+
+	err := s.service.ResetPassword(ctx, request.ResetPassword{Token: s.token(), NewPassword: "new-password"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Error_Expired() {
+	t := s.T()
+	ctx := context.TODO()
+
+	token, err := s.service.tokens.issue(actionPasswordReset, 1, s.user.Password, time.Now().Add(-time.Second))
+	s.Require().NoError(err)
+
+	err = s.service.ResetPassword(ctx, request.ResetPassword{Token: token, NewPassword: "new-password"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Error_Wrong_Action() {
+	t := s.T()
+	ctx := context.TODO()
+
+	token, err := s.service.tokens.issue(actionEmailVerification, 1, s.user.Password, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+
+	err = s.service.ResetPassword(ctx, request.ResetPassword{Token: token, NewPassword: "new-password"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+}
+
+func (s *AccountServiceTest) TestResetPassword_Error_Tampered() {
+	t := s.T()
+	ctx := context.TODO()
+
+	token, err := s.service.tokens.issue(actionPasswordReset, 1, s.user.Password, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	forged, err := newActionTokens("other-secret").issue(actionPasswordReset, 1, s.user.Password, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(token, ".")
+
+	err = s.service.ResetPassword(ctx, request.ResetPassword{Token: forged, NewPassword: "new-password"})
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	err = s.service.ResetPassword(ctx, request.ResetPassword{Token: payload + "." + signature + "x", NewPassword: "new-password"})
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+}
+
+func (s *AccountServiceTest) TestSendVerification_Error_Already_Verified() {
+	t := s.T()
+	ctx := context.TODO()
+
+	verifiedAt := time.Now()
+	s.user.EmailVerifiedAt = &verifiedAt
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	err := s.service.SendVerification(ctx, request.SendVerification{UserID: 1})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.Empty(t, s.sent)
+}
+
+func (s *AccountServiceTest) TestVerifyEmail_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Twice()
+	s.Require().NoError(s.service.SendVerification(ctx, request.SendVerification{UserID: 1}))
+	assert.Contains(t, s.sent[0].Body, "https://movies.example.com/user/verify?token=")
+	assert.Contains(t, s.sent[0].Body, "within 72 hours")
+
+	verifiedAt := time.Now()
+	verified := *s.user
+	verified.EmailVerifiedAt = &verifiedAt
+	s.u.On("MarkEmailVerified", ctx, uint(1), mock.Anything).Return(nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(&verified, nil).Once()
+
+	res, err := s.service.VerifyEmail(ctx, request.VerifyEmail{Token: s.token()})
+
+	assert.NoError(t, err)
+	assert.True(t, res.EmailVerified)
+	s.u.AssertExpectations(t)
+}
+
+func (s *AccountServiceTest) TestVerifyEmail_Error_Email_Changed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.Require().NoError(s.service.SendVerification(ctx, request.SendVerification{UserID: 1}))
+
+	changed := *s.user
+	changed.Email = "eve@example.com"
+	s.u.On("GetByID", ctx, uint(1)).Return(&changed, nil).Once()
+
+	_, err := s.service.VerifyEmail(ctx, request.VerifyEmail{Token: s.token()})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *AccountServiceTest) TestFormatTTL() {
+	t := s.T()
+
+	assert.Equal(t, "1 hour", formatTTL(time.Hour))
+	assert.Equal(t, "72 hours", formatTTL(72*time.Hour))
+	assert.Equal(t, "90 minutes", formatTTL(90*time.Minute))
+	assert.Equal(t, "1 minute", formatTTL(30*time.Second))
+}
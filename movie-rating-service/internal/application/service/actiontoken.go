@@ -0,0 +1,96 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"movie-rating-service/internal/common"
+	"strings"
+	"time"
+)
+
+const (
+	actionPasswordReset     = "password_reset"
+	actionEmailVerification = "email_verification"
+	actionLoginChallenge    = "login_challenge"
+)
+
+var errInvalidActionToken = fmt.Errorf("%w: invalid or expired token", common.ErrBadRequest)
+
+// actionTokens signs the single-use tokens mailed to users. Besides the user and an expiry, a token
+// carries a fingerprint of the account state it acts on, e.g. the password hash for a reset. Using
+// the token changes that state, so the token stops matching; nothing needs to be stored.
+type actionTokens struct {
+	key []byte
+}
+
+type actionTokenPayload struct {
+	Action    string `json:"a"`
+	UserID    uint   `json:"u"`
+	ExpiresAt int64  `json:"e"`
+	State     string `json:"s"`
+}
+
+func newActionTokens(secret string) actionTokens {
+	// A separate key, so that these tokens are never interchangeable with anything else derived from the secret.
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("action-tokens"))
+	return actionTokens{key: mac.Sum(nil)}
+}
+
+func (t actionTokens) issue(action string, userID uint, state string, expiresAt time.Time) (string, error) {
+	payload, err := json.Marshal(actionTokenPayload{
+		Action:    action,
+		UserID:    userID,
+		ExpiresAt: expiresAt.Unix(),
+		State:     fingerprint(state),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.sign(encoded)), nil
+}
+
+// verify returns the user a valid token of the action was issued for. The caller still has to check
+// the token against the user's current state with matches.
+func (t actionTokens) verify(token, action string, now time.Time) (*actionTokenPayload, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidActionToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, t.sign(encoded)) {
+		return nil, errInvalidActionToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidActionToken
+	}
+	var payload actionTokenPayload
+	if err = json.Unmarshal(data, &payload); err != nil {
+		return nil, errInvalidActionToken
+	}
+	if payload.Action != action || now.Unix() >= payload.ExpiresAt {
+		return nil, errInvalidActionToken
+	}
+	return &payload, nil
+}
+
+func (p *actionTokenPayload) matches(state string) bool {
+	return hmac.Equal([]byte(p.State), []byte(fingerprint(state)))
+}
+
+func (t actionTokens) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func fingerprint(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks the service's keys, so that a leaked one is recognized as such, e.g. by
+	// secret scanners.
+	apiKeyPrefix = "mrs_"
+	// apiKeyPrefixLength is how much of a key is stored in clear to tell keys apart.
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval is how often at most last_used_at is updated for a key.
+	apiKeyTouchInterval = time.Minute
+)
+
+var errInvalidAPIKey = fmt.Errorf("%w: invalid API key", common.ErrUnauthorized)
+
+// APIKeyService manages long-lived keys for scripts and other services. A key acts as its user but
+// grants only its scopes, and of those only what a new session of the user would: taking a role
+// away from the user, or disabling the account, applies to their keys at once.
+type APIKeyService interface {
+	// Create returns the new key, which cannot be retrieved again.
+	Create(ctx context.Context, req request.CreateAPIKey) (*response.CreatedAPIKey, error)
+	List(ctx context.Context, req request.ListAPIKeys) ([]response.APIKey, error)
+	Revoke(ctx context.Context, req request.RevokeAPIKey) error
+	// Authenticate resolves a key to the user it acts as. Unknown, expired and revoked keys, and keys
+	// of accounts that are not active, are ErrUnauthorized.
+	Authenticate(ctx context.Context, key string) (*response.APIKeyIdentity, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepository     repository.APIKeyRepository
+	userRepository       repository.UserRepository
+	cfg                  config.APIKeyConfig
+	requireVerifiedEmail bool
+	twoFactorRoles       []string
+}
+
+// NewAPIKeyService creates the API key service. requireVerifiedEmail and twoFactorRoles restrict
+// keys as NewTokenService restricts sessions.
+func NewAPIKeyService(apiKeyRepository repository.APIKeyRepository, userRepository repository.UserRepository, cfg config.APIKeyConfig, requireVerifiedEmail bool, twoFactorRoles []string) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepository:     apiKeyRepository,
+		userRepository:       userRepository,
+		cfg:                  cfg,
+		requireVerifiedEmail: requireVerifiedEmail,
+		twoFactorRoles:       twoFactorRoles,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, req request.CreateAPIKey) (*response.CreatedAPIKey, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.DefaultTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", common.ErrBadRequest)
+	}
+	if expiresAt.After(now.Add(s.cfg.MaxTTL)) {
+		return nil, fmt.Errorf("%w: API keys can be valid for at most %s", common.ErrBadRequest, formatTTL(s.cfg.MaxTTL))
+	}
+
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	// A key acts as its user, so a user manager cannot create one for a user who holds a permission
+	// they do not, such as an admin, or they would act as someone above them.
+	permissions := user.PermissionNames()
+	if req.GrantorID != req.UserID {
+		for _, permission := range permissions {
+			if !slices.Contains(req.GrantorPermissions, permission) {
+				return nil, fmt.Errorf("%w: the user has the permission %q, which you do not", common.ErrForbidden, permission)
+			}
+		}
+	}
+	// A key cannot be given a permission its user does not hold, even though it would never grant it.
+	// Nor one its grantor does not hold, or a user manager could hand out any permission of a service
+	// account without having it.
+	scopes := slices.Compact(slices.Sorted(slices.Values(req.Scopes)))
+	for _, scope := range scopes {
+		if !slices.Contains(permissions, scope) {
+			return nil, fmt.Errorf("%w: the user does not have the permission %q", common.ErrBadRequest, scope)
+		}
+		if !slices.Contains(req.GrantorPermissions, scope) {
+			return nil, fmt.Errorf("%w: you do not have the permission %q", common.ErrForbidden, scope)
+		}
+	}
+
+	secret, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := apiKeyPrefix + secret
+	created, err := s.apiKeyRepository.Create(ctx, domain.APIKey{
+		UserID:      req.UserID,
+		CreatedByID: &req.GrantorID,
+		Name:        req.Name,
+		Prefix:      key[:apiKeyPrefixLength],
+		KeyHash:     hashToken(key),
+		Scopes:      strings.Join(scopes, " "),
+		ExpiresAt:   expiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return &response.CreatedAPIKey{APIKey: created.GetAPIKeyResponse(), Key: key}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, req request.ListAPIKeys) ([]response.APIKey, error) {
+	keys, err := s.apiKeyRepository.ListByUser(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	resp := make([]response.APIKey, len(keys))
+	for i, key := range keys {
+		resp[i] = key.GetAPIKeyResponse()
+	}
+	return resp, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, req request.RevokeAPIKey) error {
+	if err := s.apiKeyRepository.Revoke(ctx, req.UserID, req.KeyID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (*response.APIKeyIdentity, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, errInvalidAPIKey
+	}
+	apiKey, err := s.apiKeyRepository.GetByHash(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil || !now.Before(apiKey.ExpiresAt) {
+		return nil, errInvalidAPIKey
+	}
+
+	// Deleted accounts are not found.
+	user, err := s.userRepository.GetByID(ctx, apiKey.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.IsActive() {
+		return nil, errInvalidAPIKey
+	}
+
+	if err = s.apiKeyRepository.Touch(ctx, apiKey.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		return nil, fmt.Errorf("failed to update api key last use: %w", err)
+	}
+
+	scopes := apiKey.ScopeList()
+	permissions, _, _ := sessionPermissions(*user.GetUserResponse(), s.requireVerifiedEmail, s.twoFactorRoles)
+	permissions = slices.DeleteFunc(slices.Clone(permissions), func(permission string) bool {
+		return !slices.Contains(scopes, permission)
+	})
+	return &response.APIKeyIdentity{
+		KeyID:       apiKey.ID,
+		UserID:      user.ID,
+		Username:    user.Username,
+		Roles:       user.RoleNames(),
+		Permissions: permissions,
+	}, nil
+}
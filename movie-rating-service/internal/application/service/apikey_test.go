@@ -0,0 +1,204 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+type APIKeyServiceTest struct {
+	suite.Suite
+	service *apiKeyService
+	k       *mocks.APIKeyRepository
+	u       *mocks.UserRepository
+	user    *domain.User
+}
+
+func (s *APIKeyServiceTest) SetupTest() {
+	s.k = new(mocks.APIKeyRepository)
+	s.u = new(mocks.UserRepository)
+
+	s.service = NewAPIKeyService(s.k, s.u, config.APIKeyConfig{DefaultTTL: 24 * time.Hour, MaxTTL: 48 * time.Hour}, false, nil).(*apiKeyService)
+
+	s.user = &domain.User{Model: gorm.Model{ID: 1}, Username: "ingest", Roles: []domain.Role{{
+		Name:        domain.RoleCurator,
+		Permissions: []domain.Permission{{Name: domain.PermissionMovieWrite}, {Name: domain.PermissionReviewWrite}},
+	}}}
+}
+
+func Test_RunAPIKeyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyServiceTest))
+}
+
+func (s *APIKeyServiceTest) TestCreate_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var stored domain.APIKey
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.k.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(domain.APIKey)
+	}).Return(func(ctx context.Context, key domain.APIKey) (*domain.APIKey, error) {
+		key.ID = 7
+		return &key, nil
+	}).Once()
+
+	res, err := s.service.Create(ctx, request.CreateAPIKey{UserID: 1, Name: "ingestion", Scopes: []string{"movie:write", "movie:write"}, GrantorID: 2, GrantorPermissions: []string{"movie:write", "review:write", "user:manage"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), res.ID)
+	assert.True(t, strings.HasPrefix(res.Key, "mrs_"))
+	assert.Equal(t, res.Key[:12], res.Prefix)
+	assert.Equal(t, []string{"movie:write"}, res.Scopes)
+	assert.Equal(t, hashToken(res.Key), stored.KeyHash)
+	assert.Equal(t, uint(2), *stored.CreatedByID)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func (s *APIKeyServiceTest) TestCreate_Error_Scope_Not_Held() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Create(ctx, request.CreateAPIKey{UserID: 1, Name: "ingestion", Scopes: []string{"movie:write", "user:manage"}, GrantorID: 1, GrantorPermissions: []string{"movie:write", "review:write", "user:manage"}})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.k.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *APIKeyServiceTest) TestCreate_Error_Scope_Not_Held_By_Grantor() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The user's session lacks review:write until their email is verified.
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Create(ctx, request.CreateAPIKey{UserID: 1, Name: "ingestion", Scopes: []string{"review:write"}, GrantorID: 1, GrantorPermissions: []string{"movie:write"}})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.k.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *APIKeyServiceTest) TestCreate_Error_User_Outranks_Grantor() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// A user manager without review:write creates a key for a curator, scoped to what they share.
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Create(ctx, request.CreateAPIKey{UserID: 1, Name: "ingestion", Scopes: []string{"movie:write"}, GrantorID: 2, GrantorPermissions: []string{"movie:write", "user:manage"}})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.k.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *APIKeyServiceTest) TestCreate_Error_Expiry_Too_Late() {
+	t := s.T()
+	ctx := context.TODO()
+
+	expiresAt := time.Now().Add(72 * time.Hour)
+	_, err := s.service.Create(ctx, request.CreateAPIKey{UserID: 1, Name: "ingestion", Scopes: []string{"movie:write"}, ExpiresAt: &expiresAt})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.k.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The user has lost rating:reconcile since the key was created.
+	key := &domain.APIKey{ID: 7, UserID: 1, Scopes: "movie:write rating:reconcile", ExpiresAt: time.Now().Add(time.Hour)}
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(key, nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.k.On("Touch", ctx, uint(7), mock.Anything, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), res.KeyID)
+	assert.Equal(t, uint(1), res.UserID)
+	assert.Equal(t, []string{"movie:write"}, res.Permissions)
+	s.k.AssertExpectations(t)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Password_Reset_Required_Grants_Nothing() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.PasswordResetRequired = true
+	key := &domain.APIKey{ID: 7, UserID: 1, Scopes: "movie:write", ExpiresAt: time.Now().Add(time.Hour)}
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(key, nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.k.On("Touch", ctx, uint(7), mock.Anything, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.NoError(t, err)
+	assert.Empty(t, res.Permissions)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Error_Expired() {
+	t := s.T()
+	ctx := context.TODO()
+
+	key := &domain.APIKey{ID: 7, UserID: 1, Scopes: "movie:write", ExpiresAt: time.Now().Add(-time.Second)}
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(key, nil).Once()
+
+	_, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.u.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Error_Revoked() {
+	t := s.T()
+	ctx := context.TODO()
+
+	revokedAt := time.Now().Add(-time.Minute)
+	key := &domain.APIKey{ID: 7, UserID: 1, Scopes: "movie:write", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(key, nil).Once()
+
+	_, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Error_Unknown_Key() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+}
+
+func (s *APIKeyServiceTest) TestAuthenticate_Error_Account_Banned() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.Status = domain.UserStatusBanned
+	key := &domain.APIKey{ID: 7, UserID: 1, Scopes: "movie:write", ExpiresAt: time.Now().Add(time.Hour)}
+	s.k.On("GetByHash", ctx, hashToken("mrs_key")).Return(key, nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Authenticate(ctx, "mrs_key")
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.k.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"log/slog"
+	"math/big"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/repository"
+	"sync"
+	"time"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits = 2048
+	// keyPublishAhead is how long before it starts signing a successor key is already published, so
+	// verifiers that cache the JWKS know it before the first token signed with it arrives.
+	keyPublishAhead = time.Hour
+)
+
+var errNoSigningKey = errors.New("no active signing key")
+
+// Signer signs access tokens with the currently active key.
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+// KeyRing holds the asymmetric keys access tokens are signed and verified with. Keys are stored in
+// the database, shared by all replicas, and rotated on a schedule: a key signs for one rotation
+// period and stays valid for verification until the tokens it signed have expired.
+type KeyRing interface {
+	Signer
+	// Keyfunc resolves the public key for a token by its kid header.
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	// Methods lists the signing algorithms tokens may use.
+	Methods() []string
+	JWKS() response.JWKS
+	// Refresh reloads the keys and creates the next one when the active key is due for rotation.
+	Refresh(ctx context.Context) error
+	// Run calls Refresh every interval until ctx is cancelled.
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type keyRing struct {
+	txManager            db.TxManager
+	signingKeyRepository repository.SigningKeyRepository
+	algorithm            string
+	rotation             time.Duration
+	tokenTTL             time.Duration
+	aead                 cipher.AEAD
+
+	mu   sync.RWMutex
+	keys []ringKey
+}
+
+type ringKey struct {
+	kid         string
+	method      jwt.SigningMethod
+	private     crypto.Signer // nil if the key cannot be decrypted, e.g. after the secret changed
+	public      crypto.PublicKey
+	activatesAt time.Time
+	retiresAt   time.Time
+	expiresAt   time.Time
+}
+
+// NewKeyRing creates a key ring signing with algorithm (RS256 or EdDSA). Private keys are encrypted
+// at rest with a key derived from secret. Tokens live for tokenTTL, which bounds how long a retired
+// key is still published.
+func NewKeyRing(txManager db.TxManager, signingKeyRepository repository.SigningKeyRepository, algorithm string, rotation, tokenTTL time.Duration, secret string) (KeyRing, error) {
+	if _, err := signingMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key cipher: %w", err)
+	}
+
+	return &keyRing{
+		txManager:            txManager,
+		signingKeyRepository: signingKeyRepository,
+		algorithm:            algorithm,
+		rotation:             rotation,
+		tokenTTL:             tokenTTL,
+		aead:                 aead,
+	}, nil
+}
+
+func (k *keyRing) Sign(claims jwt.Claims) (string, error) {
+	key := k.active(time.Now())
+	if key == nil {
+		return "", errNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+func (k *keyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range k.keys {
+		if key.kid != kid || !key.expiresAt.After(now) {
+			continue
+		}
+		// The algorithm is bound to the key, never taken from the token alone.
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+		}
+		return key.public, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (k *keyRing) Methods() []string {
+	return []string{AlgorithmRS256, AlgorithmEdDSA}
+}
+
+func (k *keyRing) JWKS() response.JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	now := time.Now()
+	jwks := response.JWKS{Keys: make([]response.JWK, 0, len(k.keys))}
+	for _, key := range k.keys {
+		if !key.expiresAt.After(now) {
+			continue
+		}
+		jwk := response.JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (k *keyRing) Refresh(ctx context.Context) error {
+	var keys []ringKey
+	err := k.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Replicas refresh concurrently; the lock makes sure only one of them creates the next key.
+		if err := k.signingKeyRepository.Lock(ctx); err != nil {
+			return fmt.Errorf("failed to lock signing keys: %w", err)
+		}
+
+		now := time.Now()
+		if err := k.signingKeyRepository.DeleteExpired(ctx, now); err != nil {
+			return fmt.Errorf("failed to delete expired signing keys: %w", err)
+		}
+		stored, err := k.signingKeyRepository.ListUnexpired(ctx, now)
+		if err != nil {
+			return fmt.Errorf("failed to get signing keys: %w", err)
+		}
+
+		keys = make([]ringKey, 0, len(stored)+2)
+		for _, s := range stored {
+			key, err := k.decode(s)
+			if err != nil {
+				return err
+			}
+			// A key that signs with another algorithm than configured, or whose private key cannot be
+			// decrypted, is retired at once but still verifies the tokens it already signed.
+			if (key.method.Alg() != k.algorithm || key.private == nil) && key.retiresAt.After(now) {
+				if err = k.signingKeyRepository.Retire(ctx, key.kid, now); err != nil {
+					return fmt.Errorf("failed to retire signing key: %w", err)
+				}
+				key.retiresAt = now
+			}
+			keys = append(keys, key)
+		}
+
+		current := activeKey(keys, now)
+		if current == nil {
+			key, err := k.create(ctx, now)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			current = &keys[len(keys)-1]
+		}
+
+		if current.retiresAt.Sub(now) <= min(keyPublishAhead, k.rotation/2) && !hasSuccessor(keys, now) {
+			key, err := k.create(ctx, current.retiresAt)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *keyRing) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := k.Refresh(ctx); err != nil {
+				slog.Error("Signing keys could not be refreshed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (k *keyRing) active(at time.Time) *ringKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return activeKey(k.keys, at)
+}
+
+// create generates and stores a key that signs from activatesAt for one rotation period.
+func (k *keyRing) create(ctx context.Context, activatesAt time.Time) (ringKey, error) {
+	private, err := generateKey(k.algorithm)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	key := ringKey{
+		kid:         uuid.NewString(),
+		private:     private,
+		public:      private.Public(),
+		activatesAt: activatesAt,
+		retiresAt:   activatesAt.Add(k.rotation),
+	}
+	key.method, _ = signingMethod(k.algorithm)
+	key.expiresAt = key.retiresAt.Add(k.tokenTTL)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(key.public)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	encrypted, err := k.encrypt(key.kid, privateDER)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	err = k.signingKeyRepository.Create(ctx, domain.SigningKey{
+		KID:         key.kid,
+		Algorithm:   k.algorithm,
+		PrivateKey:  encrypted,
+		PublicKey:   publicDER,
+		ActivatesAt: key.activatesAt,
+		RetiresAt:   key.retiresAt,
+		ExpiresAt:   key.expiresAt,
+	})
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	slog.Info("Signing key created", "kid", key.kid, "algorithm", k.algorithm, "activates_at", key.activatesAt)
+	return key, nil
+}
+
+func (k *keyRing) decode(stored domain.SigningKey) (ringKey, error) {
+	method, err := signingMethod(stored.Algorithm)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to decode signing key %s: %w", stored.KID, err)
+	}
+	public, err := x509.ParsePKIXPublicKey(stored.PublicKey)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to decode signing key %s: %w", stored.KID, err)
+	}
+
+	key := ringKey{
+		kid:         stored.KID,
+		method:      method,
+		public:      public,
+		activatesAt: stored.ActivatesAt,
+		retiresAt:   stored.RetiresAt,
+		expiresAt:   stored.ExpiresAt,
+	}
+
+	privateDER, err := k.decrypt(stored.KID, stored.PrivateKey)
+	if err != nil {
+		slog.Warn("Signing key could not be decrypted, it is only used for verification", "kid", stored.KID)
+		return key, nil
+	}
+	private, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return ringKey{}, fmt.Errorf("failed to decode signing key %s: %w", stored.KID, err)
+	}
+	key.private, _ = private.(crypto.Signer)
+	return key, nil
+}
+
+// encrypt seals a private key with AES-GCM; the kid is authenticated so keys cannot be swapped.
+func (k *keyRing) encrypt(kid string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, []byte(kid)), nil
+}
+
+func (k *keyRing) decrypt(kid string, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < k.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:k.aead.NonceSize()], ciphertext[k.aead.NonceSize():]
+	return k.aead.Open(nil, nonce, sealed, []byte(kid))
+}
+
+// activeKey returns the most recently activated key that may sign at the given time.
+func activeKey(keys []ringKey, at time.Time) *ringKey {
+	var active *ringKey
+	for i := range keys {
+		key := &keys[i]
+		if key.private == nil || key.activatesAt.After(at) || !key.retiresAt.After(at) {
+			continue
+		}
+		if active == nil || key.activatesAt.After(active.activatesAt) {
+			active = key
+		}
+	}
+	return active
+}
+
+func hasSuccessor(keys []ringKey, now time.Time) bool {
+	for _, key := range keys {
+		if key.private != nil && key.activatesAt.After(now) && key.retiresAt.After(key.activatesAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+}
+
+func generateKey(algorithm string) (crypto.Signer, error) {
+	if algorithm == AlgorithmRS256 {
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	}
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	return private, err
+}
@@ -0,0 +1,232 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/mocks"
+	"testing"
+	"time"
+)
+
+type KeyRingServiceTest struct {
+	suite.Suite
+	tx      *mocks.TxManager
+	k       *mocks.SigningKeyRepository
+	created []domain.SigningKey
+}
+
+func (s *KeyRingServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.k = new(mocks.SigningKeyRepository)
+	s.created = nil
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	s.k.On("Lock", mock.Anything).Return(nil).Maybe()
+	s.k.On("DeleteExpired", mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.k.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		s.created = append(s.created, args.Get(1).(domain.SigningKey))
+	}).Return(nil).Maybe()
+}
+
+func Test_RunKeyRingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(KeyRingServiceTest))
+}
+
+func (s *KeyRingServiceTest) newKeyRing(algorithm, secret string) *keyRing {
+	ring, err := NewKeyRing(s.tx, s.k, algorithm, time.Hour*24, time.Minute*15, secret)
+	s.Require().NoError(err)
+	return ring.(*keyRing)
+}
+
+func (s *KeyRingServiceTest) parse(ring KeyRing, token string) (*jwt.Token, error) {
+	return jwt.Parse(token, ring.Keyfunc, jwt.WithValidMethods(ring.Methods()))
+}
+
+func (s *KeyRingServiceTest) TestRefresh_Creates_First_Key() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	ring := s.newKeyRing(AlgorithmEdDSA, "secret")
+
+	err := ring.Refresh(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.created, 1)
+	assert.Equal(t, AlgorithmEdDSA, s.created[0].Algorithm)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), s.created[0].RetiresAt, time.Second)
+	assert.Equal(t, s.created[0].RetiresAt.Add(15*time.Minute), s.created[0].ExpiresAt)
+
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+	token, err := s.parse(ring, signed)
+	assert.NoError(t, err)
+	assert.Equal(t, s.created[0].KID, token.Header["kid"])
+
+	jwks := ring.JWKS()
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	assert.Equal(t, s.created[0].KID, jwks.Keys[0].Kid)
+	assert.NotEmpty(t, jwks.Keys[0].X)
+}
+
+func (s *KeyRingServiceTest) TestRefresh_RS256() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	ring := s.newKeyRing(AlgorithmRS256, "secret")
+
+	err := ring.Refresh(ctx)
+	assert.NoError(t, err)
+
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+	token, err := s.parse(ring, signed)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmRS256, token.Method.Alg())
+
+	jwks := ring.JWKS()
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+}
+
+func (s *KeyRingServiceTest) TestRefresh_Publishes_Successor_Before_Rotation() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	first := s.newKeyRing(AlgorithmEdDSA, "secret")
+	assert.NoError(t, first.Refresh(ctx))
+
+	// The stored key is about to retire.
+	stored := s.created[0]
+	stored.ActivatesAt = time.Now().Add(-24 * time.Hour)
+	stored.RetiresAt = time.Now().Add(30 * time.Minute)
+	stored.ExpiresAt = stored.RetiresAt.Add(15 * time.Minute)
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{stored}, nil).Once()
+
+	ring := s.newKeyRing(AlgorithmEdDSA, "secret")
+	err := ring.Refresh(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.created, 2)
+	assert.Equal(t, stored.RetiresAt, s.created[1].ActivatesAt)
+	assert.Len(t, ring.JWKS().Keys, 2)
+
+	// The current key keeps signing until it retires.
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+	token, err := s.parse(ring, signed)
+	assert.NoError(t, err)
+	assert.Equal(t, stored.KID, token.Header["kid"])
+}
+
+func (s *KeyRingServiceTest) TestRefresh_Retires_Key_Of_Other_Algorithm() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	old := s.newKeyRing(AlgorithmEdDSA, "secret")
+	assert.NoError(t, old.Refresh(ctx))
+	oldToken, err := old.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{s.created[0]}, nil).Once()
+	s.k.On("Retire", mock.Anything, s.created[0].KID, mock.Anything).Return(nil).Once()
+
+	ring := s.newKeyRing(AlgorithmRS256, "secret")
+	err = ring.Refresh(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.created, 2)
+	assert.Equal(t, AlgorithmRS256, s.created[1].Algorithm)
+
+	// Tokens signed with the retired key stay valid until they expire.
+	_, err = s.parse(ring, oldToken)
+	assert.NoError(t, err)
+
+	signed, err := ring.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+	token, err := s.parse(ring, signed)
+	assert.NoError(t, err)
+	assert.Equal(t, s.created[1].KID, token.Header["kid"])
+	s.k.AssertExpectations(t)
+}
+
+func (s *KeyRingServiceTest) TestRefresh_Retires_Undecryptable_Key() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	old := s.newKeyRing(AlgorithmEdDSA, "old-secret")
+	assert.NoError(t, old.Refresh(ctx))
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{s.created[0]}, nil).Once()
+	s.k.On("Retire", mock.Anything, s.created[0].KID, mock.Anything).Return(nil).Once()
+
+	ring := s.newKeyRing(AlgorithmEdDSA, "new-secret")
+	err := ring.Refresh(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, s.created, 2)
+	assert.Len(t, ring.JWKS().Keys, 2)
+	s.k.AssertExpectations(t)
+}
+
+func (s *KeyRingServiceTest) TestKeyfunc_Error_Algorithm_Mismatch() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	ring := s.newKeyRing(AlgorithmEdDSA, "secret")
+	assert.NoError(t, ring.Refresh(ctx))
+
+	// An HMAC token naming a real kid must not be verified with that key's public bytes.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1})
+	forged.Header["kid"] = s.created[0].KID
+	signed, err := forged.SignedString(s.created[0].PublicKey)
+	assert.NoError(t, err)
+
+	_, err = s.parse(ring, signed)
+	assert.Error(t, err)
+}
+
+func (s *KeyRingServiceTest) TestKeyfunc_Error_Unknown_Kid() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	ring := s.newKeyRing(AlgorithmEdDSA, "secret")
+	assert.NoError(t, ring.Refresh(ctx))
+
+	s.k.On("ListUnexpired", mock.Anything, mock.Anything).Return([]domain.SigningKey{}, nil).Once()
+	other := s.newKeyRing(AlgorithmEdDSA, "secret")
+	assert.NoError(t, other.Refresh(ctx))
+	signed, err := other.Sign(jwt.MapClaims{"user_id": 1})
+	assert.NoError(t, err)
+
+	_, err = s.parse(ring, signed)
+	assert.ErrorContains(t, err, "unknown signing key")
+}
+
+func (s *KeyRingServiceTest) TestSign_Error_No_Key() {
+	t := s.T()
+
+	ring := s.newKeyRing(AlgorithmEdDSA, "secret")
+
+	_, err := ring.Sign(jwt.MapClaims{"user_id": 1})
+
+	assert.ErrorIs(t, err, errNoSigningKey)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	loginFailureUnknownUser   = "unknown_user"
+	loginFailureWrongPassword = "wrong_password"
+	loginFailureWrongCode     = "wrong_code"
+	loginFailureLocked        = "locked"
+)
+
+var loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "login_failures_total",
+	Help: "Failed logins, by reason (unknown_user, wrong_password, wrong_code, locked).",
+}, []string{"reason"})
+
+var errInvalidCredentials = fmt.Errorf("%w: invalid credentials", common.ErrUnauthorized)
+
+// LoginLockedError refuses a login while its username or client address is locked out.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s: too many failed logins, retry in %d seconds", common.ErrTooManyRequests, e.RetryAfterSeconds())
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return common.ErrTooManyRequests
+}
+
+// RetryAfterSeconds is the wait for a Retry-After header, rounded up.
+func (e *LoginLockedError) RetryAfterSeconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// loginThrottle counts logins per username and per client address. An attempt is counted before
+// its credentials are checked, so concurrent attempts cannot outrun the limit, and taken back if
+// they were right. Unknown usernames are counted like known ones, so a lockout does not tell
+// whether an account exists.
+type loginThrottle struct {
+	repository repository.LoginAttemptRepository
+	cfg        config.LoginConfig
+}
+
+// loginAttempt is an attempt counted against one key, with the count including it.
+type loginAttempt struct {
+	key   domain.LoginAttemptKey
+	count int
+}
+
+// begin counts the attempt against the username and the client address, or refuses it while one
+// of them is locked out.
+func (t loginThrottle) begin(ctx context.Context, username, ip string, now time.Time) ([]loginAttempt, error) {
+	var attempts []loginAttempt
+	var lockedUntil *time.Time
+	for _, key := range t.keys(username, ip) {
+		count, until, err := t.repository.Acquire(ctx, key, now, t.cfg.Window, t.maxFailures(key), t.lockouts())
+		if err != nil {
+			return nil, fmt.Errorf("failed to record login attempt: %w", err)
+		}
+		if until == nil {
+			attempts = append(attempts, loginAttempt{key: key, count: count})
+		} else if lockedUntil == nil || until.After(*lockedUntil) {
+			lockedUntil = until
+		}
+	}
+	if lockedUntil != nil {
+		// A refused attempt does not count against the other key either.
+		if err := t.release(ctx, attempts); err != nil {
+			return nil, err
+		}
+		loginFailures.WithLabelValues(loginFailureLocked).Inc()
+		return nil, &LoginLockedError{RetryAfter: lockedUntil.Sub(now)}
+	}
+	return attempts, nil
+}
+
+// fail returns the error to answer a failed login with. The attempt stays counted.
+func (t loginThrottle) fail(reason string) error {
+	loginFailures.WithLabelValues(reason).Inc()
+	return errInvalidCredentials
+}
+
+// release takes back attempts whose credentials were right, keeping earlier failures.
+func (t loginThrottle) release(ctx context.Context, attempts []loginAttempt) error {
+	for _, attempt := range attempts {
+		if err := t.repository.Release(ctx, attempt.key, attempt.count >= t.maxFailures(attempt.key)); err != nil {
+			return fmt.Errorf("failed to release login attempt: %w", err)
+		}
+	}
+	return nil
+}
+
+// succeed forgets the failures of the username. Those of the client address stay, otherwise an
+// attacker could clear them by logging in to an account of their own now and then.
+func (t loginThrottle) succeed(ctx context.Context, attempts []loginAttempt, now time.Time) error {
+	for _, attempt := range attempts {
+		if attempt.key.Scope == domain.LoginAttemptScopeIP {
+			if err := t.release(ctx, []loginAttempt{attempt}); err != nil {
+				return err
+			}
+		} else if err := t.repository.Reset(ctx, attempt.key); err != nil {
+			return fmt.Errorf("failed to reset login attempts: %w", err)
+		}
+	}
+	// Rows of unknown usernames are never reset, only expire.
+	if err := t.repository.DeleteExpired(ctx, now); err != nil {
+		return fmt.Errorf("failed to delete expired login attempts: %w", err)
+	}
+	return nil
+}
+
+func (t loginThrottle) maxFailures(key domain.LoginAttemptKey) int {
+	if key.Scope == domain.LoginAttemptScopeIP {
+		return t.cfg.MaxIPFailures
+	}
+	return t.cfg.MaxFailures
+}
+
+// lockout doubles the base lockout for every failure beyond the limit, up to the maximum.
+func (t loginThrottle) lockout(excess int) time.Duration {
+	lockout := t.cfg.Lockout
+	for ; excess > 0 && lockout < t.cfg.MaxLockout; excess-- {
+		lockout *= 2
+	}
+	return min(lockout, t.cfg.MaxLockout)
+}
+
+// lockouts lists the lockout by failures beyond the limit, up to the first one at the maximum.
+func (t loginThrottle) lockouts() []time.Duration {
+	lockouts := []time.Duration{t.lockout(0)}
+	for lockouts[len(lockouts)-1] < t.cfg.MaxLockout {
+		lockouts = append(lockouts, t.lockout(len(lockouts)))
+	}
+	return lockouts
+}
+
+func (t loginThrottle) usernameKey(username string) domain.LoginAttemptKey {
+	return domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeUsername, Key: strings.ToLower(username)}
+}
+
+func (t loginThrottle) keys(username, ip string) []domain.LoginAttemptKey {
+	keys := []domain.LoginAttemptKey{t.usernameKey(username)}
+	if ip != "" {
+		keys = append(keys, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: clientAddressKey(ip)})
+	}
+	return keys
+}
+
+// clientAddressKey counts an IPv6 client by its /64 network, which a single client usually owns
+// entirely, so that it cannot dodge the limit by switching addresses.
+func clientAddressKey(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		return addr.String()
+	}
+	prefix, err := addr.Prefix(64)
+	if err != nil {
+		return addr.String()
+	}
+	return prefix.String()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"gorm.io/gorm"
+	"math"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
 	"movie-rating-service/internal/domain"
@@ -15,15 +16,32 @@ type MovieService interface {
 	Update(ctx context.Context, req request.UpdateMovie) error
 	Delete(ctx context.Context, req request.DeleteMovie) error
 	Get(ctx context.Context, req request.GetMovie) (*response.GetMovie, error)
+	List(ctx context.Context, req request.ListMovies) (*response.ListMovies, error)
+	Search(ctx context.Context, req request.SearchMovies) (*response.SearchMovies, error)
+	Suggest(ctx context.Context, req request.SuggestMovies) ([]response.SuggestMovie, error)
+	GetStats(ctx context.Context, req request.GetMovieStats) (*response.MovieStats, error)
+	Top(ctx context.Context, req request.TopMovies) ([]response.GetMovie, error)
 }
 
 type movieService struct {
-	movieRepository repository.MovieRepository
+	movieRepository       repository.MovieRepository
+	movieSearchRepository repository.MovieSearchRepository
+	movieStatsRepository  repository.MovieStatsRepository
 }
 
-func NewMovieService(movieRepository repository.MovieRepository) MovieService {
+const (
+	defaultSuggestLimit = 10
+	defaultTopLimit     = 10
+)
+
+// maxRatingBucket is the histogram bucket of a 5.0 score; buckets are half stars.
+const maxRatingBucket = 10
+
+func NewMovieService(movieRepository repository.MovieRepository, movieSearchRepository repository.MovieSearchRepository, movieStatsRepository repository.MovieStatsRepository) MovieService {
 	return &movieService{
-		movieRepository: movieRepository,
+		movieRepository:       movieRepository,
+		movieSearchRepository: movieSearchRepository,
+		movieStatsRepository:  movieStatsRepository,
 	}
 }
 
@@ -35,6 +53,179 @@ func (s *movieService) Get(ctx context.Context, req request.GetMovie) (*response
 	return movie.GetMovieResponse(), nil
 }
 
+func (s *movieService) List(ctx context.Context, req request.ListMovies) (*response.ListMovies, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, total, err := s.movieRepository.List(ctx, repository.MovieFilter{
+		Genre:          req.Genre,
+		Director:       req.Director,
+		YearFrom:       req.YearFrom,
+		YearTo:         req.YearTo,
+		MinRating:      req.MinRating,
+		MinRatingCount: req.MinRatingCount,
+		SortBy:         req.SortBy,
+		Descending:     req.Order == "desc",
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list movies: %w", err)
+	}
+
+	resp := &response.ListMovies{Pagination: encodePage(limit, offset, total)}
+	resp.Movies = make([]response.GetMovie, len(movies))
+	for i, movie := range movies {
+		resp.Movies[i] = *movie.GetMovieResponse()
+	}
+	return resp, nil
+}
+
+func (s *movieService) Top(ctx context.Context, req request.TopMovies) ([]response.GetMovie, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTopLimit
+	}
+
+	movies, _, err := s.movieRepository.List(ctx, repository.MovieFilter{
+		Genre:      req.Genre,
+		SortBy:     "weighted_rating",
+		Descending: true,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list top movies: %w", err)
+	}
+
+	resp := make([]response.GetMovie, len(movies))
+	for i, movie := range movies {
+		resp[i] = *movie.GetMovieResponse()
+	}
+	return resp, nil
+}
+
+func (s *movieService) Search(ctx context.Context, req request.SearchMovies) (*response.SearchMovies, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, total, err := s.movieSearchRepository.Search(ctx, req.Query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search movies: %w", err)
+	}
+
+	resp := &response.SearchMovies{Pagination: encodePage(limit, offset, total)}
+	resp.Movies = make([]response.SearchMovie, len(hits))
+	for i, hit := range hits {
+		resp.Movies[i] = *hit.SearchMovieResponse()
+	}
+	return resp, nil
+}
+
+func (s *movieService) Suggest(ctx context.Context, req request.SuggestMovies) ([]response.SuggestMovie, error) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultSuggestLimit
+	}
+
+	movies, err := s.movieSearchRepository.Suggest(ctx, req.Prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest movies: %w", err)
+	}
+
+	resp := make([]response.SuggestMovie, len(movies))
+	for i, movie := range movies {
+		resp[i] = *movie.SuggestMovieResponse()
+	}
+	return resp, nil
+}
+
+func (s *movieService) GetStats(ctx context.Context, req request.GetMovieStats) (*response.MovieStats, error) {
+	if _, err := s.movieRepository.Get(ctx, req.ID); err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	buckets, err := s.movieStatsRepository.GetBuckets(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie's rating histogram: %w", err)
+	}
+
+	periods, err := s.movieStatsRepository.GetPeriods(ctx, req.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie's rating timeline: %w", err)
+	}
+
+	return buildMovieStats(buckets, periods), nil
+}
+
+// buildMovieStats derives the summary from the pre-aggregated tables. The median is read from the
+// histogram, so it is exact to the half star the buckets are rounded to.
+func buildMovieStats(buckets []domain.MovieRatingBucket, periods []domain.MovieRatingPeriod) *response.MovieStats {
+	stats := &response.MovieStats{
+		Histogram: make([]response.HistogramBucket, maxRatingBucket+1),
+		Timeline:  make([]response.RatingPeriod, 0, len(periods)),
+	}
+	for i := range stats.Histogram {
+		stats.Histogram[i].Score = float64(i) / 2
+	}
+	for _, bucket := range buckets {
+		if bucket.Bucket >= 0 && bucket.Bucket <= maxRatingBucket {
+			stats.Histogram[bucket.Bucket].Count = bucket.Count
+		}
+	}
+
+	var sum, sumSquares float64
+	for _, period := range periods {
+		stats.RatingCount += period.Count
+		sum += period.Sum
+		sumSquares += period.SumSquares
+		stats.Timeline = append(stats.Timeline, response.RatingPeriod{
+			Period:            period.Period.Format("2006-01"),
+			Count:             period.Count,
+			Average:           period.Sum / float64(period.Count),
+			CumulativeCount:   stats.RatingCount,
+			CumulativeAverage: sum / float64(stats.RatingCount),
+		})
+	}
+	if stats.RatingCount == 0 {
+		return stats
+	}
+
+	stats.Average = sum / float64(stats.RatingCount)
+	stats.StdDev = math.Sqrt(math.Max(sumSquares/float64(stats.RatingCount)-stats.Average*stats.Average, 0))
+	stats.Median = histogramMedian(stats.Histogram)
+	return stats
+}
+
+func histogramMedian(histogram []response.HistogramBucket) float64 {
+	var total int64
+	for _, bucket := range histogram {
+		total += bucket.Count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// 1-based positions of the middle element(s); they coincide when total is odd.
+	lower, upper := (total+1)/2, total/2+1
+	var seen int64
+	var lowerScore float64
+	lowerFound := false
+	for _, bucket := range histogram {
+		seen += bucket.Count
+		if !lowerFound && seen >= lower {
+			lowerScore, lowerFound = bucket.Score, true
+		}
+		if seen >= upper {
+			return (lowerScore + bucket.Score) / 2
+		}
+	}
+	return lowerScore
+}
+
 func (s *movieService) Create(ctx context.Context, req request.CreateMovie) (*response.CreateMovie, error) {
 	movie, err := s.movieRepository.Create(ctx, domain.Movie{
 		Title:       req.Title,
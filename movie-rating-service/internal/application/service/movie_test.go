@@ -0,0 +1,298 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"movie-rating-service/mocks"
+	"testing"
+	"time"
+)
+
+type MovieServiceTest struct {
+	suite.Suite
+	service movieService
+	m       *mocks.MovieRepository
+	ms      *mocks.MovieSearchRepository
+	s       *mocks.MovieStatsRepository
+}
+
+func (m *MovieServiceTest) SetupTest() {
+	m.m = new(mocks.MovieRepository)
+	m.ms = new(mocks.MovieSearchRepository)
+	m.s = new(mocks.MovieStatsRepository)
+
+	m.service = movieService{movieRepository: m.m, movieSearchRepository: m.ms, movieStatsRepository: m.s}
+}
+
+func Test_RunMovieServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieServiceTest))
+}
+
+func (m *MovieServiceTest) TestMovieService_GetStats_Success() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	req := request.GetMovieStats{ID: 7}
+
+	// Scores 2.0, 4.0, 4.5, 5.0 spread over two months.
+	buckets := []domain.MovieRatingBucket{
+		{MovieID: 7, Bucket: 4, Count: 1},
+		{MovieID: 7, Bucket: 8, Count: 1},
+		{MovieID: 7, Bucket: 9, Count: 1},
+		{MovieID: 7, Bucket: 10, Count: 1},
+	}
+	periods := []domain.MovieRatingPeriod{
+		{MovieID: 7, Period: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 1, Sum: 2, SumSquares: 4},
+		{MovieID: 7, Period: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Count: 3, Sum: 13.5, SumSquares: 61.25},
+	}
+
+	m.m.On("Get", ctx, req.ID).Return(&domain.Movie{}, nil).Once()
+	m.s.On("GetBuckets", ctx, req.ID).Return(buckets, nil).Once()
+	m.s.On("GetPeriods", ctx, req.ID).Return(periods, nil).Once()
+
+	result, err := m.service.GetStats(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), result.RatingCount)
+	assert.InDelta(t, 3.875, result.Average, 1e-9)
+	assert.InDelta(t, 4.25, result.Median, 1e-9)
+	assert.InDelta(t, 1.1388042, result.StdDev, 1e-6)
+	assert.Len(t, result.Histogram, 11)
+	assert.Equal(t, 4.5, result.Histogram[9].Score)
+	assert.Equal(t, int64(1), result.Histogram[9].Count)
+	assert.Len(t, result.Timeline, 2)
+	assert.Equal(t, "2024-03", result.Timeline[1].Period)
+	assert.Equal(t, int64(4), result.Timeline[1].CumulativeCount)
+	assert.InDelta(t, 4.5, result.Timeline[1].Average, 1e-9)
+
+	m.m.AssertExpectations(t)
+	m.s.AssertExpectations(t)
+}
+
+func (m *MovieServiceTest) TestMovieService_GetStats_Empty() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	req := request.GetMovieStats{ID: 7}
+
+	m.m.On("Get", ctx, req.ID).Return(&domain.Movie{}, nil).Once()
+	m.s.On("GetBuckets", ctx, req.ID).Return(nil, nil).Once()
+	m.s.On("GetPeriods", ctx, req.ID).Return(nil, nil).Once()
+
+	result, err := m.service.GetStats(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Zero(t, result.RatingCount)
+	assert.Zero(t, result.Median)
+	assert.Len(t, result.Histogram, 11)
+	assert.Empty(t, result.Timeline)
+}
+
+func (m *MovieServiceTest) TestMovieService_GetStats_Error_Movie_Not_Found() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	req := request.GetMovieStats{ID: 7}
+
+	m.m.On("Get", ctx, req.ID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	result, err := m.service.GetStats(ctx, req)
+
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.Nil(t, result)
+
+	m.s.AssertExpectations(t)
+}
+
+func (m *MovieServiceTest) TestMovieService_List_Success() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	req := request.ListMovies{
+		Pagination:     request.Pagination{Limit: 2},
+		Genre:          "Crime",
+		Director:       "mann",
+		YearFrom:       1990,
+		YearTo:         1999,
+		MinRating:      4,
+		MinRatingCount: 10,
+		SortBy:         "year",
+		Order:          "desc",
+	}
+
+	m.m.On("List", ctx, repository.MovieFilter{
+		Genre:          "Crime",
+		Director:       "mann",
+		YearFrom:       1990,
+		YearTo:         1999,
+		MinRating:      4,
+		MinRatingCount: 10,
+		SortBy:         "year",
+		Descending:     true,
+		Limit:          2,
+		Offset:         0,
+	}).Return([]domain.Movie{
+		{Model: gorm.Model{ID: 1}, Title: "Heat", Year: 1995},
+		{Model: gorm.Model{ID: 2}, Title: "The Insider", Year: 1999},
+	}, int64(3), nil).Once()
+
+	result, err := m.service.List(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Movies, 2)
+	assert.Equal(t, "Heat", result.Movies[0].Title)
+	assert.Equal(t, int64(3), result.Pagination.Total)
+	assert.NotEmpty(t, result.Pagination.NextCursor)
+	m.m.AssertExpectations(t)
+}
+
+func (m *MovieServiceTest) TestMovieService_List_Next_Page() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	cursor := encodePage(2, 0, 3).NextCursor
+	m.m.On("List", ctx, repository.MovieFilter{Limit: 2, Offset: 2}).
+		Return([]domain.Movie{{Model: gorm.Model{ID: 3}, Title: "Thief"}}, int64(3), nil).Once()
+
+	result, err := m.service.List(ctx, request.ListMovies{Pagination: request.Pagination{Limit: 2, Cursor: cursor}})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Movies, 1)
+	assert.Empty(t, result.Pagination.NextCursor)
+}
+
+func (m *MovieServiceTest) TestMovieService_List_Error_Invalid_Cursor() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	result, err := m.service.List(ctx, request.ListMovies{Pagination: request.Pagination{Cursor: "%%%"}})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.Nil(t, result)
+	m.m.AssertNotCalled(t, "List")
+}
+
+func (m *MovieServiceTest) TestMovieService_List_Error_Repository() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	m.m.On("List", ctx, repository.MovieFilter{Limit: defaultPageLimit}).Return(nil, int64(0), errors.New("there is an error")).Once()
+
+	result, err := m.service.List(ctx, request.ListMovies{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
+
+func (m *MovieServiceTest) TestMovieService_Search_Success() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	m.ms.On("Search", ctx, "heat", 20, 0).Return([]domain.MovieSearchHit{
+		{Movie: domain.Movie{Model: gorm.Model{ID: 1}, Title: "Heat"}, Rank: 0.9, Highlight: "<mark>Heat</mark>", Snippet: "A &lt;b&gt; crew"},
+	}, int64(1), nil).Once()
+
+	result, err := m.service.Search(ctx, request.SearchMovies{Query: "heat"})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Movies, 1)
+	assert.Equal(t, uint(1), result.Movies[0].ID)
+	assert.Equal(t, 0.9, result.Movies[0].Rank)
+	assert.Equal(t, "<mark>Heat</mark>", result.Movies[0].Highlight)
+	assert.Equal(t, "A &lt;b&gt; crew", result.Movies[0].Snippet)
+	assert.Equal(t, int64(1), result.Pagination.Total)
+}
+
+func (m *MovieServiceTest) TestMovieService_Search_Error_Invalid_Cursor() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	result, err := m.service.Search(ctx, request.SearchMovies{Query: "heat", Pagination: request.Pagination{Cursor: "%%%"}})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.Nil(t, result)
+	m.ms.AssertNotCalled(t, "Search")
+}
+
+func (m *MovieServiceTest) TestMovieService_Suggest_Default_Limit() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	m.ms.On("Suggest", ctx, "hea", defaultSuggestLimit).Return([]domain.Movie{{Model: gorm.Model{ID: 1}, Title: "Heat"}}, nil).Once()
+
+	result, err := m.service.Suggest(ctx, request.SuggestMovies{Prefix: "hea"})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Heat", result[0].Title)
+}
+
+func (m *MovieServiceTest) TestMovieService_Top_Success() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	movies := []domain.Movie{
+		{Model: gorm.Model{ID: 2}, Title: "Heat", Rating: 4.8, RatingCount: 10000, WeightedRating: 4.797},
+		{Model: gorm.Model{ID: 1}, Title: "Obscure", Rating: 5, RatingCount: 1, WeightedRating: 3.18},
+	}
+	m.m.On("List", ctx, repository.MovieFilter{Genre: "crime", SortBy: "weighted_rating", Descending: true, Limit: 5}).
+		Return(movies, int64(2), nil).Once()
+
+	res, err := m.service.Top(ctx, request.TopMovies{Limit: 5, Genre: "crime"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, uint(2), res[0].ID)
+		assert.Equal(t, 4.797, res[0].WeightedRating)
+		assert.Equal(t, uint(1), res[1].ID)
+	}
+	m.m.AssertExpectations(t)
+}
+
+func (m *MovieServiceTest) TestMovieService_Top_Default_Limit() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	m.m.On("List", ctx, repository.MovieFilter{SortBy: "weighted_rating", Descending: true, Limit: defaultTopLimit}).
+		Return([]domain.Movie{}, int64(0), nil).Once()
+
+	res, err := m.service.Top(ctx, request.TopMovies{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+	m.m.AssertExpectations(t)
+}
+
+func (m *MovieServiceTest) TestMovieService_Top_Error_Repository() {
+	t := m.T()
+
+	ctx := context.TODO()
+
+	m.m.On("List", ctx, repository.MovieFilter{SortBy: "weighted_rating", Descending: true, Limit: defaultTopLimit}).
+		Return(nil, int64(0), errors.New("boom")).Once()
+
+	_, err := m.service.Top(ctx, request.TopMovies{})
+
+	assert.Error(t, err)
+}
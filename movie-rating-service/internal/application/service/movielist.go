@@ -0,0 +1,469 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/repository"
+)
+
+const (
+	// maxMovieListItems caps a list, so that it can always be reordered in one request.
+	maxMovieListItems   = 500
+	maxMovieListEditors = 20
+)
+
+// movieListRole is what a user may do with a list. Each role may do everything the ones before it
+// may: viewers read the list, editors also change its items, and the owner also changes the list
+// itself, its editors, and deletes it.
+type movieListRole int
+
+const (
+	movieListNone movieListRole = iota
+	movieListViewer
+	movieListEditor
+	movieListOwner
+)
+
+// MovieListService keeps the lists of movies users curate. Lists that are not visible to the caller
+// are reported as not found, so that private lists do not give away that they exist. Unlisted lists
+// are only visible to others through their slug, and a list only shows where it was cloned from if
+// the caller may see the source by its id.
+type MovieListService interface {
+	Create(ctx context.Context, req request.CreateMovieList) (*response.MovieList, error)
+	Get(ctx context.Context, req request.GetMovieList) (*response.MovieList, error)
+	Update(ctx context.Context, req request.UpdateMovieList) error
+	Delete(ctx context.Context, req request.DeleteMovieList) error
+	// Browse lists the public lists, newest first.
+	Browse(ctx context.Context, req request.BrowseMovieLists) (*response.ListMovieLists, error)
+	// ListMine lists the lists the user owns or edits, newest first.
+	ListMine(ctx context.Context, req request.ListMyMovieLists) (*response.ListMovieLists, error)
+	Clone(ctx context.Context, req request.CloneMovieList) (*response.MovieList, error)
+
+	ListItems(ctx context.Context, req request.ListMovieListItems) (*response.ListMovieListItems, error)
+	// AddItem puts the movie at the end of the list.
+	AddItem(ctx context.Context, req request.AddMovieListItem) error
+	UpdateItem(ctx context.Context, req request.UpdateMovieListItem) error
+	RemoveItem(ctx context.Context, req request.RemoveMovieListItem) error
+	// Reorder moves the given movies to the top of the list in the given order. The movies left out
+	// follow in their current order.
+	Reorder(ctx context.Context, req request.ReorderMovieList) error
+
+	AddEditor(ctx context.Context, req request.AddMovieListEditor) error
+	// RemoveEditor is allowed to the owner, and to editors removing themselves.
+	RemoveEditor(ctx context.Context, req request.RemoveMovieListEditor) error
+}
+
+type movieListService struct {
+	txManager           db.TxManager
+	movieListRepository repository.MovieListRepository
+	movieRepository     repository.MovieRepository
+	userRepository      repository.UserRepository
+}
+
+func NewMovieListService(txManager db.TxManager, movieListRepository repository.MovieListRepository, movieRepository repository.MovieRepository, userRepository repository.UserRepository) MovieListService {
+	return &movieListService{
+		txManager:           txManager,
+		movieListRepository: movieListRepository,
+		movieRepository:     movieRepository,
+		userRepository:      userRepository,
+	}
+}
+
+func (s *movieListService) Create(ctx context.Context, req request.CreateMovieList) (*response.MovieList, error) {
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = domain.MovieListVisibilityPrivate
+	}
+
+	list, err := s.movieListRepository.Create(ctx, domain.MovieList{
+		OwnerID:     req.UserID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  visibility,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list: %w", err)
+	}
+
+	list, err = s.movieListRepository.Get(ctx, list.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	return movieListResponse(list, 0, req.UserID), nil
+}
+
+func (s *movieListService) Get(ctx context.Context, req request.GetMovieList) (*response.MovieList, error) {
+	list, err := s.viewList(ctx, req.ListID, req.Slug, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.movieListRepository.CountItems(ctx, list.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count list items: %w", err)
+	}
+	return movieListResponse(list, count, req.UserID), nil
+}
+
+func (s *movieListService) Update(ctx context.Context, req request.UpdateMovieList) error {
+	list, err := s.getList(ctx, req.ListID, req.UserID, movieListOwner)
+	if err != nil {
+		return err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Name != nil {
+		changes["name"] = *req.Name
+	}
+	if req.Description != nil {
+		changes["description"] = *req.Description
+	}
+	if req.Visibility != nil {
+		changes["visibility"] = *req.Visibility
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err = s.movieListRepository.Update(ctx, list.ID, changes); err != nil {
+		return fmt.Errorf("failed to update list: %w", err)
+	}
+	return nil
+}
+
+func (s *movieListService) Delete(ctx context.Context, req request.DeleteMovieList) error {
+	list, err := s.getList(ctx, req.ListID, req.UserID, movieListOwner)
+	if err != nil {
+		return err
+	}
+
+	if err = s.movieListRepository.Delete(ctx, list.ID); err != nil {
+		return fmt.Errorf("failed to delete list: %w", err)
+	}
+	return nil
+}
+
+func (s *movieListService) Browse(ctx context.Context, req request.BrowseMovieLists) (*response.ListMovieLists, error) {
+	return s.list(ctx, req.Pagination, 0, repository.MovieListFilter{
+		Visibility: domain.MovieListVisibilityPublic,
+		Query:      req.Query,
+	})
+}
+
+func (s *movieListService) ListMine(ctx context.Context, req request.ListMyMovieLists) (*response.ListMovieLists, error) {
+	return s.list(ctx, req.Pagination, req.UserID, repository.MovieListFilter{MemberID: req.UserID})
+}
+
+func (s *movieListService) list(ctx context.Context, pagination request.Pagination, userID uint, filter repository.MovieListFilter) (*response.ListMovieLists, error) {
+	limit, offset, err := decodePage(pagination)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = limit
+	filter.Offset = offset
+
+	lists, total, err := s.movieListRepository.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", err)
+	}
+
+	resp := &response.ListMovieLists{Pagination: encodePage(limit, offset, total)}
+	resp.Lists = make([]response.MovieList, len(lists))
+	for i, list := range lists {
+		resp.Lists[i] = *movieListResponse(&list.MovieList, list.ItemCount, userID)
+	}
+	return resp, nil
+}
+
+func (s *movieListService) Clone(ctx context.Context, req request.CloneMovieList) (*response.MovieList, error) {
+	source, err := s.viewList(ctx, req.ListID, req.Slug, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = source.Name
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = domain.MovieListVisibilityPrivate
+	}
+
+	var clone *domain.MovieList
+	var count int64
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.movieListRepository.Create(ctx, domain.MovieList{
+			OwnerID:      req.UserID,
+			Name:         name,
+			Description:  source.Description,
+			Visibility:   visibility,
+			ClonedFromID: &source.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create list: %w", err)
+		}
+		if err = s.movieListRepository.CopyItems(ctx, source.ID, created.ID, req.UserID); err != nil {
+			return fmt.Errorf("failed to copy list items: %w", err)
+		}
+
+		clone, err = s.movieListRepository.Get(ctx, created.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get list: %w", err)
+		}
+		count, err = s.movieListRepository.CountItems(ctx, created.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count list items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movieListResponse(clone, count, req.UserID), nil
+}
+
+func (s *movieListService) ListItems(ctx context.Context, req request.ListMovieListItems) (*response.ListMovieListItems, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.viewList(ctx, req.ListID, req.Slug, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.movieListRepository.ListItems(ctx, list.ID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list items: %w", err)
+	}
+
+	resp := &response.ListMovieListItems{Pagination: encodePage(limit, offset, total)}
+	resp.Items = make([]response.MovieListItem, len(items))
+	for i, item := range items {
+		resp.Items[i] = *item.GetMovieListItemResponse()
+	}
+	return resp, nil
+}
+
+func (s *movieListService) AddItem(ctx context.Context, req request.AddMovieListItem) error {
+	if _, err := s.movieRepository.Get(ctx, req.MovieID); err != nil {
+		return fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Otherwise concurrent adds could all pass the count below.
+		if err := s.movieListRepository.Lock(ctx, req.ListID); err != nil {
+			return fmt.Errorf("failed to lock list: %w", err)
+		}
+		list, err := s.getList(ctx, req.ListID, req.UserID, movieListEditor)
+		if err != nil {
+			return err
+		}
+
+		count, err := s.movieListRepository.CountItems(ctx, list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count list items: %w", err)
+		}
+		if count >= maxMovieListItems {
+			return fmt.Errorf("%w: a list holds at most %d movies", common.ErrBadRequest, maxMovieListItems)
+		}
+
+		added, err := s.movieListRepository.AddItem(ctx, domain.MovieListItem{
+			ListID:    list.ID,
+			MovieID:   req.MovieID,
+			Note:      req.Note,
+			AddedByID: &req.UserID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add list item: %w", err)
+		}
+		if !added {
+			return fmt.Errorf("%w: movie is already on the list", common.ErrBadRequest)
+		}
+		return nil
+	})
+}
+
+func (s *movieListService) UpdateItem(ctx context.Context, req request.UpdateMovieListItem) error {
+	list, err := s.getList(ctx, req.ListID, req.UserID, movieListEditor)
+	if err != nil {
+		return err
+	}
+
+	updated, err := s.movieListRepository.UpdateItemNote(ctx, list.ID, req.MovieID, req.Note)
+	if err != nil {
+		return fmt.Errorf("failed to update list item: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("movie is not on the list: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *movieListService) RemoveItem(ctx context.Context, req request.RemoveMovieListItem) error {
+	list, err := s.getList(ctx, req.ListID, req.UserID, movieListEditor)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.movieListRepository.RemoveItem(ctx, list.ID, req.MovieID)
+	if err != nil {
+		return fmt.Errorf("failed to remove list item: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("movie is not on the list: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (s *movieListService) Reorder(ctx context.Context, req request.ReorderMovieList) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		list, err := s.getList(ctx, req.ListID, req.UserID, movieListEditor)
+		if err != nil {
+			return err
+		}
+
+		current, err := s.movieListRepository.ListMovieIDs(ctx, list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get list items: %w", err)
+		}
+		order, err := moveToFront(current, req.MovieIDs, "the list")
+		if err != nil {
+			return err
+		}
+
+		if err = s.movieListRepository.Reorder(ctx, list.ID, order); err != nil {
+			return fmt.Errorf("failed to reorder list: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *movieListService) AddEditor(ctx context.Context, req request.AddMovieListEditor) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Otherwise concurrent invitations could all pass the count below.
+		if err := s.movieListRepository.Lock(ctx, req.ListID); err != nil {
+			return fmt.Errorf("failed to lock list: %w", err)
+		}
+		list, err := s.getList(ctx, req.ListID, req.UserID, movieListOwner)
+		if err != nil {
+			return err
+		}
+
+		user, err := s.userRepository.GetByUsername(ctx, req.Username)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user.ID == list.OwnerID {
+			return fmt.Errorf("%w: the owner of a list cannot be its editor", common.ErrBadRequest)
+		}
+		if list.IsEditor(user.ID) {
+			return nil
+		}
+		if len(list.Editors) >= maxMovieListEditors {
+			return fmt.Errorf("%w: a list has at most %d editors", common.ErrBadRequest, maxMovieListEditors)
+		}
+
+		if _, err = s.movieListRepository.AddEditor(ctx, list.ID, user.ID); err != nil {
+			return fmt.Errorf("failed to add list editor: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *movieListService) RemoveEditor(ctx context.Context, req request.RemoveMovieListEditor) error {
+	list, err := s.getList(ctx, req.ListID, req.UserID, movieListViewer)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepository.GetByUsername(ctx, req.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if list.OwnerID != req.UserID && user.ID != req.UserID {
+		return fmt.Errorf("%w: only the list's owner can remove other editors", common.ErrForbidden)
+	}
+
+	removed, err := s.movieListRepository.RemoveEditor(ctx, list.ID, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to remove list editor: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("user is not an editor of the list: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// viewList returns the list if the user may view it, found by its id or, if given, its slug.
+func (s *movieListService) viewList(ctx context.Context, listID uint, slug string, userID uint) (*domain.MovieList, error) {
+	if slug == "" {
+		return s.getList(ctx, listID, userID, movieListViewer)
+	}
+
+	list, err := s.movieListRepository.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+	if movieListRoleOf(list, userID, true) == movieListNone {
+		return nil, fmt.Errorf("failed to get list: %w", gorm.ErrRecordNotFound)
+	}
+	return list, nil
+}
+
+// getList returns the list if the user may act on it as the given role. userID is 0 for anonymous
+// callers, who may only view public lists by their id.
+func (s *movieListService) getList(ctx context.Context, listID, userID uint, role movieListRole) (*domain.MovieList, error) {
+	list, err := s.movieListRepository.Get(ctx, listID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	has := movieListRoleOf(list, userID, false)
+	if has == movieListNone {
+		return nil, fmt.Errorf("failed to get list: %w", gorm.ErrRecordNotFound)
+	}
+	if has < role {
+		if role == movieListOwner {
+			return nil, fmt.Errorf("%w: only the list's owner can do this", common.ErrForbidden)
+		}
+		return nil, fmt.Errorf("%w: only the list's owner and editors can change its movies", common.ErrForbidden)
+	}
+	return list, nil
+}
+
+// movieListRoleOf returns what the user may do with the list. bySlug tells whether it was found by
+// its slug, through which unlisted lists can be viewed; ids can be guessed, so by its id only public
+// lists can.
+func movieListRoleOf(list *domain.MovieList, userID uint, bySlug bool) movieListRole {
+	switch {
+	case userID != 0 && list.OwnerID == userID:
+		return movieListOwner
+	case userID != 0 && list.IsEditor(userID):
+		return movieListEditor
+	case list.Visibility == domain.MovieListVisibilityPublic:
+		return movieListViewer
+	case list.Visibility == domain.MovieListVisibilityUnlisted && bySlug:
+		return movieListViewer
+	default:
+		return movieListNone
+	}
+}
+
+// movieListResponse leaves out the list the list was cloned from unless the user may see it by its
+// id, so that a clone does not give away the id of an unlisted or private source.
+func movieListResponse(list *domain.MovieList, itemCount int64, userID uint) *response.MovieList {
+	resp := list.GetMovieListResponse(itemCount)
+	if list.ClonedFrom == nil || movieListRoleOf(list.ClonedFrom, userID, false) == movieListNone {
+		resp.ClonedFromID = nil
+	}
+	return resp
+}
@@ -0,0 +1,491 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"movie-rating-service/mocks"
+	"testing"
+)
+
+type MovieListServiceTest struct {
+	suite.Suite
+	service *movieListService
+	tx      *mocks.TxManager
+	l       *mocks.MovieListRepository
+	m       *mocks.MovieRepository
+	u       *mocks.UserRepository
+}
+
+func (s *MovieListServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.l = new(mocks.MovieListRepository)
+	s.m = new(mocks.MovieRepository)
+	s.u = new(mocks.UserRepository)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	s.service = NewMovieListService(s.tx, s.l, s.m, s.u).(*movieListService)
+}
+
+func Test_RunMovieListServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieListServiceTest))
+}
+
+// list returns list 10, owned by user 1 and edited by user 2.
+func (s *MovieListServiceTest) list(visibility string) *domain.MovieList {
+	return &domain.MovieList{
+		ID:         10,
+		OwnerID:    1,
+		Name:       "Heists",
+		Visibility: visibility,
+		Owner:      domain.User{Model: gorm.Model{ID: 1}, Username: "alice"},
+		Editors:    []domain.User{{Model: gorm.Model{ID: 2}, Username: "bob"}},
+	}
+}
+
+func (s *MovieListServiceTest) TestCreate_Defaults_To_Private() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Create", ctx, domain.MovieList{OwnerID: 1, Name: "Heists", Visibility: domain.MovieListVisibilityPrivate}).
+		Return(&domain.MovieList{ID: 10}, nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+
+	res, err := s.service.Create(ctx, request.CreateMovieList{UserID: 1, Name: "Heists"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(10), res.ID)
+	assert.Equal(t, "alice", res.Owner)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestGet_Public_Anonymous() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPublic), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(3), nil).Once()
+
+	res, err := s.service.Get(ctx, request.GetMovieList{ListID: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.ItemCount)
+	assert.Equal(t, []string{"bob"}, res.Editors)
+}
+
+func (s *MovieListServiceTest) TestGet_Unlisted_By_Slug_Anonymous() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("GetBySlug", ctx, "3f2a").Return(s.list(domain.MovieListVisibilityUnlisted), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(3), nil).Once()
+
+	res, err := s.service.Get(ctx, request.GetMovieList{Slug: "3f2a"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(10), res.ID)
+}
+
+func (s *MovieListServiceTest) TestGet_Error_Unlisted_By_Id_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// Ids can be guessed, so they do not reveal unlisted lists to anybody but the owner and editors.
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityUnlisted), nil).Twice()
+
+	_, err := s.service.Get(ctx, request.GetMovieList{ListID: 10})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = s.service.Get(ctx, request.GetMovieList{ListID: 10, UserID: 3})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func (s *MovieListServiceTest) TestGet_Error_Private_By_Slug_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("GetBySlug", ctx, "3f2a").Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+
+	_, err := s.service.Get(ctx, request.GetMovieList{Slug: "3f2a", UserID: 3})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func (s *MovieListServiceTest) TestGet_Cloned_From_Only_If_Source_Visible() {
+	t := s.T()
+	ctx := context.TODO()
+
+	sourceID := uint(7)
+	list := s.list(domain.MovieListVisibilityPublic)
+	list.ClonedFromID = &sourceID
+	list.ClonedFrom = &domain.MovieList{ID: 7, OwnerID: 5, Visibility: domain.MovieListVisibilityUnlisted}
+	s.l.On("Get", ctx, uint(10)).Return(list, nil).Times(2)
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(3), nil).Times(2)
+
+	res, err := s.service.Get(ctx, request.GetMovieList{ListID: 10, UserID: 3})
+	s.Require().NoError(err)
+	assert.Nil(t, res.ClonedFromID)
+
+	res, err = s.service.Get(ctx, request.GetMovieList{ListID: 10, UserID: 5})
+	s.Require().NoError(err)
+	assert.Equal(t, &sourceID, res.ClonedFromID)
+}
+
+func (s *MovieListServiceTest) TestGet_Private_Editor() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(0), nil).Once()
+
+	_, err := s.service.Get(ctx, request.GetMovieList{ListID: 10, UserID: 2})
+
+	assert.NoError(t, err)
+}
+
+func (s *MovieListServiceTest) TestGet_Error_Private_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Twice()
+
+	_, err := s.service.Get(ctx, request.GetMovieList{ListID: 10, UserID: 3})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = s.service.Get(ctx, request.GetMovieList{ListID: 10})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func (s *MovieListServiceTest) TestUpdate_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	name := "Capers"
+	visibility := domain.MovieListVisibilityPublic
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("Update", ctx, uint(10), map[string]interface{}{"name": "Capers", "visibility": "public"}).Return(nil).Once()
+
+	err := s.service.Update(ctx, request.UpdateMovieList{ListID: 10, UserID: 1, Name: &name, Visibility: &visibility})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestUpdate_Error_Editor_Forbidden() {
+	t := s.T()
+	ctx := context.TODO()
+
+	name := "Capers"
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+
+	err := s.service.Update(ctx, request.UpdateMovieList{ListID: 10, UserID: 2, Name: &name})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.l.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestDelete_Error_Viewer_Forbidden() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPublic), nil).Once()
+
+	err := s.service.Delete(ctx, request.DeleteMovieList{ListID: 10, UserID: 3})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.l.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestBrowse_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("List", ctx, repository.MovieListFilter{Visibility: domain.MovieListVisibilityPublic, Query: "heist", Limit: 20}).
+		Return([]domain.MovieListSummary{{MovieList: *s.list(domain.MovieListVisibilityPublic), ItemCount: 4}}, int64(1), nil).Once()
+
+	res, err := s.service.Browse(ctx, request.BrowseMovieLists{Query: "heist"})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Lists, 1)
+	assert.Equal(t, int64(4), res.Lists[0].ItemCount)
+	assert.Equal(t, int64(1), res.Pagination.Total)
+	assert.Empty(t, res.Pagination.NextCursor)
+}
+
+func (s *MovieListServiceTest) TestClone_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	sourceID := uint(10)
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPublic), nil).Once()
+	s.l.On("Create", ctx, domain.MovieList{OwnerID: 3, Name: "Heists", Visibility: domain.MovieListVisibilityPrivate, ClonedFromID: &sourceID}).
+		Return(&domain.MovieList{ID: 11}, nil).Once()
+	s.l.On("CopyItems", ctx, uint(10), uint(11), uint(3)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(11)).Return(&domain.MovieList{ID: 11, OwnerID: 3, Name: "Heists", ClonedFromID: &sourceID, ClonedFrom: s.list(domain.MovieListVisibilityPublic)}, nil).Once()
+	s.l.On("CountItems", ctx, uint(11)).Return(int64(4), nil).Once()
+
+	res, err := s.service.Clone(ctx, request.CloneMovieList{ListID: 10, UserID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(11), res.ID)
+	assert.Equal(t, &sourceID, res.ClonedFromID)
+	assert.Equal(t, int64(4), res.ItemCount)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestClone_Unlisted_By_Slug() {
+	t := s.T()
+	ctx := context.TODO()
+
+	sourceID := uint(10)
+	s.l.On("GetBySlug", ctx, "3f2a").Return(s.list(domain.MovieListVisibilityUnlisted), nil).Once()
+	s.l.On("Create", ctx, domain.MovieList{OwnerID: 3, Name: "Heists", Visibility: domain.MovieListVisibilityPrivate, ClonedFromID: &sourceID}).
+		Return(&domain.MovieList{ID: 11}, nil).Once()
+	s.l.On("CopyItems", ctx, uint(10), uint(11), uint(3)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(11)).Return(&domain.MovieList{ID: 11, OwnerID: 3, Name: "Heists", ClonedFromID: &sourceID, ClonedFrom: s.list(domain.MovieListVisibilityUnlisted)}, nil).Once()
+	s.l.On("CountItems", ctx, uint(11)).Return(int64(4), nil).Once()
+
+	res, err := s.service.Clone(ctx, request.CloneMovieList{Slug: "3f2a", UserID: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(11), res.ID)
+	// The source cannot be seen by its id.
+	assert.Nil(t, res.ClonedFromID)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestClone_Error_Unlisted_By_Id_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityUnlisted), nil).Once()
+
+	_, err := s.service.Clone(ctx, request.CloneMovieList{ListID: 10, UserID: 3})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	s.l.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestClone_Error_Private_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+
+	_, err := s.service.Clone(ctx, request.CloneMovieList{ListID: 10, UserID: 3})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	s.l.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddItem_Editor_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	userID := uint(2)
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(3), nil).Once()
+	s.l.On("AddItem", ctx, domain.MovieListItem{ListID: 10, MovieID: 42, Note: "Watch first", AddedByID: &userID}).Return(true, nil).Once()
+
+	err := s.service.AddItem(ctx, request.AddMovieListItem{ListID: 10, UserID: 2, MovieID: 42, Note: "Watch first"})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestAddItem_Error_Viewer_Forbidden() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPublic), nil).Once()
+
+	err := s.service.AddItem(ctx, request.AddMovieListItem{ListID: 10, UserID: 3, MovieID: 42})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.l.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddItem_Error_Already_On_List() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(3), nil).Once()
+	s.l.On("AddItem", ctx, mock.Anything).Return(false, nil).Once()
+
+	err := s.service.AddItem(ctx, request.AddMovieListItem{ListID: 10, UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+}
+
+func (s *MovieListServiceTest) TestAddItem_Error_List_Full() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("CountItems", ctx, uint(10)).Return(int64(maxMovieListItems), nil).Once()
+
+	err := s.service.AddItem(ctx, request.AddMovieListItem{ListID: 10, UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.l.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddItem_Error_Lock() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(errors.New("there is an error")).Once()
+
+	err := s.service.AddItem(ctx, request.AddMovieListItem{ListID: 10, UserID: 1, MovieID: 42})
+
+	assert.ErrorContains(t, err, "failed to lock list")
+	s.l.AssertNotCalled(t, "CountItems", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestRemoveItem_Error_Not_On_List() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("RemoveItem", ctx, uint(10), uint(42)).Return(false, nil).Once()
+
+	err := s.service.RemoveItem(ctx, request.RemoveMovieListItem{ListID: 10, UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func (s *MovieListServiceTest) TestReorder_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("ListMovieIDs", ctx, uint(10)).Return([]uint{3, 5, 7}, nil).Once()
+	s.l.On("Reorder", ctx, uint(10), []uint{7, 3, 5}).Return(nil).Once()
+
+	err := s.service.Reorder(ctx, request.ReorderMovieList{ListID: 10, UserID: 2, MovieIDs: []uint{7}})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestReorder_Error_Not_On_List() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("ListMovieIDs", ctx, uint(10)).Return([]uint{3, 5}, nil).Once()
+
+	err := s.service.Reorder(ctx, request.ReorderMovieList{ListID: 10, UserID: 1, MovieIDs: []uint{8}})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.ErrorContains(t, err, "movie 8 is not on the list")
+	s.l.AssertNotCalled(t, "Reorder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddEditor_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByUsername", ctx, "carol").Return(&domain.User{Model: gorm.Model{ID: 3}, Username: "carol"}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.l.On("AddEditor", ctx, uint(10), uint(3)).Return(true, nil).Once()
+
+	err := s.service.AddEditor(ctx, request.AddMovieListEditor{ListID: 10, UserID: 1, Username: "carol"})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestAddEditor_Error_Owner() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByUsername", ctx, "alice").Return(&domain.User{Model: gorm.Model{ID: 1}, Username: "alice"}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+
+	err := s.service.AddEditor(ctx, request.AddMovieListEditor{ListID: 10, UserID: 1, Username: "alice"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.l.AssertNotCalled(t, "AddEditor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddEditor_Error_Editors_Full() {
+	t := s.T()
+	ctx := context.TODO()
+
+	list := s.list(domain.MovieListVisibilityPrivate)
+	list.Editors = make([]domain.User, maxMovieListEditors)
+	s.u.On("GetByUsername", ctx, "carol").Return(&domain.User{Model: gorm.Model{ID: 3}, Username: "carol"}, nil).Once()
+	s.l.On("Lock", ctx, uint(10)).Return(nil).Once()
+	s.l.On("Get", ctx, uint(10)).Return(list, nil).Once()
+
+	err := s.service.AddEditor(ctx, request.AddMovieListEditor{ListID: 10, UserID: 1, Username: "carol"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.l.AssertNotCalled(t, "AddEditor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestAddEditor_Error_Lock() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Lock", ctx, uint(10)).Return(errors.New("there is an error")).Once()
+
+	err := s.service.AddEditor(ctx, request.AddMovieListEditor{ListID: 10, UserID: 1, Username: "carol"})
+
+	assert.ErrorContains(t, err, "failed to lock list")
+	s.l.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func (s *MovieListServiceTest) TestRemoveEditor_Self() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Get", ctx, uint(10)).Return(s.list(domain.MovieListVisibilityPrivate), nil).Once()
+	s.u.On("GetByUsername", ctx, "bob").Return(&domain.User{Model: gorm.Model{ID: 2}, Username: "bob"}, nil).Once()
+	s.l.On("RemoveEditor", ctx, uint(10), uint(2)).Return(true, nil).Once()
+
+	err := s.service.RemoveEditor(ctx, request.RemoveMovieListEditor{ListID: 10, UserID: 2, Username: "bob"})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *MovieListServiceTest) TestRemoveEditor_Error_Other_Editor_Forbidden() {
+	t := s.T()
+	ctx := context.TODO()
+
+	list := s.list(domain.MovieListVisibilityPrivate)
+	list.Editors = append(list.Editors, domain.User{Model: gorm.Model{ID: 3}, Username: "carol"})
+	s.l.On("Get", ctx, uint(10)).Return(list, nil).Once()
+	s.u.On("GetByUsername", ctx, "carol").Return(&domain.User{Model: gorm.Model{ID: 3}, Username: "carol"}, nil).Once()
+
+	err := s.service.RemoveEditor(ctx, request.RemoveMovieListEditor{ListID: 10, UserID: 2, Username: "carol"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.l.AssertNotCalled(t, "RemoveEditor", mock.Anything, mock.Anything, mock.Anything)
+}
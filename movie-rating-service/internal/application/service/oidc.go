@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log/slog"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/oidc"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OIDCService logs users in through an OpenID Connect provider with the authorization code flow and
+// PKCE. A user is created at their first login and linked to the provider's subject. Roles that
+// appear in the group mapping follow the user's groups at every login; other roles are left alone.
+type OIDCService interface {
+	// Start begins a login and returns the URL of the provider to redirect the browser to, and the
+	// state the browser has to present again at the callback.
+	Start(ctx context.Context) (url string, state string, err error)
+	// Callback completes a login when the provider redirects back and returns the user to start a
+	// session for.
+	Callback(ctx context.Context, req request.OIDCCallback) (*response.GetUser, error)
+}
+
+type oidcService struct {
+	txManager      db.TxManager
+	userRepository repository.UserRepository
+	roleRepository repository.RoleRepository
+	oidcRepository repository.OIDCRepository
+	provider       oidc.Provider
+	cfg            config.OIDCConfig
+}
+
+func NewOIDCService(txManager db.TxManager, userRepository repository.UserRepository, roleRepository repository.RoleRepository, oidcRepository repository.OIDCRepository, provider oidc.Provider, cfg config.OIDCConfig) OIDCService {
+	return &oidcService{
+		txManager:      txManager,
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+		oidcRepository: oidcRepository,
+		provider:       provider,
+		cfg:            cfg,
+	}
+}
+
+func (s *oidcService) Start(ctx context.Context) (string, string, error) {
+	// Each is 256 bits of randomness; the verifier is a valid RFC 7636 code verifier as it is.
+	values := make([]string, 3)
+	for i := range values {
+		value, err := newRefreshToken()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to generate login state: %w", err)
+		}
+		values[i] = value
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	now := time.Now()
+	err := s.oidcRepository.CreateLogin(ctx, domain.OIDCLogin{State: state, Nonce: nonce, CodeVerifier: verifier, ExpiresAt: now.Add(s.cfg.LoginTTL)})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to store login state: %w", err)
+	}
+	// Logins that never came back are only deleted here.
+	if err = s.oidcRepository.DeleteExpiredLogins(ctx, now); err != nil {
+		return "", "", fmt.Errorf("failed to delete expired logins: %w", err)
+	}
+
+	url, err := s.provider.AuthCodeURL(ctx, state, nonce, codeChallenge(verifier))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build authorization url: %w", err)
+	}
+	return url, state, nil
+}
+
+func (s *oidcService) Callback(ctx context.Context, req request.OIDCCallback) (*response.GetUser, error) {
+	// Without this, an attacker could have a victim's browser complete a login the attacker started,
+	// signing the victim in to the attacker's account.
+	if req.BrowserState == "" || !hmac.Equal([]byte(req.State), []byte(req.BrowserState)) {
+		return nil, fmt.Errorf("%w: the login was not started in this browser", common.ErrBadRequest)
+	}
+	login, err := s.oidcRepository.ConsumeLogin(ctx, req.State)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: unknown or already used login state", common.ErrBadRequest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+	if req.Error != "" {
+		return nil, fmt.Errorf("%w: the identity provider refused the login: %s %s", common.ErrUnauthorized, req.Error, req.ErrorDescription)
+	}
+	if !time.Now().Before(login.ExpiresAt) {
+		return nil, fmt.Errorf("%w: the login has expired, start again", common.ErrBadRequest)
+	}
+
+	claims, err := s.provider.Exchange(ctx, req.Code, login.CodeVerifier)
+	if errors.Is(err, oidc.ErrRejected) {
+		return nil, fmt.Errorf("%w: %w", common.ErrUnauthorized, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if !hmac.Equal([]byte(claims.Nonce), []byte(login.Nonce)) {
+		return nil, fmt.Errorf("%w: id token nonce does not match", common.ErrUnauthorized)
+	}
+
+	var user *domain.User
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userID, err := s.resolve(ctx, claims)
+		if err != nil {
+			return err
+		}
+		if err = s.syncRoles(ctx, userID, claims.Groups); err != nil {
+			return err
+		}
+		if user, err = s.userRepository.GetByID(ctx, userID); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, fmt.Errorf("%w: account is %s", common.ErrForbidden, user.Status)
+	}
+	return user.GetUserResponse(), nil
+}
+
+// resolve returns the user linked to the subject, creating one at the first login.
+func (s *oidcService) resolve(ctx context.Context, claims *oidc.Claims) (uint, error) {
+	now := time.Now()
+	identity, err := s.oidcRepository.GetIdentity(ctx, s.provider.Issuer(), claims.Subject)
+	if err == nil {
+		if err = s.oidcRepository.TouchIdentity(ctx, identity.ID, now); err != nil {
+			return 0, fmt.Errorf("failed to update identity: %w", err)
+		}
+		return identity.UserID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	username, err := s.username(ctx, claims)
+	if err != nil {
+		return 0, err
+	}
+	// An existing account with the same email is not taken over: the provider may not have verified
+	// it, and the account's owner never agreed to the link.
+	role, err := s.roleRepository.GetByName(ctx, domain.RoleUser)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get role: %w", err)
+	}
+	user := domain.User{
+		Username: username,
+		Name:     claims.GivenName,
+		Surname:  claims.FamilyName,
+		Email:    claims.Email,
+		Roles:    []domain.Role{*role},
+	}
+	if claims.EmailVerified && claims.Email != "" {
+		user.EmailVerifiedAt = &now
+	}
+	created, err := s.userRepository.Create(ctx, user)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+	err = s.oidcRepository.CreateIdentity(ctx, domain.UserIdentity{UserID: created.ID, Issuer: s.provider.Issuer(), Subject: claims.Subject, LastLoginAt: &now})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	slog.Info("User provisioned from identity provider", "user_id", created.ID, "username", username)
+	return created.ID, nil
+}
+
+// username prefers the provider's username, then the local part of the email. When it is taken, a
+// suffix derived from the subject is added, and then a counter until the name is free.
+func (s *oidcService) username(ctx context.Context, claims *oidc.Claims) (string, error) {
+	username := claims.PreferredUsername
+	if username == "" {
+		username, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if username == "" {
+		username = "user"
+	}
+
+	sum := sha256.Sum256([]byte(s.provider.Issuer() + "|" + claims.Subject))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	for i := 0; ; i++ {
+		candidate := username
+		if i == 1 {
+			candidate += "-" + suffix
+		} else if i > 1 {
+			candidate += fmt.Sprintf("-%s-%d", suffix, i)
+		}
+		_, err := s.userRepository.GetByUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+}
+
+// syncRoles grants and revokes the mapped roles to match the groups. Like an admin revoking a role,
+// revoking one ends the user's access tokens.
+func (s *oidcService) syncRoles(ctx context.Context, userID uint, groups []string) error {
+	if len(s.cfg.GroupRoles) == 0 {
+		return nil
+	}
+
+	managed := make([]string, 0, len(s.cfg.GroupRoles))
+	wanted := make([]string, 0)
+	for group, role := range s.cfg.GroupRoles {
+		managed = append(managed, role)
+		if slices.Contains(groups, group) {
+			wanted = append(wanted, role)
+		}
+	}
+	roles, err := s.roleRepository.GetByNames(ctx, managed)
+	if err != nil {
+		return fmt.Errorf("failed to get roles: %w", err)
+	}
+	for _, name := range managed {
+		if !slices.ContainsFunc(roles, func(role domain.Role) bool { return role.Name == name }) {
+			slog.Warn("OIDC_GROUP_ROLES maps to a role that does not exist", "role", name)
+		}
+	}
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	held := user.RoleNames()
+	revoked := false
+	for _, role := range roles {
+		switch {
+		case slices.Contains(wanted, role.Name) && !slices.Contains(held, role.Name):
+			if err = s.userRepository.AddRole(ctx, userID, role.ID); err != nil {
+				return fmt.Errorf("failed to grant role: %w", err)
+			}
+		case !slices.Contains(wanted, role.Name) && slices.Contains(held, role.Name):
+			if err = s.userRepository.RemoveRole(ctx, userID, role.ID); err != nil {
+				return fmt.Errorf("failed to revoke role: %w", err)
+			}
+			revoked = true
+		}
+	}
+	if revoked {
+		if err = s.userRepository.RevokeTokens(ctx, userID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+	}
+	return nil
+}
+
+// codeChallenge is the S256 PKCE code challenge of a verifier (RFC 7636 4.2).
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
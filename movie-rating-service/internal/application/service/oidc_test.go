@@ -0,0 +1,270 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/oidc"
+	"movie-rating-service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://idp.example.com"
+
+type OIDCServiceTest struct {
+	suite.Suite
+	service *oidcService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	r       *mocks.RoleRepository
+	o       *mocks.OIDCRepository
+	p       *mocks.Provider
+	login   *domain.OIDCLogin
+	claims  *oidc.Claims
+	roles   []domain.Role
+}
+
+func (s *OIDCServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.r = new(mocks.RoleRepository)
+	s.o = new(mocks.OIDCRepository)
+	s.p = new(mocks.Provider)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	s.p.On("Issuer").Return(testIssuer).Maybe()
+
+	s.service = NewOIDCService(s.tx, s.u, s.r, s.o, s.p, config.OIDCConfig{
+		GroupRoles: map[string]string{"movie-admins": domain.RoleAdmin, "movie-curators": domain.RoleCurator},
+		LoginTTL:   10 * time.Minute,
+	}).(*oidcService)
+
+	s.login = &domain.OIDCLogin{State: "state", Nonce: "nonce", CodeVerifier: "verifier", ExpiresAt: time.Now().Add(time.Minute)}
+	s.claims = &oidc.Claims{Subject: "sub", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane", GivenName: "Jane", FamilyName: "Doe", Groups: []string{"movie-admins"}, Nonce: "nonce"}
+	s.roles = []domain.Role{{ID: 1, Name: domain.RoleAdmin}, {ID: 3, Name: domain.RoleCurator}}
+}
+
+func Test_RunOIDCServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OIDCServiceTest))
+}
+
+func (s *OIDCServiceTest) TestStart_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var login domain.OIDCLogin
+	s.o.On("CreateLogin", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		login = args.Get(1).(domain.OIDCLogin)
+	}).Return(nil).Once()
+	s.o.On("DeleteExpiredLogins", ctx, mock.Anything).Return(nil).Once()
+	s.p.On("AuthCodeURL", ctx, mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, state, nonce, challenge string) (string, error) {
+		return testIssuer + "/authorize?state=" + state + "&nonce=" + nonce + "&code_challenge=" + challenge, nil
+	}).Once()
+
+	url, state, err := s.service.Start(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, login.State, state)
+	assert.Equal(t, testIssuer+"/authorize?state="+login.State+"&nonce="+login.Nonce+"&code_challenge="+codeChallenge(login.CodeVerifier), url)
+	assert.NotEqual(t, login.State, login.Nonce)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), login.ExpiresAt, time.Minute)
+}
+
+func (s *OIDCServiceTest) TestCodeChallenge_RFC7636_Vector() {
+	// RFC 7636 appendix B.
+	assert.Equal(s.T(), "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
+
+func (s *OIDCServiceTest) TestCallback_Provisions_User() {
+	t := s.T()
+	ctx := context.TODO()
+
+	userRole := &domain.Role{ID: 4, Name: domain.RoleUser}
+	var created domain.User
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+	s.p.On("Exchange", ctx, "code", "verifier").Return(s.claims, nil).Once()
+	s.o.On("GetIdentity", ctx, testIssuer, "sub").Return(nil, gorm.ErrRecordNotFound).Once()
+	s.u.On("GetByUsername", ctx, "jane").Return(nil, gorm.ErrRecordNotFound).Once()
+	s.r.On("GetByName", ctx, domain.RoleUser).Return(userRole, nil).Once()
+	s.u.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(domain.User)
+	}).Return(&domain.User{Model: gorm.Model{ID: 9}}, nil).Once()
+	s.o.On("CreateIdentity", ctx, mock.MatchedBy(func(identity domain.UserIdentity) bool {
+		return identity.UserID == 9 && identity.Issuer == testIssuer && identity.Subject == "sub"
+	})).Return(nil).Once()
+	s.r.On("GetByNames", ctx, mock.Anything).Return(s.roles, nil).Once()
+	provisioned := &domain.User{Model: gorm.Model{ID: 9}, Username: "jane", Roles: []domain.Role{*userRole}}
+	s.u.On("GetByID", ctx, uint(9)).Return(provisioned, nil).Once()
+	s.u.On("AddRole", ctx, uint(9), uint(1)).Return(nil).Once()
+	s.u.On("GetByID", ctx, uint(9)).Return(&domain.User{Model: gorm.Model{ID: 9}, Username: "jane", Roles: []domain.Role{*userRole, s.roles[0]}}, nil).Once()
+
+	res, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), res.ID)
+	assert.ElementsMatch(t, []string{domain.RoleUser, domain.RoleAdmin}, res.Roles)
+	assert.Equal(t, "jane", created.Username)
+	assert.Equal(t, "Jane", created.Name)
+	assert.Empty(t, created.Password)
+	assert.NotNil(t, created.EmailVerifiedAt)
+	s.u.AssertExpectations(t)
+	s.o.AssertExpectations(t)
+	s.u.AssertNotCalled(t, "RevokeTokens", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Existing_User_Loses_Mapped_Role() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.claims.Groups = []string{"movie-curators"}
+	user := &domain.User{Model: gorm.Model{ID: 9}, Username: "jane", Roles: []domain.Role{{ID: 4, Name: domain.RoleUser}, s.roles[0]}}
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+	s.p.On("Exchange", ctx, "code", "verifier").Return(s.claims, nil).Once()
+	s.o.On("GetIdentity", ctx, testIssuer, "sub").Return(&domain.UserIdentity{ID: 2, UserID: 9}, nil).Once()
+	s.o.On("TouchIdentity", ctx, uint(2), mock.Anything).Return(nil).Once()
+	s.r.On("GetByNames", ctx, mock.Anything).Return(s.roles, nil).Once()
+	s.u.On("GetByID", ctx, uint(9)).Return(user, nil).Twice()
+	s.u.On("AddRole", ctx, uint(9), uint(3)).Return(nil).Once()
+	s.u.On("RemoveRole", ctx, uint(9), uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(9), mock.Anything).Return(nil).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.NoError(t, err)
+	s.u.AssertExpectations(t)
+	s.u.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestUsername_Taken() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByUsername", ctx, "jane").Return(&domain.User{Model: gorm.Model{ID: 1}}, nil).Once()
+	s.u.On("GetByUsername", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	username, err := s.service.username(ctx, s.claims)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^jane-[0-9a-f]{8}$`, username)
+}
+
+func (s *OIDCServiceTest) TestUsername_Suffix_Taken() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByUsername", ctx, "jane").Return(&domain.User{Model: gorm.Model{ID: 1}}, nil).Once()
+	s.u.On("GetByUsername", ctx, mock.MatchedBy(func(username string) bool {
+		return strings.HasPrefix(username, "jane-") && len(username) == len("jane-")+8
+	})).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil).Once()
+	s.u.On("GetByUsername", ctx, mock.Anything).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	username, err := s.service.username(ctx, s.claims)
+
+	assert.NoError(t, err)
+	assert.Regexp(t, `^jane-[0-9a-f]{8}-2$`, username)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Other_Browser() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The state comes from a login started elsewhere, e.g. an attacker's, without the cookie.
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.o.AssertNotCalled(t, "ConsumeLogin", mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Account_Banned() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.claims.Groups = nil
+	user := &domain.User{Model: gorm.Model{ID: 9}, Status: domain.UserStatusBanned}
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+	s.p.On("Exchange", ctx, "code", "verifier").Return(s.claims, nil).Once()
+	s.o.On("GetIdentity", ctx, testIssuer, "sub").Return(&domain.UserIdentity{ID: 2, UserID: 9}, nil).Once()
+	s.o.On("TouchIdentity", ctx, uint(2), mock.Anything).Return(nil).Once()
+	s.r.On("GetByNames", ctx, mock.Anything).Return(s.roles, nil).Once()
+	s.u.On("GetByID", ctx, uint(9)).Return(user, nil).Twice()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Nonce_Mismatch() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.claims.Nonce = "replayed"
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+	s.p.On("Exchange", ctx, "code", "verifier").Return(s.claims, nil).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.o.AssertNotCalled(t, "GetIdentity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Unknown_State() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.o.On("ConsumeLogin", ctx, "state").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.p.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Expired() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.login.ExpiresAt = time.Now().Add(-time.Second)
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.p.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Provider_Refused() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Error: "access_denied", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.p.AssertNotCalled(t, "Exchange", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *OIDCServiceTest) TestCallback_Error_Code_Rejected() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.o.On("ConsumeLogin", ctx, "state").Return(s.login, nil).Once()
+	s.p.On("Exchange", ctx, "code", "verifier").Return(nil, errors.Join(oidc.ErrRejected, errors.New("invalid_grant"))).Once()
+
+	_, err := s.service.Callback(ctx, request.OIDCCallback{State: "state", Code: "code", BrowserState: "state"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"strconv"
+)
+
+const defaultPageLimit = 20
+
+// Cursors are opaque to clients; today they only carry the offset of the next page,
+// which lets us switch to keyset pagination later without changing the API.
+func decodePage(p request.Pagination) (limit, offset int, err error) {
+	limit = p.Limit
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	if p.Cursor == "" {
+		return limit, 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid cursor", common.ErrBadRequest)
+	}
+	offset, err = strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, 0, fmt.Errorf("%w: invalid cursor", common.ErrBadRequest)
+	}
+	return limit, offset, nil
+}
+
+func encodePage(limit, offset int, total int64) response.Pagination {
+	page := response.Pagination{Total: total, Limit: limit}
+	next := offset + limit
+	if int64(next) < total {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(next)))
+	}
+	return page
+}
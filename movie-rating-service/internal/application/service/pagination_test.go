@@ -0,0 +1,78 @@
+//go:build unit_test
+
+package service
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"testing"
+)
+
+type PaginationTest struct {
+	suite.Suite
+}
+
+func Test_RunPaginationTestSuite(t *testing.T) {
+	suite.Run(t, new(PaginationTest))
+}
+
+func (p *PaginationTest) TestDecodePage_Defaults() {
+	t := p.T()
+
+	limit, offset, err := decodePage(request.Pagination{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPageLimit, limit)
+	assert.Zero(t, offset)
+}
+
+func (p *PaginationTest) TestDecodePage_Round_Trip() {
+	t := p.T()
+
+	page := encodePage(10, 20, 100)
+	limit, offset, err := decodePage(request.Pagination{Limit: 10, Cursor: page.NextCursor})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, limit)
+	assert.Equal(t, 30, offset)
+}
+
+func (p *PaginationTest) TestDecodePage_Error_Invalid_Cursor() {
+	t := p.T()
+
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("abc")),
+		base64.RawURLEncoding.EncodeToString([]byte("-5")),
+	} {
+		_, _, err := decodePage(request.Pagination{Cursor: cursor})
+		assert.ErrorIs(t, err, common.ErrBadRequest, cursor)
+	}
+}
+
+func (p *PaginationTest) TestEncodePage() {
+	t := p.T()
+
+	tests := []struct {
+		name     string
+		limit    int
+		offset   int
+		total    int64
+		wantNext bool
+	}{
+		{name: "more pages", limit: 10, offset: 0, total: 25, wantNext: true},
+		{name: "last full page", limit: 10, offset: 10, total: 20, wantNext: false},
+		{name: "last partial page", limit: 10, offset: 20, total: 25, wantNext: false},
+		{name: "empty", limit: 10, offset: 0, total: 0, wantNext: false},
+	}
+	for _, tt := range tests {
+		page := encodePage(tt.limit, tt.offset, tt.total)
+
+		assert.Equal(t, tt.total, page.Total, tt.name)
+		assert.Equal(t, tt.limit, page.Limit, tt.name)
+		assert.Equal(t, tt.wantNext, page.NextCursor != "", tt.name)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"time"
+)
+
+// PrivacyService hands users their data and erases it on request. Both are recorded in the audit log.
+type PrivacyService interface {
+	Export(ctx context.Context, req request.ExportUserData) (*response.UserExport, error)
+	// EraseAccount erases the caller's own data after checking their password.
+	EraseAccount(ctx context.Context, req request.EraseAccount) (*response.Erasure, error)
+	// Erase permanently deletes a user's personal data, ratings, watchlist and lists, recomputes the
+	// aggregates of the movies they rated, and deletes the user. It also works on accounts that were already deleted.
+	Erase(ctx context.Context, req request.EraseUser) (*response.Erasure, error)
+}
+
+type privacyService struct {
+	txManager            db.TxManager
+	userRepository       repository.UserRepository
+	ratingRepository     repository.RatingRepository
+	movieRepository      repository.MovieRepository
+	movieStatsRepository repository.MovieStatsRepository
+	auditRepository      repository.AuditRepository
+	watchlistRepository  repository.WatchlistRepository
+	movieListRepository  repository.MovieListRepository
+	hasher               password.Hasher
+}
+
+func NewPrivacyService(txManager db.TxManager, userRepository repository.UserRepository, ratingRepository repository.RatingRepository, movieRepository repository.MovieRepository, movieStatsRepository repository.MovieStatsRepository, auditRepository repository.AuditRepository, watchlistRepository repository.WatchlistRepository, movieListRepository repository.MovieListRepository, hasher password.Hasher) PrivacyService {
+	return &privacyService{
+		txManager:            txManager,
+		userRepository:       userRepository,
+		ratingRepository:     ratingRepository,
+		movieRepository:      movieRepository,
+		movieStatsRepository: movieStatsRepository,
+		auditRepository:      auditRepository,
+		watchlistRepository:  watchlistRepository,
+		movieListRepository:  movieListRepository,
+		hasher:               hasher,
+	}
+}
+
+func (s *privacyService) Export(ctx context.Context, req request.ExportUserData) (*response.UserExport, error) {
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	ratings, err := s.ratingRepository.GetByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user's ratings: %w", err)
+	}
+	votes, err := s.ratingRepository.GetHelpfulVotesByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user's helpful votes: %w", err)
+	}
+	watchlist, err := s.watchlistRepository.GetByUserID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user's watchlist: %w", err)
+	}
+	lists, err := s.movieListRepository.GetByOwnerID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user's lists: %w", err)
+	}
+
+	format := req.Format
+	if format == "" {
+		format = "json"
+	}
+	if err = s.audit(ctx, req.UserID, domain.AuditActionUserExported, req.UserID, map[string]interface{}{"format": format}); err != nil {
+		return nil, err
+	}
+
+	resp := &response.UserExport{
+		ExportedAt:       time.Now().UTC(),
+		AccountCreatedAt: user.CreatedAt,
+		Profile:          *user.GetUserResponse(),
+		Ratings:          make([]response.ExportedRating, len(ratings)),
+		HelpfulVotes:     make([]response.ExportedHelpfulVote, len(votes)),
+		Watchlist:        make([]response.ExportedWatchlistItem, len(watchlist)),
+		Lists:            make([]response.ExportedMovieList, len(lists)),
+	}
+	for i, rating := range ratings {
+		resp.Ratings[i] = *rating.ExportRatingResponse()
+	}
+	for i, vote := range votes {
+		resp.HelpfulVotes[i] = *vote.ExportHelpfulVoteResponse()
+	}
+	for i, item := range watchlist {
+		resp.Watchlist[i] = *item.ExportWatchlistItemResponse()
+	}
+	for i, list := range lists {
+		resp.Lists[i] = *list.ExportMovieListResponse()
+	}
+	return resp, nil
+}
+
+func (s *privacyService) EraseAccount(ctx context.Context, req request.EraseAccount) (*response.Erasure, error) {
+	if err := verifyPassword(ctx, s.userRepository, s.hasher, req.UserID, req.Password); err != nil {
+		return nil, err
+	}
+	return s.erase(ctx, req.UserID, req.UserID)
+}
+
+func (s *privacyService) Erase(ctx context.Context, req request.EraseUser) (*response.Erasure, error) {
+	if req.ActorID == req.UserID {
+		return nil, fmt.Errorf("%w: erase your own account with POST /user/me/erase", common.ErrBadRequest)
+	}
+	return s.erase(ctx, req.ActorID, req.UserID)
+}
+
+func (s *privacyService) erase(ctx context.Context, actorID, userID uint) (*response.Erasure, error) {
+	resp := &response.Erasure{UserID: userID}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		ratings, err := s.ratingRepository.GetByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get user's ratings: %w", err)
+		}
+
+		resp.RatingsErased, err = s.ratingRepository.EraseByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to erase ratings: %w", err)
+		}
+
+		movieIDs := make([]uint, 0, len(ratings))
+		for _, rating := range ratings {
+			if err = s.movieStatsRepository.RemoveScore(ctx, rating.MovieID, rating.Score, rating.CreatedAt); err != nil {
+				return fmt.Errorf("failed to delete rating stats: %w", err)
+			}
+			movieIDs = append(movieIDs, rating.MovieID)
+		}
+		// Sorted, so that concurrent erasures lock the movie rows in the same order.
+		slices.Sort(movieIDs)
+		movieIDs = slices.Compact(movieIDs)
+		for _, movieID := range movieIDs {
+			if err = s.movieRepository.RecomputeRatingAggregate(ctx, movieID); err != nil {
+				return fmt.Errorf("failed to recompute rating of movie %d: %w", movieID, err)
+			}
+		}
+		resp.MoviesRecomputed = len(movieIDs)
+
+		if err = clearWatchlist(ctx, s.watchlistRepository, s.movieRepository, userID); err != nil {
+			return err
+		}
+
+		if err = s.userRepository.Erase(ctx, userID); err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+
+		return s.audit(ctx, actorID, domain.AuditActionUserErased, userID, map[string]interface{}{
+			"ratings_erased":    resp.RatingsErased,
+			"movies_recomputed": resp.MoviesRecomputed,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *privacyService) audit(ctx context.Context, actorID uint, action string, subjectID uint, details map[string]interface{}) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	err = s.auditRepository.Create(ctx, domain.AuditLog{
+		ActorID:   actorID,
+		Action:    action,
+		SubjectID: subjectID,
+		Details:   string(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
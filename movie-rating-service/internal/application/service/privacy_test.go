@@ -0,0 +1,233 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/mocks"
+	"testing"
+	"time"
+)
+
+type PrivacyServiceTest struct {
+	suite.Suite
+	service *privacyService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	r       *mocks.RatingRepository
+	m       *mocks.MovieRepository
+	ms      *mocks.MovieStatsRepository
+	a       *mocks.AuditRepository
+	w       *mocks.WatchlistRepository
+	l       *mocks.MovieListRepository
+	audits  []domain.AuditLog
+}
+
+func (s *PrivacyServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.r = new(mocks.RatingRepository)
+	s.m = new(mocks.MovieRepository)
+	s.ms = new(mocks.MovieStatsRepository)
+	s.a = new(mocks.AuditRepository)
+	s.w = new(mocks.WatchlistRepository)
+	s.l = new(mocks.MovieListRepository)
+	s.audits = nil
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+	s.a.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		s.audits = append(s.audits, args.Get(1).(domain.AuditLog))
+	}).Return(nil).Maybe()
+
+	s.service = &privacyService{
+		txManager:            s.tx,
+		userRepository:       s.u,
+		ratingRepository:     s.r,
+		movieRepository:      s.m,
+		movieStatsRepository: s.ms,
+		auditRepository:      s.a,
+		watchlistRepository:  s.w,
+		movieListRepository:  s.l,
+		hasher:               password.NewHasher(testPasswordConfig),
+	}
+}
+
+func Test_RunPrivacyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PrivacyServiceTest))
+}
+
+func (s *PrivacyServiceTest) TestExport_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(&domain.User{Model: gorm.Model{ID: 1}, Username: "alice", Email: "alice@example.com"}, nil).Once()
+	s.r.On("GetByUserID", ctx, uint(1)).Return([]domain.Rating{
+		{Model: gorm.Model{ID: 7}, MovieID: 3, Score: 4, Review: "Great", Movie: domain.Movie{Title: "Heat"}},
+	}, nil).Once()
+	s.r.On("GetHelpfulVotesByUserID", ctx, uint(1)).Return([]domain.RatingHelpfulVote{{RatingID: 9}}, nil).Once()
+	s.w.On("GetByUserID", ctx, uint(1)).Return([]domain.WatchlistItem{
+		{MovieID: 4, Position: 0, Movie: domain.Movie{Title: "Ronin"}},
+	}, nil).Once()
+	s.l.On("GetByOwnerID", ctx, uint(1)).Return([]domain.MovieList{
+		{ID: 2, Name: "Heists", Visibility: domain.MovieListVisibilityPublic, Items: []domain.MovieListItem{
+			{MovieID: 3, Position: 0, Note: "The bank job", Movie: domain.Movie{Title: "Heat"}},
+		}},
+	}, nil).Once()
+
+	res, err := s.service.Export(ctx, request.ExportUserData{UserID: 1, Format: "zip"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", res.Profile.Email)
+	assert.Len(t, res.Ratings, 1)
+	assert.Equal(t, "Heat", res.Ratings[0].MovieTitle)
+	assert.Equal(t, "Great", res.Ratings[0].Review)
+	assert.Len(t, res.HelpfulVotes, 1)
+	assert.Equal(t, uint(9), res.HelpfulVotes[0].RatingID)
+	assert.Len(t, res.Watchlist, 1)
+	assert.Equal(t, "Ronin", res.Watchlist[0].MovieTitle)
+	assert.Len(t, res.Lists, 1)
+	assert.Equal(t, "Heists", res.Lists[0].Name)
+	assert.Len(t, res.Lists[0].Items, 1)
+	assert.Equal(t, "Heat", res.Lists[0].Items[0].MovieTitle)
+	assert.Equal(t, "The bank job", res.Lists[0].Items[0].Note)
+
+	assert.Len(t, s.audits, 1)
+	assert.Equal(t, domain.AuditActionUserExported, s.audits[0].Action)
+	assert.JSONEq(t, `{"format":"zip"}`, s.audits[0].Details)
+}
+
+func (s *PrivacyServiceTest) TestExport_Error_User_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := s.service.Export(ctx, request.ExportUserData{UserID: 1})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Empty(t, s.audits)
+}
+
+func (s *PrivacyServiceTest) TestErase_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	ratedAt := time.Now()
+	s.r.On("GetByUserID", ctx, uint(2)).Return([]domain.Rating{
+		{Model: gorm.Model{ID: 1, CreatedAt: ratedAt}, MovieID: 5, Score: 4},
+		{Model: gorm.Model{ID: 2, CreatedAt: ratedAt}, MovieID: 3, Score: 2},
+	}, nil).Once()
+	s.r.On("EraseByUserID", ctx, uint(2)).Return(int64(3), nil).Once()
+	s.ms.On("RemoveScore", ctx, uint(5), 4.0, ratedAt).Return(nil).Once()
+	s.ms.On("RemoveScore", ctx, uint(3), 2.0, ratedAt).Return(nil).Once()
+	s.m.On("RecomputeRatingAggregate", ctx, uint(3)).Return(nil).Once()
+	s.m.On("RecomputeRatingAggregate", ctx, uint(5)).Return(nil).Once()
+	s.w.On("RemoveAll", ctx, uint(2)).Return([]uint{8}, nil).Once()
+	s.m.On("UpdateWatchlistCount", ctx, uint(8), -1).Return(nil).Once()
+	s.u.On("Erase", ctx, uint(2)).Return(nil).Once()
+
+	res, err := s.service.Erase(ctx, request.EraseUser{ActorID: 1, UserID: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), res.RatingsErased)
+	assert.Equal(t, 2, res.MoviesRecomputed)
+	s.r.AssertExpectations(t)
+	s.ms.AssertExpectations(t)
+	s.m.AssertExpectations(t)
+	s.w.AssertExpectations(t)
+	s.u.AssertExpectations(t)
+
+	assert.Len(t, s.audits, 1)
+	assert.Equal(t, domain.AuditActionUserErased, s.audits[0].Action)
+	assert.Equal(t, uint(1), s.audits[0].ActorID)
+	assert.Equal(t, uint(2), s.audits[0].SubjectID)
+	var details map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(s.audits[0].Details), &details))
+	assert.Equal(t, 3.0, details["ratings_erased"])
+}
+
+func (s *PrivacyServiceTest) TestErase_Error_Self() {
+	t := s.T()
+	ctx := context.TODO()
+
+	_, err := s.service.Erase(ctx, request.EraseUser{ActorID: 1, UserID: 1})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.r.AssertNotCalled(t, "EraseByUserID", mock.Anything, mock.Anything)
+}
+
+func (s *PrivacyServiceTest) TestErase_Error_User_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.r.On("GetByUserID", ctx, uint(2)).Return([]domain.Rating{}, nil).Once()
+	s.r.On("EraseByUserID", ctx, uint(2)).Return(int64(0), nil).Once()
+	s.w.On("RemoveAll", ctx, uint(2)).Return([]uint{}, nil).Once()
+	s.u.On("Erase", ctx, uint(2)).Return(gorm.ErrRecordNotFound).Once()
+
+	_, err := s.service.Erase(ctx, request.EraseUser{ActorID: 1, UserID: 2})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Empty(t, s.audits)
+}
+
+func (s *PrivacyServiceTest) TestErase_Error_Recompute_Failed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.r.On("GetByUserID", ctx, uint(2)).Return([]domain.Rating{{MovieID: 5, Score: 4}}, nil).Once()
+	s.r.On("EraseByUserID", ctx, uint(2)).Return(int64(1), nil).Once()
+	s.ms.On("RemoveScore", ctx, uint(5), 4.0, mock.Anything).Return(nil).Once()
+	s.m.On("RecomputeRatingAggregate", ctx, uint(5)).Return(errors.New("there is an error")).Once()
+
+	_, err := s.service.Erase(ctx, request.EraseUser{ActorID: 1, UserID: 2})
+
+	assert.ErrorContains(t, err, "failed to recompute rating of movie 5")
+	s.u.AssertNotCalled(t, "Erase", mock.Anything, mock.Anything)
+}
+
+func (s *PrivacyServiceTest) TestEraseAccount_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.u.On("GetByID", ctx, uint(1)).Return(&domain.User{Model: gorm.Model{ID: 1}, Password: string(hash)}, nil).Once()
+	s.r.On("GetByUserID", ctx, uint(1)).Return([]domain.Rating{}, nil).Once()
+	s.r.On("EraseByUserID", ctx, uint(1)).Return(int64(0), nil).Once()
+	s.w.On("RemoveAll", ctx, uint(1)).Return([]uint{}, nil).Once()
+	s.u.On("Erase", ctx, uint(1)).Return(nil).Once()
+
+	_, err = s.service.EraseAccount(ctx, request.EraseAccount{UserID: 1, Password: "password"})
+
+	assert.NoError(t, err)
+	assert.Len(t, s.audits, 1)
+	assert.Equal(t, uint(1), s.audits[0].ActorID)
+}
+
+func (s *PrivacyServiceTest) TestEraseAccount_Error_Wrong_Password() {
+	t := s.T()
+	ctx := context.TODO()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.u.On("GetByID", ctx, uint(1)).Return(&domain.User{Model: gorm.Model{ID: 1}, Password: string(hash)}, nil).Once()
+
+	_, err = s.service.EraseAccount(ctx, request.EraseAccount{UserID: 1, Password: "wrong"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.u.AssertNotCalled(t, "Erase", mock.Anything, mock.Anything)
+}
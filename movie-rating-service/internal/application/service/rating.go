@@ -5,54 +5,66 @@ import (
 	"fmt"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
 	"movie-rating-service/internal/domain"
 	"movie-rating-service/internal/infrastructure/db"
 	"movie-rating-service/internal/infrastructure/repository"
 )
 
 type RatingService interface {
+	// Create also takes the movie off the user's watchlist.
 	Create(ctx context.Context, req request.CreateRating) (*response.CreateRating, error)
 	GetRatingsByUserID(ctx context.Context, req request.GetUserRatings) (*response.GetUserRatings, error)
 	Update(ctx context.Context, req request.UpdateRating) (*response.UpdateRating, error)
 	Delete(ctx context.Context, req request.DeleteRating) error
+	// Moderate removes any user's rating.
+	Moderate(ctx context.Context, req request.ModerateRating) error
+	ListByMovieID(ctx context.Context, req request.ListMovieRatings) (*response.ListMovieRatings, error)
+	VoteHelpful(ctx context.Context, req request.VoteRatingHelpful) error
 }
 
 type ratingService struct {
-	ratingRepository repository.RatingRepository
-	movieRepository  repository.MovieRepository
+	txManager            db.TxManager
+	ratingRepository     repository.RatingRepository
+	movieRepository      repository.MovieRepository
+	movieStatsRepository repository.MovieStatsRepository
+	watchlistRepository  repository.WatchlistRepository
 }
 
-func NewRatingService(ratingRepository repository.RatingRepository, movieRepository repository.MovieRepository) RatingService {
-	return &ratingService{ratingRepository: ratingRepository, movieRepository: movieRepository}
+func NewRatingService(txManager db.TxManager, ratingRepository repository.RatingRepository, movieRepository repository.MovieRepository, movieStatsRepository repository.MovieStatsRepository, watchlistRepository repository.WatchlistRepository) RatingService {
+	return &ratingService{txManager: txManager, ratingRepository: ratingRepository, movieRepository: movieRepository, movieStatsRepository: movieStatsRepository, watchlistRepository: watchlistRepository}
 }
 
 func (s *ratingService) Create(ctx context.Context, req request.CreateRating) (*response.CreateRating, error) {
-	tx := db.BeginTransaction()
-
-	rating, err := s.ratingRepository.Create(ctx, domain.Rating{
-		UserID:  req.UserID,
-		MovieID: req.MovieID,
-		Score:   req.Score,
-		Review:  req.Review,
-	}, tx)
-	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("failed to rollback rate movie: %w", rollbackErr)
+	var rating *domain.Rating
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		rating, err = s.ratingRepository.Create(ctx, domain.Rating{
+			UserID:  req.UserID,
+			MovieID: req.MovieID,
+			Score:   req.Score,
+			Review:  req.Review,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rate movie: %w", err)
 		}
-		return nil, fmt.Errorf("failed to rate movie: %w", err)
-	}
 
-	err = s.movieRepository.AddRating(ctx, req.MovieID, req.Score, tx)
-	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("failed to rollback add rating: %w", rollbackErr)
+		err = s.movieRepository.AddRating(ctx, req.MovieID, req.Score)
+		if err != nil {
+			return fmt.Errorf("failed to add rating: %w", err)
+		}
+
+		err = s.movieStatsRepository.AddScore(ctx, req.MovieID, req.Score, rating.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add rating stats: %w", err)
 		}
-		return nil, fmt.Errorf("failed to add rating: %w", err)
-	}
 
-	err = tx.Commit().Error
+		// A rated movie has been seen.
+		_, err = removeFromWatchlist(ctx, s.watchlistRepository, s.movieRepository, req.UserID, req.MovieID)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
 	}
 
 	return rating.CreateRatingResponse(), nil
@@ -74,74 +86,139 @@ func (s *ratingService) GetRatingsByUserID(ctx context.Context, req request.GetU
 }
 
 func (s *ratingService) Update(ctx context.Context, req request.UpdateRating) (*response.UpdateRating, error) {
-	rating, err := s.ratingRepository.GetByUserIDAndMovieID(ctx, req.UserID, req.MovieID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user's rating on the selected movie: %w", err)
-	}
-
-	tx := db.BeginTransaction()
+	var rating *domain.Rating
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// The old score is read under the row lock, so a concurrent update cannot make it stale.
+		var err error
+		rating, err = s.ratingRepository.LockByUserIDAndMovieID(ctx, req.UserID, req.MovieID)
+		if err != nil {
+			return fmt.Errorf("failed to get user's rating on the selected movie: %w", err)
+		}
 
-	err = s.ratingRepository.Update(ctx, domain.Rating{
-		UserID:  req.UserID,
-		MovieID: req.MovieID,
-		Score:   req.Score,
-		Review:  req.Review,
-	}, tx)
-	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("ailed to rollback rate movie: %w", rollbackErr)
+		err = s.ratingRepository.Update(ctx, domain.Rating{
+			UserID:  req.UserID,
+			MovieID: req.MovieID,
+			Score:   req.Score,
+			Review:  req.Review,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rate movie: %w", err)
 		}
-		return nil, fmt.Errorf("failed to rate movie: %w", err)
-	}
 
-	err = s.movieRepository.UpdateRating(ctx, req.MovieID, rating.Score, req.Score, tx)
-	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return nil, fmt.Errorf("failed to rollback update rating: %w", rollbackErr)
+		err = s.movieRepository.UpdateRating(ctx, req.MovieID, rating.Score, req.Score)
+		if err != nil {
+			return fmt.Errorf("failed to update rating: %w", err)
 		}
-		return nil, fmt.Errorf("failed to update rating: %w", err)
-	}
 
-	err = tx.Commit().Error
+		err = s.movieStatsRepository.ReplaceScore(ctx, req.MovieID, rating.Score, req.Score, rating.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update rating stats: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
 	}
 
 	return rating.UpdateMovieResponse(), nil
 }
 
 func (s *ratingService) Delete(ctx context.Context, req request.DeleteRating) error {
-	rating, err := s.ratingRepository.GetByUserIDAndMovieID(ctx, req.UserID, req.MovieID)
-	if err != nil {
-		return fmt.Errorf("failed to get user's rating on the selected movie: %w", err)
-	}
+	return s.remove(ctx, func(ctx context.Context) (*domain.Rating, error) {
+		rating, err := s.ratingRepository.LockByUserIDAndMovieID(ctx, req.UserID, req.MovieID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user's rating on the selected movie: %w", err)
+		}
+		return rating, nil
+	})
+}
+
+func (s *ratingService) Moderate(ctx context.Context, req request.ModerateRating) error {
+	return s.remove(ctx, func(ctx context.Context) (*domain.Rating, error) {
+		rating, err := s.ratingRepository.LockByID(ctx, req.RatingID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rating: %w", err)
+		}
+		return rating, nil
+	})
+}
 
-	tx := db.BeginTransaction()
+// remove deletes the rating returned by lock and takes its score out of the movie's aggregates. lock
+// reads the rating under its row lock inside the transaction, so the score taken out is the one of the
+// rating deleted, and a rating deleted by two requests at once is taken out only once.
+func (s *ratingService) remove(ctx context.Context, lock func(ctx context.Context) (*domain.Rating, error)) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rating, err := lock(ctx)
+		if err != nil {
+			return err
+		}
 
-	err = s.ratingRepository.Delete(ctx, domain.Rating{
-		UserID:  req.UserID,
-		MovieID: req.MovieID,
-	}, tx)
+		deleted, err := s.ratingRepository.Delete(ctx, rating.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete rating: %w", err)
+		}
+		if deleted == 0 {
+			return nil
+		}
 
-	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return fmt.Errorf("ailed to rollback rate movie: %w", rollbackErr)
+		err = s.movieRepository.DeleteRating(ctx, rating.MovieID, rating.Score)
+		if err != nil {
+			return fmt.Errorf("failed to update rating: %w", err)
 		}
-		return fmt.Errorf("failed to rate movie: %w", err)
+
+		err = s.movieStatsRepository.RemoveScore(ctx, rating.MovieID, rating.Score, rating.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to delete rating stats: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *ratingService) ListByMovieID(ctx context.Context, req request.ListMovieRatings) (*response.ListMovieRatings, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.movieRepository.DeleteRating(ctx, req.MovieID, rating.Score, tx)
+	if _, err = s.movieRepository.Get(ctx, req.MovieID); err != nil {
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	ratings, total, err := s.ratingRepository.ListByMovieID(ctx, req.MovieID, req.Sort, limit, offset)
 	if err != nil {
-		if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
-			return fmt.Errorf("failed to rollback update rating: %w", rollbackErr)
-		}
-		return fmt.Errorf("failed to update rating: %w", err)
+		return nil, fmt.Errorf("failed to get movie's ratings: %w", err)
 	}
 
-	err = tx.Commit().Error
+	resp := &response.ListMovieRatings{Pagination: encodePage(limit, offset, total)}
+	resp.Ratings = make([]response.MovieRating, len(ratings))
+	for i, rating := range ratings {
+		resp.Ratings[i] = *rating.GetMovieRatingResponse()
+	}
+	return resp, nil
+}
+
+func (s *ratingService) VoteHelpful(ctx context.Context, req request.VoteRatingHelpful) error {
+	rating, err := s.ratingRepository.GetByID(ctx, req.RatingID)
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to get rating: %w", err)
+	}
+	if rating.UserID == req.UserID {
+		return fmt.Errorf("%w: cannot vote on your own review", common.ErrBadRequest)
 	}
 
-	return nil
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.ratingRepository.CreateHelpfulVote(ctx, domain.RatingHelpfulVote{
+			RatingID: req.RatingID,
+			UserID:   req.UserID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to vote helpful: %w", err)
+		}
+
+		err = s.ratingRepository.IncrementHelpfulCount(ctx, req.RatingID)
+		if err != nil {
+			return fmt.Errorf("failed to increment helpful count: %w", err)
+		}
+		return nil
+	})
 }
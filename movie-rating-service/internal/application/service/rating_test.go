@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
 	"movie-rating-service/internal/domain"
 	"movie-rating-service/mocks"
 	"testing"
@@ -17,15 +19,26 @@ import (
 type RatingServiceTest struct {
 	suite.Suite
 	service ratingService
+	tx      *mocks.TxManager
 	r       *mocks.RatingRepository
 	m       *mocks.MovieRepository
+	s       *mocks.MovieStatsRepository
+	w       *mocks.WatchlistRepository
 }
 
 func (r *RatingServiceTest) SetupTest() {
+	r.tx = new(mocks.TxManager)
 	r.r = new(mocks.RatingRepository)
 	r.m = new(mocks.MovieRepository)
+	r.s = new(mocks.MovieStatsRepository)
+	r.w = new(mocks.WatchlistRepository)
 
-	r.service = ratingService{ratingRepository: r.r, movieRepository: r.m}
+	// Run the unit of work inline; commit and rollback are the transaction manager's concern.
+	r.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	r.service = ratingService{txManager: r.tx, ratingRepository: r.r, movieRepository: r.m, movieStatsRepository: r.s, watchlistRepository: r.w}
 }
 
 func Test_RunRatingServiceTestSuite(t *testing.T) {
@@ -56,14 +69,18 @@ func (r *RatingServiceTest) TestPromotionService_Create_Success() {
 	})).Return(rating, nil).Once()
 
 	r.m.On("AddRating", ctx, req.MovieID, req.Score).Return(nil).Once()
+	r.s.On("AddScore", ctx, req.MovieID, req.Score, rating.CreatedAt).Return(nil).Once()
+	r.w.On("Remove", ctx, req.UserID, req.MovieID).Return(false, nil).Once()
 
 	result, err := r.service.Create(ctx, req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
+	r.m.AssertNotCalled(t, "UpdateWatchlistCount", mock.Anything, mock.Anything, mock.Anything)
 
 	r.r.AssertExpectations(t)
 	r.m.AssertExpectations(t)
+	r.s.AssertExpectations(t)
 }
 
 func (r *RatingServiceTest) TestPromotionService_Create_Error_Failed_To_Create_Rating() {
@@ -118,11 +135,85 @@ func (r *RatingServiceTest) TestPromotionService_Create_Error_Failed_To_Update_M
 
 	result, err := r.service.Create(ctx, req)
 
-	assert.ErrorContains(t, err, "failed to update rating: there is an error")
+	assert.ErrorContains(t, err, "failed to add rating: there is an error")
+	assert.Nil(t, result)
+
+	r.r.AssertExpectations(t)
+	r.m.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Create_Error_Failed_To_Add_Rating_Stats() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.CreateRating{
+		UserID:  1,
+		MovieID: 42,
+		Score:   4.7,
+		Review:  "good",
+	}
+
+	rating := &domain.Rating{
+		UserID:  req.UserID,
+		MovieID: req.MovieID,
+		Score:   req.Score,
+		Review:  req.Review,
+	}
+
+	r.r.On("Create", ctx, mock.Anything).Return(rating, nil).Once()
+	r.m.On("AddRating", ctx, req.MovieID, req.Score).Return(nil).Once()
+	r.s.On("AddScore", ctx, req.MovieID, req.Score, rating.CreatedAt).Return(errors.New("there is an error")).Once()
+
+	result, err := r.service.Create(ctx, req)
+
+	assert.ErrorContains(t, err, "failed to add rating stats: there is an error")
 	assert.Nil(t, result)
 
+	r.tx.AssertNumberOfCalls(t, "WithinTx", 1)
 	r.r.AssertExpectations(t)
 	r.m.AssertExpectations(t)
+	r.s.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Create_Removes_From_Watchlist() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.CreateRating{UserID: 1, MovieID: 42, Score: 4}
+	rating := &domain.Rating{UserID: req.UserID, MovieID: req.MovieID, Score: req.Score}
+
+	r.r.On("Create", ctx, mock.Anything).Return(rating, nil).Once()
+	r.m.On("AddRating", ctx, req.MovieID, req.Score).Return(nil).Once()
+	r.s.On("AddScore", ctx, req.MovieID, req.Score, rating.CreatedAt).Return(nil).Once()
+	r.w.On("Remove", ctx, req.UserID, req.MovieID).Return(true, nil).Once()
+	r.m.On("UpdateWatchlistCount", ctx, req.MovieID, -1).Return(nil).Once()
+
+	_, err := r.service.Create(ctx, req)
+
+	assert.NoError(t, err)
+	r.w.AssertExpectations(t)
+	r.m.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Create_Error_Failed_To_Remove_From_Watchlist() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.CreateRating{UserID: 1, MovieID: 42, Score: 4}
+	rating := &domain.Rating{UserID: req.UserID, MovieID: req.MovieID, Score: req.Score}
+
+	r.r.On("Create", ctx, mock.Anything).Return(rating, nil).Once()
+	r.m.On("AddRating", ctx, req.MovieID, req.Score).Return(nil).Once()
+	r.s.On("AddScore", ctx, req.MovieID, req.Score, rating.CreatedAt).Return(nil).Once()
+	r.w.On("Remove", ctx, req.UserID, req.MovieID).Return(false, errors.New("there is an error")).Once()
+
+	result, err := r.service.Create(ctx, req)
+
+	assert.ErrorContains(t, err, "failed to remove from watchlist: there is an error")
+	assert.Nil(t, result)
 }
 
 func (r *RatingServiceTest) TestPromotionService_GetRatingsByUserID_Success() {
@@ -176,8 +267,214 @@ func (r *RatingServiceTest) TestPromotionService_GetRatingsByUserID_Error_Failed
 
 	result, err := r.service.GetRatingsByUserID(ctx, req)
 
-	assert.ErrorContains(t, err, "error occurred while getting user's ratings: there is an error")
+	assert.ErrorContains(t, err, "failed to get user's ratings: there is an error")
 	assert.Nil(t, result)
 
 	r.r.AssertExpectations(t)
 }
+
+func (r *RatingServiceTest) TestPromotionService_Update_Success() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.UpdateRating{UserID: 3, MovieID: 42, Score: 4, Review: "Better on a second watch"}
+	rating := &domain.Rating{Model: gorm.Model{ID: 9}, UserID: 3, MovieID: 42, Score: 2}
+
+	r.r.On("LockByUserIDAndMovieID", ctx, req.UserID, req.MovieID).Return(rating, nil).Once()
+	r.r.On("Update", ctx, domain.Rating{UserID: 3, MovieID: 42, Score: 4, Review: "Better on a second watch"}).Return(nil).Once()
+	r.m.On("UpdateRating", ctx, req.MovieID, 2.0, 4.0).Return(nil).Once()
+	r.s.On("ReplaceScore", ctx, req.MovieID, 2.0, 4.0, rating.CreatedAt).Return(nil).Once()
+
+	result, err := r.service.Update(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(9), result.ID)
+
+	r.r.AssertExpectations(t)
+	r.m.AssertExpectations(t)
+	r.s.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Update_Error_Not_Found() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.UpdateRating{UserID: 3, MovieID: 42, Score: 4}
+
+	r.r.On("LockByUserIDAndMovieID", ctx, req.UserID, req.MovieID).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	result, err := r.service.Update(ctx, req)
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, result)
+
+	r.r.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	r.m.AssertNotCalled(t, "UpdateRating", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Delete_Success() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.DeleteRating{UserID: 3, MovieID: 42}
+	rating := &domain.Rating{Model: gorm.Model{ID: 9}, UserID: 3, MovieID: 42, Score: 4.5}
+
+	r.r.On("LockByUserIDAndMovieID", ctx, req.UserID, req.MovieID).Return(rating, nil).Once()
+	r.r.On("Delete", ctx, rating.ID).Return(int64(1), nil).Once()
+	r.m.On("DeleteRating", ctx, rating.MovieID, rating.Score).Return(nil).Once()
+	r.s.On("RemoveScore", ctx, rating.MovieID, rating.Score, rating.CreatedAt).Return(nil).Once()
+
+	err := r.service.Delete(ctx, req)
+
+	assert.NoError(t, err)
+
+	r.r.AssertExpectations(t)
+	r.m.AssertExpectations(t)
+	r.s.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Moderate_Success() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.ModerateRating{RatingID: 9}
+	rating := &domain.Rating{Model: gorm.Model{ID: 9}, UserID: 3, MovieID: 42, Score: 1.5}
+
+	r.r.On("LockByID", ctx, req.RatingID).Return(rating, nil).Once()
+	r.r.On("Delete", ctx, rating.ID).Return(int64(1), nil).Once()
+	r.m.On("DeleteRating", ctx, rating.MovieID, rating.Score).Return(nil).Once()
+	r.s.On("RemoveScore", ctx, rating.MovieID, rating.Score, rating.CreatedAt).Return(nil).Once()
+
+	err := r.service.Moderate(ctx, req)
+
+	assert.NoError(t, err)
+
+	r.r.AssertExpectations(t)
+	r.m.AssertExpectations(t)
+	r.s.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Moderate_Already_Deleted() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.ModerateRating{RatingID: 9}
+	rating := &domain.Rating{Model: gorm.Model{ID: 9}, UserID: 3, MovieID: 42, Score: 1.5}
+
+	r.r.On("LockByID", ctx, req.RatingID).Return(rating, nil).Once()
+	r.r.On("Delete", ctx, rating.ID).Return(int64(0), nil).Once()
+
+	err := r.service.Moderate(ctx, req)
+
+	assert.NoError(t, err)
+
+	r.r.AssertExpectations(t)
+	r.m.AssertNotCalled(t, "DeleteRating", mock.Anything, mock.Anything, mock.Anything)
+	r.s.AssertNotCalled(t, "RemoveScore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (r *RatingServiceTest) TestPromotionService_Moderate_Error_Failed_To_Get_Rating() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.ModerateRating{RatingID: 9}
+
+	r.r.On("LockByID", ctx, req.RatingID).Return(nil, errors.New("there is an error")).Once()
+
+	err := r.service.Moderate(ctx, req)
+
+	assert.ErrorContains(t, err, "failed to get rating: there is an error")
+
+	r.r.AssertExpectations(t)
+	r.m.AssertNotCalled(t, "DeleteRating", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (r *RatingServiceTest) TestPromotionService_ListByMovieID_Success() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	req := request.ListMovieRatings{
+		Pagination: request.Pagination{Limit: 2},
+		MovieID:    42,
+		Sort:       "most_helpful",
+	}
+
+	r.m.On("Get", ctx, req.MovieID).Return(&domain.Movie{}, nil).Once()
+	r.r.On("ListByMovieID", ctx, req.MovieID, "most_helpful", 2, 0).Return([]domain.Rating{
+		{Model: gorm.Model{ID: 7}, Score: 4.5, Review: "Tense", HelpfulCount: 12, User: domain.User{Username: "alice"}},
+		{Model: gorm.Model{ID: 8}, Score: 3, Review: "Long", HelpfulCount: 2, User: domain.User{Username: "bob"}},
+	}, int64(5), nil).Once()
+
+	result, err := r.service.ListByMovieID(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Ratings, 2)
+	assert.Equal(t, "alice", result.Ratings[0].Username)
+	assert.Equal(t, int64(12), result.Ratings[0].HelpfulCount)
+	assert.Equal(t, "Tense", result.Ratings[0].Review)
+	assert.Equal(t, int64(5), result.Pagination.Total)
+	assert.NotEmpty(t, result.Pagination.NextCursor)
+}
+
+func (r *RatingServiceTest) TestPromotionService_ListByMovieID_Error_Movie_Not_Found() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("Get", ctx, uint(42)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	result, err := r.service.ListByMovieID(ctx, request.ListMovieRatings{MovieID: 42})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, result)
+	r.r.AssertNotCalled(t, "ListByMovieID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (r *RatingServiceTest) TestPromotionService_VoteHelpful_Success() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.r.On("GetByID", ctx, uint(7)).Return(&domain.Rating{Model: gorm.Model{ID: 7}, UserID: 2}, nil).Once()
+	r.r.On("CreateHelpfulVote", ctx, domain.RatingHelpfulVote{RatingID: 7, UserID: 1}).Return(nil).Once()
+	r.r.On("IncrementHelpfulCount", ctx, uint(7)).Return(nil).Once()
+
+	err := r.service.VoteHelpful(ctx, request.VoteRatingHelpful{RatingID: 7, UserID: 1})
+
+	assert.NoError(t, err)
+	r.r.AssertExpectations(t)
+}
+
+func (r *RatingServiceTest) TestPromotionService_VoteHelpful_Error_Own_Review() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.r.On("GetByID", ctx, uint(7)).Return(&domain.Rating{Model: gorm.Model{ID: 7}, UserID: 1}, nil).Once()
+
+	err := r.service.VoteHelpful(ctx, request.VoteRatingHelpful{RatingID: 7, UserID: 1})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	r.r.AssertNotCalled(t, "CreateHelpfulVote", mock.Anything, mock.Anything)
+}
+
+func (r *RatingServiceTest) TestPromotionService_VoteHelpful_Error_Failed_To_Increment() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.r.On("GetByID", ctx, uint(7)).Return(&domain.Rating{Model: gorm.Model{ID: 7}, UserID: 2}, nil).Once()
+	r.r.On("CreateHelpfulVote", ctx, mock.Anything).Return(nil).Once()
+	r.r.On("IncrementHelpfulCount", ctx, uint(7)).Return(errors.New("there is an error")).Once()
+
+	err := r.service.VoteHelpful(ctx, request.VoteRatingHelpful{RatingID: 7, UserID: 1})
+
+	assert.ErrorContains(t, err, "failed to increment helpful count")
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+)
+
+const (
+	defaultReconcileBatchSize = 500
+	// ratingTolerance absorbs the rounding noise of the incremental formulas; anything above it is reported as drift.
+	ratingTolerance = 1e-6
+	// maxReportedDiscrepancies keeps the report readable; Mismatched still counts every movie.
+	maxReportedDiscrepancies = 1000
+)
+
+// ReconcileService recomputes the rating aggregates stored on movies from the ratings table.
+// The incremental updates done on every rating write can drift after partial failures or manual edits.
+type ReconcileService interface {
+	Reconcile(ctx context.Context, req request.Reconcile) (*response.Reconcile, error)
+}
+
+type reconcileService struct {
+	movieRepository repository.MovieRepository
+	ratingConfig    config.RatingConfig
+}
+
+func NewReconcileService(movieRepository repository.MovieRepository, ratingConfig config.RatingConfig) ReconcileService {
+	return &reconcileService{movieRepository: movieRepository, ratingConfig: ratingConfig}
+}
+
+func (s *reconcileService) Reconcile(ctx context.Context, req request.Reconcile) (*response.Reconcile, error) {
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+
+	resp := &response.Reconcile{DryRun: req.DryRun, Discrepancies: []response.RatingDiscrepancy{}}
+
+	// The prior is derived from all ratings too, so a fixing run refreshes it first; weighted ratings
+	// are then compared against the prior they have just been realigned with.
+	var prior float64
+	var err error
+	if req.DryRun {
+		prior, err = s.movieRepository.RatingPrior(ctx)
+	} else {
+		prior, err = s.movieRepository.RefreshRatingPrior(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating prior: %w", err)
+	}
+
+	var afterID uint
+	for {
+		aggregates, err := s.movieRepository.ListRatingAggregates(ctx, afterID, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rating aggregates: %w", err)
+		}
+
+		for _, aggregate := range aggregates {
+			resp.Scanned++
+			afterID = aggregate.MovieID
+
+			actualRating := aggregate.ActualRating()
+			actualWeighted := domain.WeightedRating(aggregate.ActualSum, aggregate.ActualCount, prior, s.ratingConfig.MinVotes)
+
+			if aggregate.StoredCount == aggregate.ActualCount &&
+				math.Abs(aggregate.StoredRating-actualRating) <= ratingTolerance &&
+				math.Abs(aggregate.StoredWeighted-actualWeighted) <= ratingTolerance {
+				continue
+			}
+
+			resp.Mismatched++
+			discrepancy := response.RatingDiscrepancy{
+				MovieID:        aggregate.MovieID,
+				StoredRating:   aggregate.StoredRating,
+				ActualRating:   actualRating,
+				StoredCount:    aggregate.StoredCount,
+				ActualCount:    aggregate.ActualCount,
+				StoredWeighted: aggregate.StoredWeighted,
+				ActualWeighted: actualWeighted,
+			}
+			if len(resp.Discrepancies) < maxReportedDiscrepancies {
+				resp.Discrepancies = append(resp.Discrepancies, discrepancy)
+			}
+			slog.Warn("Rating aggregate mismatch", "movie_id", aggregate.MovieID,
+				"stored_rating", aggregate.StoredRating, "actual_rating", actualRating,
+				"stored_count", aggregate.StoredCount, "actual_count", aggregate.ActualCount)
+
+			if req.DryRun {
+				continue
+			}
+			fixed, err := s.movieRepository.FixRatingAggregate(ctx, aggregate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fix rating aggregate of movie %d: %w", aggregate.MovieID, err)
+			}
+			if fixed {
+				resp.Fixed++
+			} else {
+				slog.Info("Movie rated during reconciliation, skipped", "movie_id", aggregate.MovieID)
+			}
+		}
+
+		if len(aggregates) < batchSize {
+			return resp, nil
+		}
+	}
+}
@@ -0,0 +1,154 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/mocks"
+	"testing"
+)
+
+type ReconcileServiceTest struct {
+	suite.Suite
+	service reconcileService
+	m       *mocks.MovieRepository
+}
+
+func (r *ReconcileServiceTest) SetupTest() {
+	r.m = new(mocks.MovieRepository)
+
+	r.service = reconcileService{movieRepository: r.m, ratingConfig: config.RatingConfig{MinVotes: 10}}
+}
+
+func Test_RunReconcileServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ReconcileServiceTest))
+}
+
+// Movie 1 is consistent: two ratings of 4.0 and 5.0 with a prior of 3.0. Movie 2 lost a rating.
+var (
+	consistentAggregate = domain.RatingAggregate{MovieID: 1, StoredRating: 4.5, StoredCount: 2, StoredWeighted: 39.0 / 12, ActualCount: 2, ActualSum: 9}
+	driftedAggregate    = domain.RatingAggregate{MovieID: 2, StoredRating: 4, StoredCount: 1, StoredWeighted: 34.0 / 11, ActualCount: 2, ActualSum: 9}
+)
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Dry_Run() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("RatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), defaultReconcileBatchSize).
+		Return([]domain.RatingAggregate{consistentAggregate, driftedAggregate}, nil).Once()
+
+	res, err := r.service.Reconcile(ctx, request.Reconcile{DryRun: true})
+
+	assert.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, 2, res.Scanned)
+	assert.Equal(t, 1, res.Mismatched)
+	assert.Equal(t, 0, res.Fixed)
+	if assert.Len(t, res.Discrepancies, 1) {
+		assert.Equal(t, uint(2), res.Discrepancies[0].MovieID)
+		assert.Equal(t, 4.5, res.Discrepancies[0].ActualRating)
+		assert.Equal(t, int64(2), res.Discrepancies[0].ActualCount)
+		assert.InDelta(t, 39.0/12, res.Discrepancies[0].ActualWeighted, 1e-9)
+	}
+	r.m.AssertExpectations(t)
+	r.m.AssertNotCalled(t, "RefreshRatingPrior", ctx)
+	r.m.AssertNotCalled(t, "FixRatingAggregate", ctx, driftedAggregate)
+}
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Mismatch_Weighted_Rating() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	// Count and average are right, but the weighted rating was computed with another prior.
+	aggregate := consistentAggregate
+	aggregate.StoredWeighted = 4.5
+
+	r.m.On("RatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), defaultReconcileBatchSize).
+		Return([]domain.RatingAggregate{aggregate}, nil).Once()
+
+	res, err := r.service.Reconcile(ctx, request.Reconcile{DryRun: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Mismatched)
+	r.m.AssertExpectations(t)
+}
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Fix() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("RefreshRatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), 2).
+		Return([]domain.RatingAggregate{consistentAggregate, driftedAggregate}, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(2), 2).
+		Return([]domain.RatingAggregate{}, nil).Once()
+	r.m.On("FixRatingAggregate", ctx, driftedAggregate).Return(true, nil).Once()
+
+	res, err := r.service.Reconcile(ctx, request.Reconcile{BatchSize: 2})
+
+	assert.NoError(t, err)
+	assert.False(t, res.DryRun)
+	assert.Equal(t, 2, res.Scanned)
+	assert.Equal(t, 1, res.Mismatched)
+	assert.Equal(t, 1, res.Fixed)
+	r.m.AssertExpectations(t)
+	r.m.AssertNotCalled(t, "RatingPrior", ctx)
+}
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Fix_Skipped_Concurrent_Write() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("RefreshRatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), defaultReconcileBatchSize).
+		Return([]domain.RatingAggregate{driftedAggregate}, nil).Once()
+	r.m.On("FixRatingAggregate", ctx, driftedAggregate).Return(false, nil).Once()
+
+	res, err := r.service.Reconcile(ctx, request.Reconcile{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Mismatched)
+	assert.Equal(t, 0, res.Fixed)
+	r.m.AssertExpectations(t)
+}
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Error_Failed_To_Fix() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("RefreshRatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), defaultReconcileBatchSize).
+		Return([]domain.RatingAggregate{driftedAggregate}, nil).Once()
+	r.m.On("FixRatingAggregate", ctx, driftedAggregate).Return(false, errors.New("boom")).Once()
+
+	_, err := r.service.Reconcile(ctx, request.Reconcile{})
+
+	assert.Error(t, err)
+}
+
+func (r *ReconcileServiceTest) TestReconcileService_Reconcile_Error_Failed_To_List() {
+	t := r.T()
+
+	ctx := context.TODO()
+
+	r.m.On("RatingPrior", ctx).Return(3.0, nil).Once()
+	r.m.On("ListRatingAggregates", ctx, uint(0), defaultReconcileBatchSize).
+		Return(nil, errors.New("boom")).Once()
+
+	_, err := r.service.Reconcile(ctx, request.Reconcile{DryRun: true})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"time"
+)
+
+var errRefreshTokenReused = errors.New("refresh token reuse detected")
+
+type TokenService interface {
+	// Issue starts a new session for an authenticated user.
+	Issue(ctx context.Context, user response.GetUser) (*response.Login, error)
+	// Refresh exchanges a refresh token for a new access token and a new refresh token.
+	Refresh(ctx context.Context, req request.RefreshToken) (*response.Login, error)
+	Logout(ctx context.Context, req request.Logout) error
+	IsRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error)
+}
+
+type tokenService struct {
+	txManager            db.TxManager
+	tokenRepository      repository.TokenRepository
+	userRepository       repository.UserRepository
+	signer               Signer
+	accessTTL            time.Duration
+	refreshTTL           time.Duration
+	requireVerifiedEmail bool
+	twoFactorRoles       []string
+}
+
+// NewTokenService creates the token service. With requireVerifiedEmail, users who have not verified
+// their email get access tokens without review:write. Users holding one of twoFactorRoles get tokens
+// that grant nothing until they enabled two-factor authentication.
+func NewTokenService(txManager db.TxManager, tokenRepository repository.TokenRepository, userRepository repository.UserRepository, signer Signer, accessTTL, refreshTTL time.Duration, requireVerifiedEmail bool, twoFactorRoles []string) TokenService {
+	return &tokenService{
+		txManager:            txManager,
+		tokenRepository:      tokenRepository,
+		userRepository:       userRepository,
+		signer:               signer,
+		accessTTL:            accessTTL,
+		refreshTTL:           refreshTTL,
+		requireVerifiedEmail: requireVerifiedEmail,
+		twoFactorRoles:       twoFactorRoles,
+	}
+}
+
+func (s *tokenService) Issue(ctx context.Context, user response.GetUser) (*response.Login, error) {
+	return s.issue(ctx, user, uuid.NewString())
+}
+
+func (s *tokenService) Refresh(ctx context.Context, req request.RefreshToken) (*response.Login, error) {
+	stored, err := s.tokenRepository.GetRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: invalid refresh token", common.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if stored.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+	}
+	if !stored.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: refresh token expired", common.ErrUnauthorized)
+	}
+
+	var resp *response.Login
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rotated, err := s.tokenRepository.RevokeRefreshToken(ctx, stored.ID)
+		if err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+		if !rotated {
+			return errRefreshTokenReused
+		}
+
+		// The user is reloaded so that changed privileges take effect with the next access token.
+		user, err := s.userRepository.GetByID(ctx, stored.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: user no longer exists", common.ErrUnauthorized)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if !user.IsActive() {
+			return fmt.Errorf("%w: account is %s", common.ErrUnauthorized, user.Status)
+		}
+
+		resp, err = s.issue(ctx, *user.GetUserResponse(), stored.FamilyID)
+		return err
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *tokenService) Logout(ctx context.Context, req request.Logout) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.tokenRepository.RevokeAccessToken(ctx, domain.RevokedToken{JTI: req.JTI, ExpiresAt: req.ExpiresAt})
+		if err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+
+		if req.All {
+			if err = s.tokenRepository.RevokeUserRefreshTokens(ctx, req.UserID); err != nil {
+				return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+			}
+		} else if req.RefreshToken != "" {
+			stored, err := s.tokenRepository.GetRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to get refresh token: %w", err)
+			}
+			// Someone else's refresh token is ignored rather than revoked.
+			if err == nil && stored.UserID == req.UserID {
+				if err = s.tokenRepository.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+					return fmt.Errorf("failed to revoke refresh token: %w", err)
+				}
+			}
+		}
+
+		// Denylist entries are useless once the token has expired anyway.
+		if err = s.tokenRepository.DeleteExpiredRevokedTokens(ctx, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete expired revoked tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *tokenService) IsRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error) {
+	revoked, err := s.tokenRepository.IsAccessTokenRevoked(ctx, jti, userID, issuedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (s *tokenService) issue(ctx context.Context, user response.GetUser, familyID string) (*response.Login, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.accessTTL)
+
+	// A revocation covers every token issued in its second, so a session issued in that same second,
+	// e.g. right after a password change, starts at the next one.
+	issuedAt := now.Unix()
+	revokedAt, err := s.userRepository.GetTokensRevokedAt(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token revocation: %w", err)
+	}
+	if !revokedAt.IsZero() && issuedAt <= revokedAt.Unix() {
+		issuedAt = revokedAt.Unix() + 1
+	}
+
+	permissions, enrollmentRequired, verificationRequired := sessionPermissions(user, s.requireVerifiedEmail, s.twoFactorRoles)
+
+	tokenString, err := s.signer.Sign(jwt.MapClaims{
+		"jti":         uuid.NewString(),
+		"user_id":     user.ID,
+		"username":    user.Username,
+		"roles":       user.Roles,
+		"permissions": permissions,
+		"iat":         issuedAt,
+		"exp":         expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	err = s.tokenRepository.CreateRefreshToken(ctx, domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &response.Login{
+		Username:                    user.Username,
+		Token:                       tokenString,
+		ExpiresAt:                   time.Unix(expiresAt.Unix(), 0).UTC(),
+		RefreshToken:                refreshToken,
+		PasswordResetRequired:       user.PasswordResetRequired,
+		EmailVerificationRequired:   verificationRequired,
+		TwoFactorEnrollmentRequired: enrollmentRequired,
+	}, nil
+}
+
+// sessionPermissions are the permissions a session of the user grants. Until a forced password
+// reset is done, or mandatory two-factor authentication is set up, it only authenticates, it grants
+// nothing; until the email is verified, if required, it cannot rate.
+func sessionPermissions(user response.GetUser, requireVerifiedEmail bool, twoFactorRoles []string) (permissions []string, enrollmentRequired, verificationRequired bool) {
+	permissions = user.Permissions
+	enrollmentRequired = !user.TwoFactorEnabled && requiresTwoFactor(user.Roles, twoFactorRoles)
+	if user.PasswordResetRequired || enrollmentRequired {
+		permissions = []string{}
+	}
+	verificationRequired = requireVerifiedEmail && !user.EmailVerified
+	if verificationRequired {
+		permissions = slices.DeleteFunc(slices.Clone(permissions), func(permission string) bool {
+			return permission == domain.PermissionReviewWrite
+		})
+	}
+	return permissions, enrollmentRequired, verificationRequired
+}
+
+// revokeReusedFamily ends a session whose rotated refresh token was presented again: either the
+// client or an attacker holds a stolen copy, and there is no telling which.
+func (s *tokenService) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := s.tokenRepository.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return fmt.Errorf("%w: %w", common.ErrUnauthorized, errRefreshTokenReused)
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,368 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/mocks"
+	"testing"
+	"time"
+)
+
+type TokenServiceTest struct {
+	suite.Suite
+	service *tokenService
+	tx      *mocks.TxManager
+	t       *mocks.TokenRepository
+	u       *mocks.UserRepository
+	signer  *mocks.Signer
+}
+
+func (s *TokenServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.t = new(mocks.TokenRepository)
+	s.u = new(mocks.UserRepository)
+	s.signer = new(mocks.Signer)
+	s.signer.On("Sign", mock.Anything).Return("access-token", nil).Maybe()
+	s.u.On("GetTokensRevokedAt", mock.Anything, mock.Anything).Return(time.Time{}, nil).Maybe()
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	s.service = &tokenService{
+		txManager:       s.tx,
+		tokenRepository: s.t,
+		userRepository:  s.u,
+		signer:          s.signer,
+		accessTTL:       time.Minute * 15,
+		refreshTTL:      time.Hour,
+	}
+}
+
+func Test_RunTokenServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenServiceTest))
+}
+
+func (s *TokenServiceTest) TestIssue_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var claims jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = args.Get(0).(jwt.MapClaims)
+	}).Return("access-token", nil).Once()
+
+	var stored domain.RefreshToken
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(domain.RefreshToken)
+	}).Return(nil).Once()
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Username: "alice", Roles: []string{"user"}, Permissions: []string{"review:write"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", res.Username)
+	assert.Equal(t, "access-token", res.Token)
+	assert.Equal(t, uint(7), stored.UserID)
+	assert.Equal(t, hashToken(res.RefreshToken), stored.TokenHash)
+	assert.NotEmpty(t, stored.FamilyID)
+
+	assert.NotEmpty(t, claims["jti"])
+	assert.Equal(t, []string{"user"}, claims["roles"])
+	assert.Equal(t, []string{"review:write"}, claims["permissions"])
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), res.ExpiresAt, 2*time.Second)
+
+	s.t.AssertExpectations(t)
+	s.signer.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestIssue_After_Revocation_In_Same_Second() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The tokens were revoked during this second, e.g. by the password change this session follows.
+	revokedAt := time.Now().UTC().Truncate(time.Second)
+	s.u.ExpectedCalls = nil
+	s.u.On("GetTokensRevokedAt", ctx, uint(7)).Return(revokedAt, nil).Once()
+
+	var claims jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = args.Get(0).(jwt.MapClaims)
+	}).Return("access-token", nil).Once()
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+
+	_, err := s.service.Issue(ctx, response.GetUser{ID: 7, Username: "alice"})
+
+	assert.NoError(t, err)
+	assert.Greater(t, claims["iat"], revokedAt.Unix())
+	s.u.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestIssue_Error_Revocation_Lookup_Failed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.ExpectedCalls = nil
+	s.u.On("GetTokensRevokedAt", ctx, uint(7)).Return(time.Time{}, errors.New("connection refused")).Once()
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Username: "alice"})
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	s.signer.AssertNotCalled(t, "Sign", mock.Anything)
+}
+
+func (s *TokenServiceTest) TestIssue_Error_Sign_Failed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Return("", errors.New("no active signing key")).Once()
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Username: "alice"})
+
+	assert.ErrorContains(t, err, "failed to sign access token")
+	assert.Nil(t, res)
+	s.t.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (s *TokenServiceTest) TestRefresh_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	stored := &domain.RefreshToken{Model: gorm.Model{ID: 3}, UserID: 7, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("old")).Return(stored, nil).Once()
+	s.t.On("RevokeRefreshToken", ctx, uint(3)).Return(true, nil).Once()
+	s.u.On("GetByID", ctx, uint(7)).Return(&domain.User{Model: gorm.Model{ID: 7}, Username: "alice"}, nil).Once()
+	s.t.On("CreateRefreshToken", ctx, mock.MatchedBy(func(token domain.RefreshToken) bool {
+		return token.UserID == 7 && token.FamilyID == "family" && token.TokenHash != hashToken("old")
+	})).Return(nil).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "old"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", res.Username)
+	assert.NotEqual(t, "old", res.RefreshToken)
+
+	s.t.AssertExpectations(t)
+	s.u.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestRefresh_Error_Reused_Token_Revokes_Family() {
+	t := s.T()
+	ctx := context.TODO()
+
+	revokedAt := time.Now().Add(-time.Minute)
+	stored := &domain.RefreshToken{Model: gorm.Model{ID: 3}, UserID: 7, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("old")).Return(stored, nil).Once()
+	s.t.On("RevokeRefreshTokenFamily", ctx, "family").Return(nil).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "old"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	assert.ErrorContains(t, err, "refresh token reuse detected")
+	assert.Nil(t, res)
+
+	s.t.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestRefresh_Error_Concurrent_Rotation_Revokes_Family() {
+	t := s.T()
+	ctx := context.TODO()
+
+	stored := &domain.RefreshToken{Model: gorm.Model{ID: 3}, UserID: 7, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("old")).Return(stored, nil).Once()
+	s.t.On("RevokeRefreshToken", ctx, uint(3)).Return(false, nil).Once()
+	s.t.On("RevokeRefreshTokenFamily", ctx, "family").Return(nil).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "old"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	assert.Nil(t, res)
+
+	s.t.AssertExpectations(t)
+	s.u.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestRefresh_Error_Banned_User() {
+	t := s.T()
+	ctx := context.TODO()
+
+	stored := &domain.RefreshToken{Model: gorm.Model{ID: 3}, UserID: 7, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("old")).Return(stored, nil).Once()
+	s.t.On("RevokeRefreshToken", ctx, uint(3)).Return(true, nil).Once()
+	s.u.On("GetByID", ctx, uint(7)).Return(&domain.User{Model: gorm.Model{ID: 7}, Status: domain.UserStatusBanned}, nil).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "old"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	assert.Nil(t, res)
+	s.t.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+func (s *TokenServiceTest) TestIssue_Password_Reset_Required_Grants_Nothing() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var claims jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = args.Get(0).(jwt.MapClaims)
+	}).Return("access-token", nil).Once()
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Permissions: []string{"movie:write"}, PasswordResetRequired: true})
+
+	assert.NoError(t, err)
+	assert.True(t, res.PasswordResetRequired)
+	assert.Equal(t, []string{}, claims["permissions"])
+}
+
+func (s *TokenServiceTest) TestIssue_Unverified_Email_Cannot_Review() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.service.requireVerifiedEmail = true
+	var claims jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = args.Get(0).(jwt.MapClaims)
+	}).Return("access-token", nil).Once()
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+	permissions := []string{"review:moderate", "review:write"}
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Permissions: permissions})
+
+	assert.NoError(t, err)
+	assert.True(t, res.EmailVerificationRequired)
+	assert.Equal(t, []string{"review:moderate"}, claims["permissions"])
+	assert.Equal(t, []string{"review:moderate", "review:write"}, permissions)
+}
+
+func (s *TokenServiceTest) TestIssue_Verified_Email_Can_Review() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.service.requireVerifiedEmail = true
+	var claims jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = args.Get(0).(jwt.MapClaims)
+	}).Return("access-token", nil).Once()
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.Issue(ctx, response.GetUser{ID: 7, Permissions: []string{"review:write"}, EmailVerified: true})
+
+	assert.NoError(t, err)
+	assert.False(t, res.EmailVerificationRequired)
+	assert.Equal(t, []string{"review:write"}, claims["permissions"])
+}
+
+func (s *TokenServiceTest) TestIssue_Two_Factor_Required_Grants_Nothing_Until_Enabled() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.service.twoFactorRoles = []string{"admin"}
+	var claims []jwt.MapClaims
+	s.signer.ExpectedCalls = nil
+	s.signer.On("Sign", mock.Anything).Run(func(args mock.Arguments) {
+		claims = append(claims, args.Get(0).(jwt.MapClaims))
+	}).Return("access-token", nil).Twice()
+	s.t.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Twice()
+	admin := response.GetUser{ID: 7, Roles: []string{"admin", "user"}, Permissions: []string{"movie:write"}}
+
+	res, err := s.service.Issue(ctx, admin)
+
+	assert.NoError(t, err)
+	assert.True(t, res.TwoFactorEnrollmentRequired)
+	assert.Equal(t, []string{}, claims[0]["permissions"])
+
+	admin.TwoFactorEnabled = true
+	res, err = s.service.Issue(ctx, admin)
+
+	assert.NoError(t, err)
+	assert.False(t, res.TwoFactorEnrollmentRequired)
+	assert.Equal(t, []string{"movie:write"}, claims[1]["permissions"])
+}
+
+func (s *TokenServiceTest) TestRefresh_Error_Expired() {
+	t := s.T()
+	ctx := context.TODO()
+
+	stored := &domain.RefreshToken{Model: gorm.Model{ID: 3}, UserID: 7, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Second)}
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("old")).Return(stored, nil).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "old"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	assert.ErrorContains(t, err, "refresh token expired")
+	assert.Nil(t, res)
+}
+
+func (s *TokenServiceTest) TestRefresh_Error_Unknown_Token() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("unknown")).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	res, err := s.service.Refresh(ctx, request.RefreshToken{RefreshToken: "unknown"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	assert.Nil(t, res)
+}
+
+func (s *TokenServiceTest) TestLogout_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	expiresAt := time.Now().Add(time.Minute)
+	s.t.On("RevokeAccessToken", ctx, domain.RevokedToken{JTI: "jti", ExpiresAt: expiresAt}).Return(nil).Once()
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("refresh")).Return(&domain.RefreshToken{UserID: 7, FamilyID: "family"}, nil).Once()
+	s.t.On("RevokeRefreshTokenFamily", ctx, "family").Return(nil).Once()
+	s.t.On("DeleteExpiredRevokedTokens", ctx, mock.Anything).Return(nil).Once()
+
+	err := s.service.Logout(ctx, request.Logout{UserID: 7, JTI: "jti", ExpiresAt: expiresAt, RefreshToken: "refresh"})
+
+	assert.NoError(t, err)
+	s.t.AssertExpectations(t)
+}
+
+func (s *TokenServiceTest) TestLogout_Ignores_Other_Users_Refresh_Token() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.t.On("RevokeAccessToken", ctx, mock.Anything).Return(nil).Once()
+	s.t.On("GetRefreshTokenByHash", ctx, hashToken("refresh")).Return(&domain.RefreshToken{UserID: 8, FamilyID: "family"}, nil).Once()
+	s.t.On("DeleteExpiredRevokedTokens", ctx, mock.Anything).Return(nil).Once()
+
+	err := s.service.Logout(ctx, request.Logout{UserID: 7, JTI: "jti", RefreshToken: "refresh"})
+
+	assert.NoError(t, err)
+	s.t.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+}
+
+func (s *TokenServiceTest) TestLogout_All_Sessions() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.t.On("RevokeAccessToken", ctx, mock.Anything).Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(7)).Return(nil).Once()
+	s.t.On("DeleteExpiredRevokedTokens", ctx, mock.Anything).Return(nil).Once()
+
+	err := s.service.Logout(ctx, request.Logout{UserID: 7, JTI: "jti", All: true})
+
+	assert.NoError(t, err)
+	s.t.AssertExpectations(t)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// TwoFactorService manages TOTP two-factor authentication and the second step of a login.
+type TwoFactorService interface {
+	// Enroll stores a new secret for the user's authenticator. It is only used once Confirm accepted
+	// a first code from it.
+	Enroll(ctx context.Context, req request.EnrollTwoFactor) (*response.TwoFactorEnrollment, error)
+	// Confirm enables two-factor authentication and returns the first recovery codes.
+	Confirm(ctx context.Context, req request.ConfirmTwoFactor) (*response.RecoveryCodes, error)
+	Disable(ctx context.Context, req request.DisableTwoFactor) error
+	// RegenerateRecoveryCodes replaces every recovery code of the user, used or not.
+	RegenerateRecoveryCodes(ctx context.Context, req request.RegenerateRecoveryCodes) (*response.RecoveryCodes, error)
+	// Challenge starts the second login step for a user who passed the password check.
+	Challenge(ctx context.Context, user response.GetUser) (*response.Login, error)
+	// VerifyLogin checks the answer to a challenge and returns the user to start a session for.
+	// Wrong codes count as failed logins.
+	VerifyLogin(ctx context.Context, req request.LoginTwoFactor) (*response.GetUser, error)
+}
+
+type twoFactorService struct {
+	txManager           db.TxManager
+	userRepository      repository.UserRepository
+	twoFactorRepository repository.TwoFactorRepository
+	loginThrottle       loginThrottle
+	hasher              password.Hasher
+	tokens              actionTokens
+	cipher              totpCipher
+	cfg                 config.TwoFactorConfig
+}
+
+// NewTwoFactorService creates the two-factor service. TOTP secrets are encrypted and challenge
+// tokens signed with keys derived from secret.
+func NewTwoFactorService(txManager db.TxManager, userRepository repository.UserRepository, twoFactorRepository repository.TwoFactorRepository, loginAttemptRepository repository.LoginAttemptRepository, hasher password.Hasher, secret string, cfg config.TwoFactorConfig, loginConfig config.LoginConfig) (TwoFactorService, error) {
+	totpCipher, err := newTOTPCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create totp cipher: %w", err)
+	}
+	return &twoFactorService{
+		txManager:           txManager,
+		userRepository:      userRepository,
+		twoFactorRepository: twoFactorRepository,
+		loginThrottle:       loginThrottle{repository: loginAttemptRepository, cfg: loginConfig},
+		hasher:              hasher,
+		tokens:              newActionTokens(secret),
+		cipher:              totpCipher,
+		cfg:                 cfg,
+	}, nil
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, req request.EnrollTwoFactor) (*response.TwoFactorEnrollment, error) {
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if match, _ := s.hasher.Verify(user.Password, req.Password); !match {
+		return nil, fmt.Errorf("%w: current password is incorrect", common.ErrForbidden)
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", common.ErrBadRequest)
+	}
+
+	secret := make([]byte, totpSecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	sealed, err := s.cipher.seal(user.ID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+	stored, err := s.twoFactorRepository.SetPendingSecret(ctx, user.ID, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store totp secret: %w", err)
+	}
+	if !stored {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", common.ErrBadRequest)
+	}
+
+	return &response.TwoFactorEnrollment{
+		Secret: totpEncoding.EncodeToString(secret),
+		URI:    provisioningURI(s.cfg.Issuer, user.Username, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, req request.ConfirmTwoFactor) (*response.RecoveryCodes, error) {
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, fmt.Errorf("%w: two-factor authentication is already enabled", common.ErrBadRequest)
+	}
+	if user.TOTPSecret == nil {
+		return nil, fmt.Errorf("%w: two-factor authentication has not been enrolled", common.ErrBadRequest)
+	}
+
+	secret, err := s.cipher.open(user.ID, user.TOTPSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	now := time.Now()
+	step, ok := matchTOTP(secret, req.Code, now)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid code", common.ErrBadRequest)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.twoFactorRepository.EnableTOTP(ctx, user.ID, now, step); err != nil {
+			return fmt.Errorf("failed to enable totp: %w", err)
+		}
+		if err := s.twoFactorRepository.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+			return fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response.RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, req request.DisableTwoFactor) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, req.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if match, _ := s.hasher.Verify(user.Password, req.Password); !match {
+			return fmt.Errorf("%w: current password is incorrect", common.ErrForbidden)
+		}
+		if user.TOTPEnabledAt == nil {
+			return fmt.Errorf("%w: two-factor authentication is not enabled", common.ErrBadRequest)
+		}
+		if requiresTwoFactor(user.RoleNames(), s.cfg.RequiredRoles) {
+			return fmt.Errorf("%w: two-factor authentication is required for your roles", common.ErrForbidden)
+		}
+
+		var valid bool
+		if isTOTPCode(req.Code) {
+			valid, err = s.useTOTPCode(ctx, user, req.Code, time.Now())
+		} else {
+			valid, err = s.twoFactorRepository.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(req.Code), time.Now())
+		}
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return fmt.Errorf("%w: invalid code", common.ErrForbidden)
+		}
+
+		if err = s.twoFactorRepository.DisableTOTP(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to disable totp: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, req request.RegenerateRecoveryCodes) (*response.RecoveryCodes, error) {
+	var codes []string
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepository.GetByID(ctx, req.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if user.TOTPEnabledAt == nil {
+			return fmt.Errorf("%w: two-factor authentication is not enabled", common.ErrBadRequest)
+		}
+		valid, err := s.useTOTPCode(ctx, user, req.Code, time.Now())
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return fmt.Errorf("%w: invalid code", common.ErrForbidden)
+		}
+
+		var hashes []string
+		codes, hashes, err = newRecoveryCodes()
+		if err != nil {
+			return fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		if err = s.twoFactorRepository.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+			return fmt.Errorf("failed to store recovery codes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response.RecoveryCodes{Codes: codes}, nil
+}
+
+func (s *twoFactorService) Challenge(ctx context.Context, user response.GetUser) (*response.Login, error) {
+	stored, err := s.userRepository.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.cfg.ChallengeTTL)
+	token, err := s.tokens.issue(actionLoginChallenge, stored.ID, challengeState(stored), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue challenge token: %w", err)
+	}
+	return &response.Login{
+		Username:          stored.Username,
+		ExpiresAt:         time.Unix(expiresAt.Unix(), 0).UTC(),
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}, nil
+}
+
+func (s *twoFactorService) VerifyLogin(ctx context.Context, req request.LoginTwoFactor) (*response.GetUser, error) {
+	now := time.Now()
+	payload, err := s.tokens.verify(req.ChallengeToken, actionLoginChallenge, now)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepository.GetByID(ctx, payload.UserID)
+	if err != nil {
+		return nil, errInvalidActionToken
+	}
+	// The challenge is bound to the password and the enrollment, so it ends when either changes.
+	if !payload.matches(challengeState(user)) {
+		return nil, errInvalidActionToken
+	}
+	attempts, err := s.loginThrottle.begin(ctx, user.Username, req.IP, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var valid bool
+	if req.Code != "" {
+		valid, err = s.useTOTPCode(ctx, user, req.Code, now)
+	} else {
+		valid, err = s.twoFactorRepository.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(req.RecoveryCode), now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, s.loginThrottle.fail(loginFailureWrongCode)
+	}
+	if err = s.loginThrottle.succeed(ctx, attempts, now); err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, fmt.Errorf("%w: account is %s", common.ErrForbidden, user.Status)
+	}
+	return user.GetUserResponse(), nil
+}
+
+// useTOTPCode checks a code from the authenticator. A code is accepted once: afterwards neither it
+// nor a code of an earlier time step works.
+func (s *twoFactorService) useTOTPCode(ctx context.Context, user *domain.User, code string, now time.Time) (bool, error) {
+	secret, err := s.cipher.open(user.ID, user.TOTPSecret)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	step, ok := matchTOTP(secret, code, now)
+	if !ok {
+		return false, nil
+	}
+	used, err := s.twoFactorRepository.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp code: %w", err)
+	}
+	return used, nil
+}
+
+func challengeState(user *domain.User) string {
+	var enabledAt int64
+	if user.TOTPEnabledAt != nil {
+		enabledAt = user.TOTPEnabledAt.UnixNano()
+	}
+	return user.Password + "|" + strconv.FormatInt(enabledAt, 10)
+}
+
+// requiresTwoFactor reports whether one of the roles makes two-factor authentication mandatory.
+func requiresTwoFactor(roles, requiredRoles []string) bool {
+	return slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(requiredRoles, role)
+	})
+}
@@ -0,0 +1,265 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TwoFactorServiceTest struct {
+	suite.Suite
+	service *twoFactorService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	f       *mocks.TwoFactorRepository
+	l       *mocks.LoginAttemptRepository
+	user    *domain.User
+	secret  []byte
+}
+
+func (s *TwoFactorServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.f = new(mocks.TwoFactorRepository)
+	s.l = new(mocks.LoginAttemptRepository)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	service, err := NewTwoFactorService(s.tx, s.u, s.f, s.l, password.NewHasher(testPasswordConfig), "secret", config.TwoFactorConfig{
+		Issuer:        "Movie Rating",
+		RequiredRoles: []string{domain.RoleAdmin},
+		ChallengeTTL:  5 * time.Minute,
+	}, config.LoginConfig{MaxFailures: 5, MaxIPFailures: 50, Lockout: time.Minute, MaxLockout: time.Hour, Window: 15 * time.Minute})
+	s.Require().NoError(err)
+	s.service = service.(*twoFactorService)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.secret = []byte("12345678901234567890")
+	sealed, err := s.service.cipher.seal(1, s.secret)
+	s.Require().NoError(err)
+	enabledAt := time.Now().Add(-time.Hour)
+	s.user = &domain.User{Model: gorm.Model{ID: 1}, Username: "alice", Password: string(hash), TOTPSecret: sealed, TOTPEnabledAt: &enabledAt}
+}
+
+func Test_RunTwoFactorServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorServiceTest))
+}
+
+func (s *TwoFactorServiceTest) currentCode() string {
+	return totpCode(s.secret, time.Now().Unix()/totpPeriod)
+}
+
+func (s *TwoFactorServiceTest) TestTOTPCode_RFC6238_Vectors() {
+	t := s.T()
+
+	// RFC 6238 appendix B (SHA-1), truncated to six digits.
+	assert.Equal(t, "287082", totpCode(s.secret, 59/totpPeriod))
+	assert.Equal(t, "081804", totpCode(s.secret, 1111111109/totpPeriod))
+	assert.Equal(t, "005924", totpCode(s.secret, 1234567890/totpPeriod))
+	assert.Equal(t, "279037", totpCode(s.secret, 2000000000/totpPeriod))
+}
+
+func (s *TwoFactorServiceTest) TestMatchTOTP_Allows_One_Step_Of_Drift() {
+	t := s.T()
+	now := time.Unix(1111111109, 0)
+	current := now.Unix() / totpPeriod
+
+	step, ok := matchTOTP(s.secret, totpCode(s.secret, current-1), now)
+	assert.True(t, ok)
+	assert.Equal(t, current-1, step)
+
+	_, ok = matchTOTP(s.secret, totpCode(s.secret, current-2), now)
+	assert.False(t, ok)
+}
+
+func (s *TwoFactorServiceTest) TestEnroll_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.TOTPSecret, s.user.TOTPEnabledAt = nil, nil
+	var sealed []byte
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.f.On("SetPendingSecret", ctx, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		sealed = args.Get(2).([]byte)
+	}).Return(true, nil).Once()
+
+	res, err := s.service.Enroll(ctx, request.EnrollTwoFactor{UserID: 1, Password: "password"})
+
+	assert.NoError(t, err)
+	secret, err := s.service.cipher.open(1, sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, totpEncoding.EncodeToString(secret), res.Secret)
+	assert.True(t, strings.HasPrefix(res.URI, "otpauth://totp/Movie%20Rating:alice?"))
+	assert.Contains(t, res.URI, "secret="+res.Secret)
+	// A secret sealed for one user cannot be used for another.
+	_, err = s.service.cipher.open(2, sealed)
+	assert.Error(t, err)
+}
+
+func (s *TwoFactorServiceTest) TestEnroll_Error_Already_Enabled() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Enroll(ctx, request.EnrollTwoFactor{UserID: 1, Password: "password"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.f.AssertNotCalled(t, "SetPendingSecret", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TwoFactorServiceTest) TestConfirm_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.TOTPEnabledAt = nil
+	var hashes []string
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.f.On("EnableTOTP", ctx, uint(1), mock.Anything, mock.Anything).Return(nil).Once()
+	s.f.On("ReplaceRecoveryCodes", ctx, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	}).Return(nil).Once()
+
+	res, err := s.service.Confirm(ctx, request.ConfirmTwoFactor{UserID: 1, Code: s.currentCode()})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Codes, recoveryCodeCount)
+	assert.Equal(t, hashRecoveryCode(res.Codes[0]), hashes[0])
+	s.f.AssertExpectations(t)
+}
+
+func (s *TwoFactorServiceTest) TestConfirm_Error_Invalid_Code() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.TOTPEnabledAt = nil
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.Confirm(ctx, request.ConfirmTwoFactor{UserID: 1, Code: "000000"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.f.AssertNotCalled(t, "EnableTOTP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TwoFactorServiceTest) TestVerifyLogin_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Twice()
+	challenge, err := s.service.Challenge(ctx, *s.user.GetUserResponse())
+	s.Require().NoError(err)
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.Empty(t, challenge.Token)
+
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.f.On("UseTOTPStep", ctx, uint(1), mock.Anything).Return(true, nil).Once()
+	s.l.On("Reset", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeUsername, Key: "alice"}).Return(nil).Once()
+	s.l.On("Release", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: "203.0.113.7"}, false).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.VerifyLogin(ctx, request.LoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: s.currentCode(), IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.ID)
+	s.l.AssertExpectations(t)
+}
+
+func (s *TwoFactorServiceTest) TestVerifyLogin_Recovery_Code_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Twice()
+	challenge, err := s.service.Challenge(ctx, *s.user.GetUserResponse())
+	s.Require().NoError(err)
+
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Once()
+	s.f.On("UseRecoveryCode", ctx, uint(1), hashRecoveryCode("abcdefgh"), mock.Anything).Return(true, nil).Once()
+	s.l.On("Reset", ctx, mock.Anything).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	_, err = s.service.VerifyLogin(ctx, request.LoginTwoFactor{ChallengeToken: challenge.ChallengeToken, RecoveryCode: "ABCD-EFGH"})
+
+	assert.NoError(t, err)
+	s.f.AssertExpectations(t)
+}
+
+func (s *TwoFactorServiceTest) TestVerifyLogin_Error_Replayed_Code() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Twice()
+	challenge, err := s.service.Challenge(ctx, *s.user.GetUserResponse())
+	s.Require().NoError(err)
+
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.f.On("UseTOTPStep", ctx, uint(1), mock.Anything).Return(false, nil).Once()
+
+	_, err = s.service.VerifyLogin(ctx, request.LoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: s.currentCode(), IP: "203.0.113.7"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.l.AssertExpectations(t)
+	s.l.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+	s.l.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TwoFactorServiceTest) TestVerifyLogin_Error_Password_Changed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	challenge, err := s.service.Challenge(ctx, *s.user.GetUserResponse())
+	s.Require().NoError(err)
+
+	changed := *s.user
+	changed.Password = "another-hash"
+	s.u.On("GetByID", ctx, uint(1)).Return(&changed, nil).Once()
+
+	_, err = s.service.VerifyLogin(ctx, request.LoginTwoFactor{ChallengeToken: challenge.ChallengeToken, Code: s.currentCode()})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.f.AssertNotCalled(t, "UseTOTPStep", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *TwoFactorServiceTest) TestDisable_Error_Required_For_Role() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.Roles = []domain.Role{{Name: domain.RoleAdmin}}
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	err := s.service.Disable(ctx, request.DisableTwoFactor{UserID: 1, Password: "password", Code: s.currentCode()})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.f.AssertNotCalled(t, "DisableTOTP", mock.Anything, mock.Anything)
+}
+
+func (s *TwoFactorServiceTest) TestDisable_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.f.On("UseTOTPStep", ctx, uint(1), mock.Anything).Return(true, nil).Once()
+	s.f.On("DisableTOTP", ctx, uint(1)).Return(nil).Once()
+
+	err := s.service.Disable(ctx, request.DisableTwoFactor{UserID: 1, Password: "password", Code: s.currentCode()})
+
+	assert.NoError(t, err)
+	s.f.AssertExpectations(t)
+}
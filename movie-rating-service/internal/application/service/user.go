@@ -2,26 +2,69 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"log/slog"
+	"movie-rating-service/config"
 	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
 	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/password"
 	"movie-rating-service/internal/infrastructure/repository"
+	"time"
 )
 
 type UserService interface {
+	// Create registers a user. The password must satisfy the password policy.
 	Create(ctx context.Context, req request.CreateUser) (*response.CreateUser, error)
 	Get(ctx context.Context, req request.GetUser) (*response.GetUser, error)
+	// IsAuthorized checks the credentials of a login. Repeated failures lock out the username and the
+	// client address for a while, with a *LoginLockedError. A password hash made with outdated
+	// parameters is replaced on success.
 	IsAuthorized(ctx context.Context, req request.Login) (*response.GetUser, error)
+	UpdateProfile(ctx context.Context, req request.UpdateProfile) (*response.GetUser, error)
+	// ChangePassword ends every session of the user; the caller issues a new one for the returned user.
+	ChangePassword(ctx context.Context, req request.ChangePassword) (*response.GetUser, error)
+	// Delete anonymizes the account, ends its sessions and empties its watchlist. The user's ratings
+	// are kept without their author's personal data, so movie rating aggregates do not change.
+	Delete(ctx context.Context, req request.DeleteAccount) error
 }
 
 type userService struct {
-	userRepository repository.UserRepository
+	txManager           db.TxManager
+	userRepository      repository.UserRepository
+	roleRepository      repository.RoleRepository
+	tokenRepository     repository.TokenRepository
+	watchlistRepository repository.WatchlistRepository
+	movieRepository     repository.MovieRepository
+	loginThrottle       loginThrottle
+	hasher              password.Hasher
+	policy              *password.Policy
+	dummyHash           string
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
-	return &userService{userRepository: userRepository}
+func NewUserService(txManager db.TxManager, userRepository repository.UserRepository, roleRepository repository.RoleRepository, tokenRepository repository.TokenRepository, loginAttemptRepository repository.LoginAttemptRepository, watchlistRepository repository.WatchlistRepository, movieRepository repository.MovieRepository, hasher password.Hasher, policy *password.Policy, loginConfig config.LoginConfig) (UserService, error) {
+	// Compared against when the username does not exist, so that a login takes as long whether or
+	// not it does.
+	dummyHash, err := hasher.Hash("dummy password")
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dummy password: %w", err)
+	}
+	return &userService{
+		txManager:           txManager,
+		userRepository:      userRepository,
+		roleRepository:      roleRepository,
+		tokenRepository:     tokenRepository,
+		watchlistRepository: watchlistRepository,
+		movieRepository:     movieRepository,
+		loginThrottle:       loginThrottle{repository: loginAttemptRepository, cfg: loginConfig},
+		hasher:              hasher,
+		policy:              policy,
+		dummyHash:           dummyHash,
+	}, nil
 }
 
 func (s *userService) Get(ctx context.Context, req request.GetUser) (*response.GetUser, error) {
@@ -32,15 +75,25 @@ func (s *userService) Get(ctx context.Context, req request.GetUser) (*response.G
 	return user.GetUserResponse(), nil
 }
 func (s *userService) Create(ctx context.Context, req request.CreateUser) (*response.CreateUser, error) {
+	hash, err := newPasswordHash(s.hasher, s.policy, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	// Registration never grants privileges; further roles are granted by an admin.
+	role, err := s.roleRepository.GetByName(ctx, domain.RoleUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
 	user, err := s.userRepository.Create(ctx, domain.User{
 		Username: req.Username,
-		Password: req.Password,
+		Password: hash,
 		Name:     req.Name,
 		Surname:  req.Surname,
 		Email:    req.Email,
 		Phone:    req.Phone,
 		Address:  req.Address,
-		IsAdmin:  req.IsAdmin,
+		Roles:    []domain.Role{*role},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -49,14 +102,158 @@ func (s *userService) Create(ctx context.Context, req request.CreateUser) (*resp
 }
 
 func (s *userService) IsAuthorized(ctx context.Context, req request.Login) (*response.GetUser, error) {
+	now := time.Now()
+	attempts, err := s.loginThrottle.begin(ctx, req.Username, req.IP, now)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepository.GetByUsername(ctx, req.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Compare anyway, so that an unknown username takes as long as a wrong password.
+		s.hasher.Verify(s.dummyHash, req.Password)
+		return nil, s.loginThrottle.fail(loginFailureUnknownUser)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	match, rehash := s.hasher.Verify(user.Password, req.Password)
+	if !match {
+		return nil, s.loginThrottle.fail(loginFailureWrongPassword)
+	}
+	if rehash {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+	// With two-factor authentication, the failures are only forgotten once the code is right too;
+	// otherwise the password would allow guessing codes without ever being locked out.
+	if user.TOTPEnabledAt == nil {
+		err = s.loginThrottle.succeed(ctx, attempts, now)
+	} else {
+		err = s.loginThrottle.release(ctx, attempts)
+	}
+	if err != nil {
 		return nil, err
 	}
+	if !user.IsActive() {
+		return nil, fmt.Errorf("%w: account is %s", common.ErrForbidden, user.Status)
+	}
 
 	return user.GetUserResponse(), nil
 }
+
+// rehashPassword upgrades the stored hash to the configured algorithm and parameters. The login
+// succeeds either way; the next one tries again.
+func (s *userService) rehashPassword(ctx context.Context, user *domain.User, plain string) {
+	hash, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.userRepository.RehashPassword(ctx, user.ID, user.Password, hash)
+	}
+	if err != nil {
+		slog.Warn("Password could not rehash", "user_id", user.ID, "error", err)
+		return
+	}
+	user.Password = hash
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, req request.UpdateProfile) (*response.GetUser, error) {
+	changes := make(map[string]interface{})
+	for column, value := range map[string]*string{
+		"name":    req.Name,
+		"surname": req.Surname,
+		"email":   req.Email,
+		"phone":   req.Phone,
+		"address": req.Address,
+	} {
+		if value != nil {
+			changes[column] = *value
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: nothing to update", common.ErrBadRequest)
+	}
+
+	if err := s.userRepository.UpdateProfile(ctx, req.UserID, changes); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return s.Get(ctx, request.GetUser{ID: req.UserID})
+}
+
+func (s *userService) ChangePassword(ctx context.Context, req request.ChangePassword) (*response.GetUser, error) {
+	if req.CurrentPassword == req.NewPassword {
+		return nil, fmt.Errorf("%w: new password must differ from the current one", common.ErrBadRequest)
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := verifyPassword(ctx, s.userRepository, s.hasher, req.UserID, req.CurrentPassword); err != nil {
+			return err
+		}
+
+		hash, err := newPasswordHash(s.hasher, s.policy, req.NewPassword)
+		if err != nil {
+			return err
+		}
+		if err = s.userRepository.UpdatePassword(ctx, req.UserID, hash); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		return endSessions(ctx, s.userRepository, s.tokenRepository, req.UserID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, request.GetUser{ID: req.UserID})
+}
+
+func (s *userService) Delete(ctx context.Context, req request.DeleteAccount) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := verifyPassword(ctx, s.userRepository, s.hasher, req.UserID, req.Password); err != nil {
+			return err
+		}
+		if err := endSessions(ctx, s.userRepository, s.tokenRepository, req.UserID, time.Now()); err != nil {
+			return err
+		}
+		if err := clearWatchlist(ctx, s.watchlistRepository, s.movieRepository, req.UserID); err != nil {
+			return err
+		}
+		if err := s.userRepository.Anonymize(ctx, req.UserID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+}
+
+// verifyPassword re-checks the password of an already authenticated user before a sensitive change.
+func verifyPassword(ctx context.Context, userRepository repository.UserRepository, hasher password.Hasher, userID uint, plain string) error {
+	user, err := userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if match, _ := hasher.Verify(user.Password, plain); !match {
+		return fmt.Errorf("%w: current password is incorrect", common.ErrForbidden)
+	}
+	return nil
+}
+
+// newPasswordHash checks a new password against the policy and hashes it.
+func newPasswordHash(hasher password.Hasher, policy *password.Policy, plain string) (string, error) {
+	if err := policy.Check(plain); err != nil {
+		return "", fmt.Errorf("%w: %w", common.ErrBadRequest, err)
+	}
+	hash, err := hasher.Hash(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hash, nil
+}
+
+// endSessions revokes the user's refresh tokens and every access token issued up to the given time.
+func endSessions(ctx context.Context, userRepository repository.UserRepository, tokenRepository repository.TokenRepository, userID uint, at time.Time) error {
+	if err := tokenRepository.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := userRepository.RevokeTokens(ctx, userID, at); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/password"
+	"movie-rating-service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPasswordConfig hashes with bcrypt at its lowest cost, like the fixtures, so that tests stay
+// fast and logins do not rehash.
+var testPasswordConfig = config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: bcrypt.MinCost, MinLength: 8, MaxLength: 72}
+
+type UserServiceTest struct {
+	suite.Suite
+	service *userService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	r       *mocks.RoleRepository
+	t       *mocks.TokenRepository
+	l       *mocks.LoginAttemptRepository
+	w       *mocks.WatchlistRepository
+	m       *mocks.MovieRepository
+	user    *domain.User
+}
+
+func (s *UserServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.r = new(mocks.RoleRepository)
+	s.t = new(mocks.TokenRepository)
+	s.l = new(mocks.LoginAttemptRepository)
+	s.w = new(mocks.WatchlistRepository)
+	s.m = new(mocks.MovieRepository)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	s.Require().NoError(err)
+	s.user = &domain.User{Model: gorm.Model{ID: 1}, Username: "alice", Password: string(hash)}
+
+	policy, err := password.NewPolicy(testPasswordConfig)
+	s.Require().NoError(err)
+	service, err := NewUserService(s.tx, s.u, s.r, s.t, s.l, s.w, s.m, password.NewHasher(testPasswordConfig), policy, config.LoginConfig{
+		MaxFailures:   3,
+		MaxIPFailures: 10,
+		Lockout:       time.Minute,
+		MaxLockout:    10 * time.Minute,
+		Window:        15 * time.Minute,
+	})
+	s.Require().NoError(err)
+	s.service = service.(*userService)
+}
+
+func Test_RunUserServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UserServiceTest))
+}
+
+func (s *UserServiceTest) TestCreate_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var created domain.User
+	s.r.On("GetByName", ctx, domain.RoleUser).Return(&domain.Role{ID: 4, Name: domain.RoleUser}, nil).Once()
+	s.u.On("Create", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(domain.User)
+	}).Return(&domain.User{Model: gorm.Model{ID: 2}, Username: "bob"}, nil).Once()
+
+	res, err := s.service.Create(ctx, request.CreateUser{Username: "bob", Password: "correct horse", Name: "Bob", Surname: "Builder", Email: "bob@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), res.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(created.Password), []byte("correct horse")))
+}
+
+func (s *UserServiceTest) TestCreate_Error_Password_Too_Short() {
+	t := s.T()
+	ctx := context.TODO()
+
+	_, err := s.service.Create(ctx, request.CreateUser{Username: "bob", Password: "1234", Name: "Bob", Surname: "Builder", Email: "bob@example.com"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.ErrorIs(t, err, password.ErrWeak)
+	s.u.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestUpdateProfile_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	name, email := "Alice", "alice@example.com"
+	s.u.On("UpdateProfile", ctx, uint(1), map[string]interface{}{"name": name, "email": email}).Return(nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(&domain.User{Model: gorm.Model{ID: 1}, Name: name, Email: email}, nil).Once()
+
+	res, err := s.service.UpdateProfile(ctx, request.UpdateProfile{UserID: 1, Name: &name, Email: &email})
+
+	assert.NoError(t, err)
+	assert.Equal(t, name, res.Name)
+	assert.Equal(t, email, res.Email)
+	s.u.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestUpdateProfile_Clears_Optional_Field() {
+	t := s.T()
+	ctx := context.TODO()
+
+	empty := ""
+	s.u.On("UpdateProfile", ctx, uint(1), map[string]interface{}{"phone": ""}).Return(nil).Once()
+	s.u.On("GetByID", ctx, uint(1)).Return(&domain.User{Model: gorm.Model{ID: 1}}, nil).Once()
+
+	_, err := s.service.UpdateProfile(ctx, request.UpdateProfile{UserID: 1, Phone: &empty})
+
+	assert.NoError(t, err)
+	s.u.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestUpdateProfile_Error_Nothing_To_Update() {
+	t := s.T()
+	ctx := context.TODO()
+
+	_, err := s.service.UpdateProfile(ctx, request.UpdateProfile{UserID: 1})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestChangePassword_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	var hash string
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil)
+	s.u.On("UpdatePassword", ctx, uint(1), mock.Anything).Run(func(args mock.Arguments) {
+		hash = args.String(2)
+	}).Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(1), mock.MatchedBy(func(at time.Time) bool {
+		return time.Since(at) < 2*time.Second
+	})).Return(nil).Once()
+
+	res, err := s.service.ChangePassword(ctx, request.ChangePassword{UserID: 1, CurrentPassword: "old-password", NewPassword: "new-password"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")))
+	s.u.AssertExpectations(t)
+	s.t.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestChangePassword_Error_Wrong_Current_Password() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	_, err := s.service.ChangePassword(ctx, request.ChangePassword{UserID: 1, CurrentPassword: "wrong-password", NewPassword: "new-password"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.u.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	s.t.AssertNotCalled(t, "RevokeUserRefreshTokens", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestChangePassword_Error_Same_Password() {
+	t := s.T()
+	ctx := context.TODO()
+
+	_, err := s.service.ChangePassword(ctx, request.ChangePassword{UserID: 1, CurrentPassword: "old-password", NewPassword: "old-password"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.u.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestDelete_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(1), mock.Anything).Return(nil).Once()
+	s.w.On("RemoveAll", ctx, uint(1)).Return([]uint{9, 4}, nil).Once()
+	var counted []uint
+	s.m.On("UpdateWatchlistCount", ctx, mock.Anything, -1).Run(func(args mock.Arguments) {
+		counted = append(counted, args.Get(1).(uint))
+	}).Return(nil).Twice()
+	s.u.On("Anonymize", ctx, uint(1)).Return(nil).Once()
+
+	err := s.service.Delete(ctx, request.DeleteAccount{UserID: 1, Password: "old-password"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{4, 9}, counted)
+	s.u.AssertExpectations(t)
+	s.t.AssertExpectations(t)
+	s.w.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestDelete_Error_Wrong_Password() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+
+	err := s.service.Delete(ctx, request.DeleteAccount{UserID: 1, Password: "wrong-password"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+	s.u.AssertNotCalled(t, "Anonymize", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestDelete_Error_Anonymize_Failed() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(1)).Return(s.user, nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(1), mock.Anything).Return(nil).Once()
+	s.w.On("RemoveAll", ctx, uint(1)).Return([]uint{}, nil).Once()
+	s.u.On("Anonymize", ctx, uint(1)).Return(errors.New("there is an error")).Once()
+
+	err := s.service.Delete(ctx, request.DeleteAccount{UserID: 1, Password: "old-password"})
+
+	assert.ErrorContains(t, err, "failed to delete user")
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.u.On("GetByUsername", ctx, "Alice").Return(s.user, nil).Once()
+	s.l.On("Reset", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeUsername, Key: "alice"}).Return(nil).Once()
+	s.l.On("Release", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: "203.0.113.7"}, false).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.IsAuthorized(ctx, request.Login{Username: "Alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.ID)
+	s.l.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Rehashes_Outdated_Hash() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The service moved on to argon2id; alice still has her bcrypt hash.
+	s.service.hasher = password.NewHasher(config.PasswordConfig{Algorithm: "argon2id", Argon2Memory: 64, Argon2Time: 1, Argon2Threads: 1})
+	oldHash := s.user.Password
+	var newHash string
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+	s.u.On("RehashPassword", ctx, uint(1), oldHash, mock.Anything).Run(func(args mock.Arguments) {
+		newHash = args.String(3)
+	}).Return(nil).Once()
+	s.l.On("Reset", ctx, mock.Anything).Return(nil).Once()
+	s.l.On("Release", ctx, mock.Anything, false).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(newHash, "$argon2id$"))
+	match, rehash := s.service.hasher.Verify(newHash, "old-password")
+	assert.True(t, match)
+	assert.False(t, rehash)
+	s.u.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Rehash_Failure_Does_Not_Fail_Login() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.service.hasher = password.NewHasher(config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: bcrypt.MinCost + 1})
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+	s.u.On("RehashPassword", ctx, uint(1), mock.Anything, mock.Anything).Return(errors.New("there is an error")).Once()
+	s.l.On("Reset", ctx, mock.Anything).Return(nil).Once()
+	s.l.On("Release", ctx, mock.Anything, false).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	res, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.ID)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Two_Factor_Releases_Attempts() {
+	t := s.T()
+	ctx := context.TODO()
+
+	enabledAt := time.Now()
+	s.user.TOTPEnabledAt = &enabledAt
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Twice()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+	s.l.On("Release", ctx, mock.Anything, false).Return(nil).Twice()
+
+	res, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), res.ID)
+	s.l.AssertExpectations(t)
+	s.l.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Success_Lifts_Own_Lockout() {
+	t := s.T()
+	ctx := context.TODO()
+	ipKey := domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: "203.0.113.7"}
+
+	// The attempt is the tenth from the address, so counting it locked the address.
+	s.l.On("Acquire", ctx, ipKey, mock.Anything, mock.Anything, 10, mock.Anything).Return(10, nil, nil).Once()
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Once()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+	s.l.On("Reset", ctx, mock.Anything).Return(nil).Once()
+	s.l.On("Release", ctx, ipKey, true).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.NoError(t, err)
+	s.l.AssertExpectations(t)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Error_Unknown_User() {
+	t := s.T()
+	ctx := context.TODO()
+
+	lockouts := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute}
+	s.l.On("Acquire", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeUsername, Key: "mallory"}, mock.Anything, 15*time.Minute, 3, lockouts).Return(1, nil, nil).Once()
+	s.l.On("Acquire", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: "203.0.113.7"}, mock.Anything, 15*time.Minute, 10, lockouts).Return(1, nil, nil).Once()
+	s.u.On("GetByUsername", ctx, "mallory").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "mallory", Password: "guess", IP: "203.0.113.7"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.l.AssertExpectations(t)
+	s.l.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Error_Wrong_Password_Keeps_Attempt() {
+	t := s.T()
+	ctx := context.TODO()
+
+	// The attempt reaches the limit of three, which locks the username when it is counted.
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(3, nil, nil).Twice()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "guess", IP: "203.0.113.7"})
+
+	assert.ErrorIs(t, err, common.ErrUnauthorized)
+	s.l.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+	s.l.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Error_Locked() {
+	t := s.T()
+	ctx := context.TODO()
+	ipKey := domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeIP, Key: "203.0.113.7"}
+
+	// Concurrent attempts are counted one after another, so the one beyond the limit finds the
+	// username locked before its password is checked.
+	lockedUntil := time.Now().Add(90 * time.Second)
+	s.l.On("Acquire", ctx, domain.LoginAttemptKey{Scope: domain.LoginAttemptScopeUsername, Key: "alice"}, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, &lockedUntil, nil).Once()
+	s.l.On("Acquire", ctx, ipKey, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(4, nil, nil).Once()
+	s.l.On("Release", ctx, ipKey, false).Return(nil).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	var locked *LoginLockedError
+	assert.ErrorAs(t, err, &locked)
+	assert.ErrorIs(t, err, common.ErrTooManyRequests)
+	assert.InDelta(t, 90, locked.RetryAfterSeconds(), 1)
+	s.l.AssertExpectations(t)
+	s.u.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Error_Record_Attempt() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil, errors.New("there is an error")).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password", IP: "203.0.113.7"})
+
+	assert.ErrorContains(t, err, "failed to record login attempt")
+	s.u.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+}
+
+func (s *UserServiceTest) TestIsAuthorized_Error_Inactive() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.user.Status = domain.UserStatusDisabled
+	s.l.On("Acquire", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(1, nil, nil).Once()
+	s.u.On("GetByUsername", ctx, "alice").Return(s.user, nil).Once()
+	s.l.On("Reset", ctx, mock.Anything).Return(nil).Once()
+	s.l.On("DeleteExpired", ctx, mock.Anything).Return(nil).Once()
+
+	_, err := s.service.IsAuthorized(ctx, request.Login{Username: "alice", Password: "old-password"})
+
+	assert.ErrorIs(t, err, common.ErrForbidden)
+}
+
+func (s *UserServiceTest) TestLoginThrottle_Lockout() {
+	t := s.T()
+
+	throttle := s.service.loginThrottle
+	assert.Equal(t, time.Minute, throttle.lockout(0))
+	assert.Equal(t, 4*time.Minute, throttle.lockout(2))
+	assert.Equal(t, 10*time.Minute, throttle.lockout(4))
+	assert.Equal(t, 10*time.Minute, throttle.lockout(1000))
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute}, throttle.lockouts())
+}
+
+func (s *UserServiceTest) TestClientAddressKey() {
+	t := s.T()
+
+	assert.Equal(t, "203.0.113.7", clientAddressKey("203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientAddressKey("::ffff:203.0.113.7"))
+	assert.Equal(t, "2001:db8:1:2::/64", clientAddressKey("2001:db8:1:2:aaaa:bbbb:cccc:dddd"))
+	assert.Equal(t, "unknown", clientAddressKey("unknown"))
+}
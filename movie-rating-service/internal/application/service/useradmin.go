@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/repository"
+	"time"
+)
+
+// UserAdminService manages other users' accounts. Every change that takes privileges away ends the
+// user's sessions at once instead of waiting for their access tokens to expire.
+type UserAdminService interface {
+	List(ctx context.Context, req request.ListUsers) (*response.ListUsers, error)
+	GrantRole(ctx context.Context, req request.UserRole) (*response.GetUser, error)
+	RevokeRole(ctx context.Context, req request.UserRole) (*response.GetUser, error)
+	UpdateStatus(ctx context.Context, req request.UpdateUserStatus) (*response.GetUser, error)
+	// ForcePasswordReset ends the user's sessions; after the next login the access token grants
+	// nothing until the password is changed.
+	ForcePasswordReset(ctx context.Context, req request.ForcePasswordReset) error
+}
+
+type userAdminService struct {
+	txManager       db.TxManager
+	userRepository  repository.UserRepository
+	roleRepository  repository.RoleRepository
+	tokenRepository repository.TokenRepository
+}
+
+func NewUserAdminService(txManager db.TxManager, userRepository repository.UserRepository, roleRepository repository.RoleRepository, tokenRepository repository.TokenRepository) UserAdminService {
+	return &userAdminService{txManager: txManager, userRepository: userRepository, roleRepository: roleRepository, tokenRepository: tokenRepository}
+}
+
+func (s *userAdminService) List(ctx context.Context, req request.ListUsers) (*response.ListUsers, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.userRepository.List(ctx, repository.UserFilter{
+		Query:  req.Query,
+		Role:   req.Role,
+		Status: req.Status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	resp := &response.ListUsers{Pagination: encodePage(limit, offset, total)}
+	resp.Users = make([]response.GetUser, len(users))
+	for i, user := range users {
+		resp.Users[i] = *user.GetUserResponse()
+	}
+	return resp, nil
+}
+
+func (s *userAdminService) GrantRole(ctx context.Context, req request.UserRole) (*response.GetUser, error) {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userID, roleID, err := s.resolve(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err = s.userRepository.AddRole(ctx, userID, roleID); err != nil {
+			return fmt.Errorf("failed to grant role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.UserID)
+}
+
+func (s *userAdminService) RevokeRole(ctx context.Context, req request.UserRole) (*response.GetUser, error) {
+	if req.ActorID == req.UserID {
+		return nil, fmt.Errorf("%w: cannot revoke your own roles", common.ErrBadRequest)
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		userID, roleID, err := s.resolve(ctx, req)
+		if err != nil {
+			return err
+		}
+		if err = s.userRepository.RemoveRole(ctx, userID, roleID); err != nil {
+			return fmt.Errorf("failed to revoke role: %w", err)
+		}
+		// Refresh tokens stay valid; the next refresh issues a token without the revoked permissions.
+		if err = s.userRepository.RevokeTokens(ctx, userID, time.Now()); err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.UserID)
+}
+
+func (s *userAdminService) UpdateStatus(ctx context.Context, req request.UpdateUserStatus) (*response.GetUser, error) {
+	if req.ActorID == req.UserID {
+		return nil, fmt.Errorf("%w: cannot change your own status", common.ErrBadRequest)
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetByID(ctx, req.UserID); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		reason := req.Reason
+		if req.Status == domain.UserStatusActive {
+			reason = ""
+		}
+		if err := s.userRepository.UpdateStatus(ctx, req.UserID, req.Status, reason); err != nil {
+			return fmt.Errorf("failed to update user status: %w", err)
+		}
+
+		if req.Status != domain.UserStatusActive {
+			return endSessions(ctx, s.userRepository, s.tokenRepository, req.UserID, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.UserID)
+}
+
+func (s *userAdminService) ForcePasswordReset(ctx context.Context, req request.ForcePasswordReset) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.GetByID(ctx, req.UserID); err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if err := s.userRepository.SetPasswordResetRequired(ctx, req.UserID, true); err != nil {
+			return fmt.Errorf("failed to require password reset: %w", err)
+		}
+		return endSessions(ctx, s.userRepository, s.tokenRepository, req.UserID, time.Now())
+	})
+}
+
+func (s *userAdminService) resolve(ctx context.Context, req request.UserRole) (uint, uint, error) {
+	user, err := s.userRepository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	role, err := s.roleRepository.GetByName(ctx, req.Role)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get role: %w", err)
+	}
+	return user.ID, role.ID, nil
+}
+
+func (s *userAdminService) get(ctx context.Context, userID uint) (*response.GetUser, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.GetUserResponse(), nil
+}
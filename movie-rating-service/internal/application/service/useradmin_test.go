@@ -0,0 +1,186 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"movie-rating-service/mocks"
+	"testing"
+)
+
+type UserAdminServiceTest struct {
+	suite.Suite
+	service *userAdminService
+	tx      *mocks.TxManager
+	u       *mocks.UserRepository
+	r       *mocks.RoleRepository
+	t       *mocks.TokenRepository
+}
+
+func (s *UserAdminServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.u = new(mocks.UserRepository)
+	s.r = new(mocks.RoleRepository)
+	s.t = new(mocks.TokenRepository)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	s.service = &userAdminService{txManager: s.tx, userRepository: s.u, roleRepository: s.r, tokenRepository: s.t}
+}
+
+func Test_RunUserAdminServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UserAdminServiceTest))
+}
+
+func (s *UserAdminServiceTest) TestList_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	users := []domain.User{{Model: gorm.Model{ID: 1}, Username: "alice", Roles: []domain.Role{{Name: "user"}}}}
+	s.u.On("List", ctx, repository.UserFilter{Query: "ali", Status: "active", Limit: 20}).Return(users, int64(1), nil).Once()
+
+	res, err := s.service.List(ctx, request.ListUsers{Query: "ali", Status: "active"})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Users, 1)
+	assert.Equal(t, []string{"user"}, res.Users[0].Roles)
+	assert.Equal(t, int64(1), res.Pagination.Total)
+	s.u.AssertExpectations(t)
+}
+
+func (s *UserAdminServiceTest) TestGrantRole_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil)
+	s.r.On("GetByName", ctx, "curator").Return(&domain.Role{ID: 3, Name: "curator"}, nil).Once()
+	s.u.On("AddRole", ctx, uint(2), uint(3)).Return(nil).Once()
+
+	res, err := s.service.GrantRole(ctx, request.UserRole{ActorID: 1, UserID: 2, Role: "curator"})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+	s.u.AssertExpectations(t)
+	s.r.AssertExpectations(t)
+}
+
+func (s *UserAdminServiceTest) TestGrantRole_Error_Unknown_Role() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil).Once()
+	s.r.On("GetByName", ctx, "root").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	res, err := s.service.GrantRole(ctx, request.UserRole{ActorID: 1, UserID: 2, Role: "root"})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, res)
+	s.u.AssertNotCalled(t, "AddRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserAdminServiceTest) TestRevokeRole_Revokes_Access_Tokens() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil)
+	s.r.On("GetByName", ctx, "admin").Return(&domain.Role{ID: 1, Name: "admin"}, nil).Once()
+	s.u.On("RemoveRole", ctx, uint(2), uint(1)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(2), mock.Anything).Return(nil).Once()
+
+	res, err := s.service.RevokeRole(ctx, request.UserRole{ActorID: 1, UserID: 2, Role: "admin"})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+	s.u.AssertExpectations(t)
+}
+
+func (s *UserAdminServiceTest) TestRevokeRole_Error_Own_Roles() {
+	t := s.T()
+	ctx := context.TODO()
+
+	res, err := s.service.RevokeRole(ctx, request.UserRole{ActorID: 1, UserID: 1, Role: "admin"})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.Nil(t, res)
+	s.u.AssertNotCalled(t, "RemoveRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *UserAdminServiceTest) TestUpdateStatus_Ban_Ends_Sessions() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil)
+	s.u.On("UpdateStatus", ctx, uint(2), domain.UserStatusBanned, "spam").Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(2)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(2), mock.Anything).Return(nil).Once()
+
+	res, err := s.service.UpdateStatus(ctx, request.UpdateUserStatus{ActorID: 1, UserID: 2, Status: domain.UserStatusBanned, Reason: "spam"})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, res)
+	s.u.AssertExpectations(t)
+	s.t.AssertExpectations(t)
+}
+
+func (s *UserAdminServiceTest) TestUpdateStatus_Reactivate_Clears_Reason() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil)
+	s.u.On("UpdateStatus", ctx, uint(2), domain.UserStatusActive, "").Return(nil).Once()
+
+	_, err := s.service.UpdateStatus(ctx, request.UpdateUserStatus{ActorID: 1, UserID: 2, Status: domain.UserStatusActive, Reason: "appeal"})
+
+	assert.NoError(t, err)
+	s.t.AssertNotCalled(t, "RevokeUserRefreshTokens", mock.Anything, mock.Anything)
+}
+
+func (s *UserAdminServiceTest) TestUpdateStatus_Error_Own_Status() {
+	t := s.T()
+	ctx := context.TODO()
+
+	res, err := s.service.UpdateStatus(ctx, request.UpdateUserStatus{ActorID: 1, UserID: 1, Status: domain.UserStatusDisabled})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.Nil(t, res)
+}
+
+func (s *UserAdminServiceTest) TestForcePasswordReset_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil).Once()
+	s.u.On("SetPasswordResetRequired", ctx, uint(2), true).Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(2)).Return(nil).Once()
+	s.u.On("RevokeTokens", ctx, uint(2), mock.Anything).Return(nil).Once()
+
+	err := s.service.ForcePasswordReset(ctx, request.ForcePasswordReset{UserID: 2})
+
+	assert.NoError(t, err)
+	s.u.AssertExpectations(t)
+	s.t.AssertExpectations(t)
+}
+
+func (s *UserAdminServiceTest) TestForcePasswordReset_Error_Failed_To_Revoke() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.u.On("GetByID", ctx, uint(2)).Return(&domain.User{Model: gorm.Model{ID: 2}}, nil).Once()
+	s.u.On("SetPasswordResetRequired", ctx, uint(2), true).Return(nil).Once()
+	s.t.On("RevokeUserRefreshTokens", ctx, uint(2)).Return(errors.New("there is an error")).Once()
+
+	err := s.service.ForcePasswordReset(ctx, request.ForcePasswordReset{UserID: 2})
+
+	assert.ErrorContains(t, err, "failed to revoke refresh tokens: there is an error")
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/application/models/response"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/repository"
+	"slices"
+	"time"
+)
+
+// maxWatchlistItems caps a watchlist, so that it can always be reordered in one request.
+const maxWatchlistItems = 1000
+
+// WatchlistService keeps the movies users intend to see. A movie leaves the watchlist by itself when
+// the user rates it, see RatingService.Create.
+type WatchlistService interface {
+	// Add puts the movie at the end of the watchlist; adding a movie that is already there changes nothing.
+	Add(ctx context.Context, req request.AddToWatchlist) error
+	Remove(ctx context.Context, req request.RemoveFromWatchlist) error
+	// Reorder moves the given movies to the top of the watchlist in the given order. The movies left
+	// out follow in their current order.
+	Reorder(ctx context.Context, req request.ReorderWatchlist) error
+	List(ctx context.Context, req request.ListWatchlist) (*response.ListWatchlist, error)
+}
+
+type watchlistService struct {
+	txManager           db.TxManager
+	watchlistRepository repository.WatchlistRepository
+	movieRepository     repository.MovieRepository
+}
+
+func NewWatchlistService(txManager db.TxManager, watchlistRepository repository.WatchlistRepository, movieRepository repository.MovieRepository) WatchlistService {
+	return &watchlistService{txManager: txManager, watchlistRepository: watchlistRepository, movieRepository: movieRepository}
+}
+
+func (s *watchlistService) Add(ctx context.Context, req request.AddToWatchlist) error {
+	if _, err := s.movieRepository.Get(ctx, req.MovieID); err != nil {
+		return fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Otherwise concurrent adds could all pass the count below.
+		if err := s.watchlistRepository.Lock(ctx, req.UserID); err != nil {
+			return fmt.Errorf("failed to lock watchlist: %w", err)
+		}
+		count, err := s.watchlistRepository.Count(ctx, req.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to count watchlist: %w", err)
+		}
+		if count >= maxWatchlistItems {
+			return fmt.Errorf("%w: a watchlist holds at most %d movies", common.ErrBadRequest, maxWatchlistItems)
+		}
+
+		added, err := s.watchlistRepository.Add(ctx, req.UserID, req.MovieID)
+		if err != nil {
+			return fmt.Errorf("failed to add to watchlist: %w", err)
+		}
+		if !added {
+			return nil
+		}
+		if err = s.movieRepository.UpdateWatchlistCount(ctx, req.MovieID, 1); err != nil {
+			return fmt.Errorf("failed to update watchlist count: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *watchlistService) Remove(ctx context.Context, req request.RemoveFromWatchlist) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		removed, err := removeFromWatchlist(ctx, s.watchlistRepository, s.movieRepository, req.UserID, req.MovieID)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("movie is not on the watchlist: %w", gorm.ErrRecordNotFound)
+		}
+		return nil
+	})
+}
+
+func (s *watchlistService) Reorder(ctx context.Context, req request.ReorderWatchlist) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.watchlistRepository.ListMovieIDs(ctx, req.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get watchlist: %w", err)
+		}
+
+		order, err := moveToFront(current, req.MovieIDs, "the watchlist")
+		if err != nil {
+			return err
+		}
+
+		if err = s.watchlistRepository.Reorder(ctx, req.UserID, order); err != nil {
+			return fmt.Errorf("failed to reorder watchlist: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *watchlistService) List(ctx context.Context, req request.ListWatchlist) (*response.ListWatchlist, error) {
+	limit, offset, err := decodePage(req.Pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.watchlistRepository.List(ctx, repository.WatchlistFilter{
+		UserID:     req.UserID,
+		Genre:      req.Genre,
+		Released:   req.Released,
+		ReleasedBy: time.Now().Year(),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchlist: %w", err)
+	}
+
+	resp := &response.ListWatchlist{Pagination: encodePage(limit, offset, total)}
+	resp.Items = make([]response.WatchlistItem, len(items))
+	for i, item := range items {
+		resp.Items[i] = *item.GetWatchlistItemResponse()
+	}
+	return resp, nil
+}
+
+// removeFromWatchlist takes the movie off the user's watchlist and out of the movie's watchlist
+// count. It reports false if the movie was not on the watchlist.
+func removeFromWatchlist(ctx context.Context, watchlistRepository repository.WatchlistRepository, movieRepository repository.MovieRepository, userID, movieID uint) (bool, error) {
+	removed, err := watchlistRepository.Remove(ctx, userID, movieID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove from watchlist: %w", err)
+	}
+	if !removed {
+		return false, nil
+	}
+	if err = movieRepository.UpdateWatchlistCount(ctx, movieID, -1); err != nil {
+		return false, fmt.Errorf("failed to update watchlist count: %w", err)
+	}
+	return true, nil
+}
+
+// clearWatchlist empties the user's watchlist when the account goes away.
+func clearWatchlist(ctx context.Context, watchlistRepository repository.WatchlistRepository, movieRepository repository.MovieRepository, userID uint) error {
+	movieIDs, err := watchlistRepository.RemoveAll(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear watchlist: %w", err)
+	}
+	// Sorted, so that concurrent deletions lock the movie rows in the same order.
+	slices.Sort(movieIDs)
+	for _, movieID := range movieIDs {
+		if err = movieRepository.UpdateWatchlistCount(ctx, movieID, -1); err != nil {
+			return fmt.Errorf("failed to update watchlist count of movie %d: %w", movieID, err)
+		}
+	}
+	return nil
+}
+
+// moveToFront returns current with the movies of front moved to the beginning, in the order of
+// front. Every movie of front has to be in current, which is named by where in the error.
+func moveToFront(current, front []uint, where string) ([]uint, error) {
+	for _, movieID := range front {
+		if !slices.Contains(current, movieID) {
+			return nil, fmt.Errorf("%w: movie %d is not on %s", common.ErrBadRequest, movieID, where)
+		}
+	}
+	order := slices.Clone(front)
+	for _, movieID := range current {
+		if !slices.Contains(front, movieID) {
+			order = append(order, movieID)
+		}
+	}
+	return order, nil
+}
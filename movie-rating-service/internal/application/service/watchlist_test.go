@@ -0,0 +1,200 @@
+//go:build unit_test
+
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/application/models/request"
+	"movie-rating-service/internal/common"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/repository"
+	"movie-rating-service/mocks"
+	"testing"
+	"time"
+)
+
+type WatchlistServiceTest struct {
+	suite.Suite
+	service *watchlistService
+	tx      *mocks.TxManager
+	w       *mocks.WatchlistRepository
+	m       *mocks.MovieRepository
+}
+
+func (s *WatchlistServiceTest) SetupTest() {
+	s.tx = new(mocks.TxManager)
+	s.w = new(mocks.WatchlistRepository)
+	s.m = new(mocks.MovieRepository)
+
+	s.tx.On("WithinTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).Maybe()
+
+	s.service = NewWatchlistService(s.tx, s.w, s.m).(*watchlistService)
+}
+
+func Test_RunWatchlistServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WatchlistServiceTest))
+}
+
+func (s *WatchlistServiceTest) TestAdd_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.w.On("Lock", ctx, uint(1)).Return(nil).Once()
+	s.w.On("Count", ctx, uint(1)).Return(int64(3), nil).Once()
+	s.w.On("Add", ctx, uint(1), uint(42)).Return(true, nil).Once()
+	s.m.On("UpdateWatchlistCount", ctx, uint(42), 1).Return(nil).Once()
+
+	err := s.service.Add(ctx, request.AddToWatchlist{UserID: 1, MovieID: 42})
+
+	assert.NoError(t, err)
+	s.w.AssertExpectations(t)
+	s.m.AssertExpectations(t)
+}
+
+func (s *WatchlistServiceTest) TestAdd_Already_On_Watchlist() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.w.On("Lock", ctx, uint(1)).Return(nil).Once()
+	s.w.On("Count", ctx, uint(1)).Return(int64(3), nil).Once()
+	s.w.On("Add", ctx, uint(1), uint(42)).Return(false, nil).Once()
+
+	err := s.service.Add(ctx, request.AddToWatchlist{UserID: 1, MovieID: 42})
+
+	assert.NoError(t, err)
+	s.m.AssertNotCalled(t, "UpdateWatchlistCount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestAdd_Error_Movie_Not_Found() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	err := s.service.Add(ctx, request.AddToWatchlist{UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	s.w.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestAdd_Error_Watchlist_Full() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.w.On("Lock", ctx, uint(1)).Return(nil).Once()
+	s.w.On("Count", ctx, uint(1)).Return(int64(maxWatchlistItems), nil).Once()
+
+	err := s.service.Add(ctx, request.AddToWatchlist{UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	s.w.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestAdd_Error_Lock() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.m.On("Get", ctx, uint(42)).Return(&domain.Movie{Model: gorm.Model{ID: 42}}, nil).Once()
+	s.w.On("Lock", ctx, uint(1)).Return(errors.New("there is an error")).Once()
+
+	err := s.service.Add(ctx, request.AddToWatchlist{UserID: 1, MovieID: 42})
+
+	assert.ErrorContains(t, err, "failed to lock watchlist")
+	s.w.AssertNotCalled(t, "Count", mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestRemove_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.w.On("Remove", ctx, uint(1), uint(42)).Return(true, nil).Once()
+	s.m.On("UpdateWatchlistCount", ctx, uint(42), -1).Return(nil).Once()
+
+	err := s.service.Remove(ctx, request.RemoveFromWatchlist{UserID: 1, MovieID: 42})
+
+	assert.NoError(t, err)
+	s.m.AssertExpectations(t)
+}
+
+func (s *WatchlistServiceTest) TestRemove_Error_Not_On_Watchlist() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.w.On("Remove", ctx, uint(1), uint(42)).Return(false, nil).Once()
+
+	err := s.service.Remove(ctx, request.RemoveFromWatchlist{UserID: 1, MovieID: 42})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	s.m.AssertNotCalled(t, "UpdateWatchlistCount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestReorder_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.w.On("ListMovieIDs", ctx, uint(1)).Return([]uint{3, 5, 7, 9}, nil).Once()
+	s.w.On("Reorder", ctx, uint(1), []uint{9, 5, 3, 7}).Return(nil).Once()
+
+	err := s.service.Reorder(ctx, request.ReorderWatchlist{UserID: 1, MovieIDs: []uint{9, 5}})
+
+	assert.NoError(t, err)
+	s.w.AssertExpectations(t)
+}
+
+func (s *WatchlistServiceTest) TestReorder_Error_Not_On_Watchlist() {
+	t := s.T()
+	ctx := context.TODO()
+
+	s.w.On("ListMovieIDs", ctx, uint(1)).Return([]uint{3, 5}, nil).Once()
+
+	err := s.service.Reorder(ctx, request.ReorderWatchlist{UserID: 1, MovieIDs: []uint{5, 8}})
+
+	assert.ErrorIs(t, err, common.ErrBadRequest)
+	assert.ErrorContains(t, err, "movie 8 is not on the watchlist")
+	s.w.AssertNotCalled(t, "Reorder", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *WatchlistServiceTest) TestList_Success() {
+	t := s.T()
+	ctx := context.TODO()
+
+	released := true
+	addedAt := time.Now()
+	s.w.On("List", ctx, repository.WatchlistFilter{
+		UserID:     1,
+		Genre:      "Crime",
+		Released:   &released,
+		ReleasedBy: time.Now().Year(),
+		Limit:      2,
+		Offset:     0,
+	}).Return([]domain.WatchlistItem{
+		{CreatedAt: addedAt, MovieID: 4, Position: 0, Movie: domain.Movie{Model: gorm.Model{ID: 4}, Title: "Heat", WatchlistCount: 12}},
+		{CreatedAt: addedAt, MovieID: 6, Position: 2, Movie: domain.Movie{Model: gorm.Model{ID: 6}, Title: "Ronin"}},
+	}, int64(3), nil).Once()
+
+	res, err := s.service.List(ctx, request.ListWatchlist{
+		Pagination: request.Pagination{Limit: 2},
+		UserID:     1,
+		Genre:      "Crime",
+		Released:   &released,
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, res.Items, 2)
+	assert.Equal(t, "Heat", res.Items[0].Movie.Title)
+	assert.Equal(t, int64(12), res.Items[0].Movie.WatchlistCount)
+	assert.Equal(t, addedAt, res.Items[0].AddedAt)
+	assert.Equal(t, 2, res.Items[1].Position)
+	assert.Equal(t, int64(3), res.Pagination.Total)
+	assert.NotEmpty(t, res.Pagination.NextCursor)
+}
@@ -24,6 +24,18 @@ func ErrorHandler() func(ctx *fiber.Ctx, err error) error {
 		if errors.As(err, &validator.ValidationErrors{}) {
 			return ctx.Status(fiber.StatusBadRequest).JSON(response.Error("Bad request.", err.Error()))
 		}
+		if errors.Is(err, ErrBadRequest) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.Error("Bad request.", err.Error()))
+		}
+		if errors.Is(err, ErrUnauthorized) {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.Error("Unauthorized.", err.Error()))
+		}
+		if errors.Is(err, ErrForbidden) {
+			return ctx.Status(fiber.StatusForbidden).JSON(response.Error("Forbidden.", err.Error()))
+		}
+		if errors.Is(err, ErrTooManyRequests) {
+			return ctx.Status(fiber.StatusTooManyRequests).JSON(response.Error("Too many requests.", err.Error()))
+		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ctx.Status(fiber.StatusNotFound).JSON(response.Error("Record not found.", err.Error()))
 		}
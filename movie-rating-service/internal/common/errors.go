@@ -0,0 +1,18 @@
+package common
+
+import "errors"
+
+// ErrBadRequest is wrapped by services when the caller sent input that passed
+// struct validation but is still unusable (e.g. a malformed cursor).
+var ErrBadRequest = errors.New("bad request")
+
+// ErrUnauthorized is wrapped by services when credentials or tokens are missing, invalid or revoked.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrForbidden is wrapped by services when the caller is known but not allowed to do something,
+// e.g. log in to a disabled account.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrTooManyRequests is wrapped by services when the caller has to wait before trying again,
+// e.g. after too many failed logins.
+var ErrTooManyRequests = errors.New("too many requests")
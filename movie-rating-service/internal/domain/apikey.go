@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"movie-rating-service/internal/application/models/response"
+	"strings"
+	"time"
+)
+
+// APIKey authenticates scripts and other services as its user, granting at most the permissions in
+// Scopes, a space-separated list. Only the SHA-256 hash of the key is stored; Prefix is its first
+// characters, kept to tell keys apart. CreatedByID is the user who created the key, nil for keys
+// from before it was recorded or whose creator was deleted.
+type APIKey struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UserID      uint `gorm:"not null;index"`
+	CreatedByID *uint
+	Name        string `gorm:"not null"`
+	Prefix      string `gorm:"not null"`
+	KeyHash     string `gorm:"not null;unique"`
+	Scopes      string `gorm:"not null"`
+	ExpiresAt   time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+func (k *APIKey) GetAPIKeyResponse() response.APIKey {
+	return response.APIKey{
+		ID:          k.ID,
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		Scopes:      k.ScopeList(),
+		CreatedByID: k.CreatedByID,
+		CreatedAt:   k.CreatedAt,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		RevokedAt:   k.RevokedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+const (
+	AuditActionUserExported = "user.exported"
+	AuditActionUserErased   = "user.erased"
+)
+
+// AuditLog records a privacy-relevant action. It refers to users by id only and holds no personal
+// data, so an entry outlives the erasure of the user it is about. Details is a JSON object.
+type AuditLog struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	ActorID   uint   `gorm:"not null"`
+	Action    string `gorm:"not null"`
+	SubjectID uint   `gorm:"not null;index"`
+	Details   string `gorm:"type:jsonb"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+const (
+	LoginAttemptScopeUsername = "username"
+	LoginAttemptScopeIP       = "ip"
+)
+
+// LoginAttemptKey identifies what failed logins are counted for: a username, or a client address.
+type LoginAttemptKey struct {
+	Scope string
+	Key   string
+}
+
+// LoginAttempt counts the recent failed logins of a key. While LockedUntil is in the future, logins
+// for the key are refused without checking the password. The row is forgotten at ExpiresAt.
+type LoginAttempt struct {
+	Scope        string    `gorm:"primaryKey"`
+	Key          string    `gorm:"primaryKey"`
+	Failures     int       `gorm:"not null"`
+	LastFailedAt time.Time `gorm:"not null"`
+	LockedUntil  *time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
@@ -7,24 +7,65 @@ import (
 
 type Movie struct {
 	gorm.Model
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Genre       string  `json:"genre"`
-	Director    string  `json:"director"`
-	Year        int     `json:"year"`
-	Rating      float64 `json:"rating"`
-	RatingCount int64   `json:"rating_count"`
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	Genre          string  `json:"genre"`
+	Director       string  `json:"director"`
+	Year           int     `json:"year"`
+	Rating         float64 `json:"rating"`
+	RatingCount    int64   `json:"rating_count"`
+	WeightedRating float64 `json:"weighted_rating" gorm:"not null;default:0;index"`
+	WatchlistCount int64   `json:"watchlist_count" gorm:"not null;default:0"`
+}
+
+// MovieSearchHit is a movie matched by full-text search together with its relevance and highlighted fragments.
+type MovieSearchHit struct {
+	Movie
+	Rank      float64
+	Highlight string
+	Snippet   string
+}
+
+// RatingAggregate compares the aggregates stored on a movie with the ones derived from its ratings.
+type RatingAggregate struct {
+	MovieID        uint
+	StoredRating   float64
+	StoredCount    int64
+	StoredWeighted float64
+	ActualCount    int64
+	ActualSum      float64
+}
+
+func (a *RatingAggregate) ActualRating() float64 {
+	if a.ActualCount == 0 {
+		return 0
+	}
+	return a.ActualSum / float64(a.ActualCount)
+}
+
+// WeightedRating is the IMDb-style Bayesian average (v·R + m·C) / (v + m) written in terms of the
+// score sum, where v is the number of ratings, R their mean, C the prior mean and m the vote threshold.
+// The SQL in the movie repository applies the same formula when ratings change.
+func WeightedRating(sum float64, count int64, priorMean float64, minVotes int) float64 {
+	votes := float64(count + int64(minVotes))
+	if votes == 0 {
+		return 0
+	}
+	return (sum + float64(minVotes)*priorMean) / votes
 }
 
 func (m *Movie) GetMovieResponse() *response.GetMovie {
 	return &response.GetMovie{
-		Title:       m.Title,
-		Description: m.Description,
-		Genre:       m.Genre,
-		Director:    m.Director,
-		Year:        m.Year,
-		Rating:      m.Rating,
-		RatingCount: m.RatingCount,
+		ID:             m.ID,
+		Title:          m.Title,
+		Description:    m.Description,
+		Genre:          m.Genre,
+		Director:       m.Director,
+		Year:           m.Year,
+		Rating:         m.Rating,
+		RatingCount:    m.RatingCount,
+		WeightedRating: m.WeightedRating,
+		WatchlistCount: m.WatchlistCount,
 	}
 }
 
@@ -33,3 +74,19 @@ func (m *Movie) CreateMovieResponse() *response.CreateMovie {
 		ID: m.ID,
 	}
 }
+
+func (m *Movie) SuggestMovieResponse() *response.SuggestMovie {
+	return &response.SuggestMovie{
+		ID:    m.ID,
+		Title: m.Title,
+	}
+}
+
+func (h *MovieSearchHit) SearchMovieResponse() *response.SearchMovie {
+	return &response.SearchMovie{
+		GetMovie:  *h.GetMovieResponse(),
+		Rank:      h.Rank,
+		Highlight: h.Highlight,
+		Snippet:   h.Snippet,
+	}
+}
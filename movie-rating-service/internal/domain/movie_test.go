@@ -0,0 +1,44 @@
+//go:build unit_test
+
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type MovieTest struct {
+	suite.Suite
+}
+
+func Test_RunMovieTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieTest))
+}
+
+func (s *MovieTest) TestWeightedRating() {
+	t := s.T()
+
+	tests := []struct {
+		name      string
+		sum       float64
+		count     int64
+		priorMean float64
+		minVotes  int
+		want      float64
+	}{
+		{name: "zero votes is the prior", sum: 0, count: 0, priorMean: 3.2, minVotes: 10, want: 3.2},
+		{name: "zero votes without a threshold", sum: 0, count: 0, priorMean: 3.2, minVotes: 0, want: 0},
+		{name: "single perfect score below min votes", sum: 5, count: 1, priorMean: 3, minVotes: 10, want: 35.0 / 11},
+		{name: "votes below min votes stay near the prior", sum: 4 * 4.8, count: 4, priorMean: 3, minVotes: 10, want: 49.2 / 14},
+		{name: "votes equal to min votes meet halfway", sum: 10 * 5, count: 10, priorMean: 3, minVotes: 10, want: 4},
+		{name: "many votes approach the mean", sum: 10000 * 4.8, count: 10000, priorMean: 3, minVotes: 10, want: 48030.0 / 10010},
+		{name: "no threshold is the plain mean", sum: 9, count: 2, priorMean: 3, minVotes: 0, want: 4.5},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, WeightedRating(tt.sum, tt.count, tt.priorMean, tt.minVotes), 1e-9, tt.name)
+	}
+
+	// A single 5.0 must not outrank thousands of 4.8s.
+	assert.Less(t, WeightedRating(5, 1, 3, 10), WeightedRating(10000*4.8, 10000, 3, 10))
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"movie-rating-service/internal/application/models/response"
+	"time"
+)
+
+const (
+	MovieListVisibilityPublic   = "public"
+	MovieListVisibilityUnlisted = "unlisted"
+	MovieListVisibilityPrivate  = "private"
+)
+
+// MovieList is a named, ordered list of movies curated by its owner and the editors the owner
+// invited. Public lists can be browsed, unlisted ones are only reached by their slug, and private
+// ones are only seen by the owner and the editors. The slug is generated by the database.
+type MovieList struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	OwnerID      uint   `gorm:"not null;index"`
+	Name         string `gorm:"not null"`
+	Description  string `gorm:"not null"`
+	Visibility   string `gorm:"not null"`
+	Slug         string `gorm:"<-:false"`
+	ClonedFromID *uint
+
+	Owner      User            `gorm:"foreignKey:OwnerID"`
+	Editors    []User          `gorm:"many2many:movie_list_editors;joinForeignKey:ListID;joinReferences:UserID"`
+	Items      []MovieListItem `gorm:"foreignKey:ListID"`
+	ClonedFrom *MovieList      `gorm:"foreignKey:ClonedFromID"`
+}
+
+// MovieListSummary is a list as shown when browsing, together with its number of items.
+type MovieListSummary struct {
+	MovieList
+	ItemCount int64
+}
+
+// MovieListItem is a movie on a list. Position orders the list, lowest first; it is only compared
+// within one list and may have gaps.
+type MovieListItem struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	ListID    uint   `gorm:"not null;uniqueIndex:uni_movie_list_item"`
+	MovieID   uint   `gorm:"not null;uniqueIndex:uni_movie_list_item"`
+	Position  int    `gorm:"not null"`
+	Note      string `gorm:"not null"`
+	AddedByID *uint
+
+	Movie Movie `gorm:"foreignKey:MovieID"`
+}
+
+// IsEditor reports whether the user was invited to edit the list; the owner is not an editor.
+func (l *MovieList) IsEditor(userID uint) bool {
+	for _, editor := range l.Editors {
+		if editor.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *MovieList) GetMovieListResponse(itemCount int64) *response.MovieList {
+	resp := &response.MovieList{
+		ID:           l.ID,
+		Name:         l.Name,
+		Description:  l.Description,
+		Visibility:   l.Visibility,
+		Slug:         l.Slug,
+		Owner:        l.Owner.Username,
+		ItemCount:    itemCount,
+		ClonedFromID: l.ClonedFromID,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}
+	for _, editor := range l.Editors {
+		resp.Editors = append(resp.Editors, editor.Username)
+	}
+	return resp
+}
+
+func (l *MovieList) ExportMovieListResponse() *response.ExportedMovieList {
+	resp := &response.ExportedMovieList{
+		ID:          l.ID,
+		Name:        l.Name,
+		Description: l.Description,
+		Visibility:  l.Visibility,
+		CreatedAt:   l.CreatedAt,
+		Items:       make([]response.ExportedMovieListItem, len(l.Items)),
+	}
+	for i, item := range l.Items {
+		resp.Items[i] = response.ExportedMovieListItem{
+			MovieID:    item.MovieID,
+			MovieTitle: item.Movie.Title,
+			Position:   item.Position,
+			Note:       item.Note,
+		}
+	}
+	return resp
+}
+
+func (i *MovieListItem) GetMovieListItemResponse() *response.MovieListItem {
+	return &response.MovieListItem{
+		Position: i.Position,
+		Note:     i.Note,
+		AddedAt:  i.CreatedAt,
+		Movie:    *i.Movie.GetMovieResponse(),
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// MovieRatingBucket counts a movie's ratings rounded to the nearest half star. Bucket is the
+// rounded score doubled, so 0 stands for 0.0 and 10 for 5.0.
+type MovieRatingBucket struct {
+	MovieID uint  `gorm:"primaryKey;autoIncrement:false"`
+	Bucket  int   `gorm:"primaryKey;autoIncrement:false"`
+	Count   int64 `gorm:"not null;default:0"`
+}
+
+// MovieRatingPeriod aggregates a movie's ratings by the month they were first submitted.
+// Sum and SumSquares let mean and standard deviation be derived without reading the ratings.
+type MovieRatingPeriod struct {
+	MovieID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Period     time.Time `gorm:"primaryKey;type:date"`
+	Count      int64     `gorm:"not null;default:0"`
+	Sum        float64   `gorm:"not null;default:0"`
+	SumSquares float64   `gorm:"not null;default:0"`
+}
+
+func RatingBucket(score float64) int {
+	return int(math.Round(score * 2))
+}
+
+func RatingPeriod(ratedAt time.Time) time.Time {
+	ratedAt = ratedAt.UTC()
+	return time.Date(ratedAt.Year(), ratedAt.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// UserIdentity links a user to the subject of an OpenID Connect provider.
+type UserIdentity struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UserID      uint   `gorm:"not null;index"`
+	Issuer      string `gorm:"not null"`
+	Subject     string `gorm:"not null"`
+	LastLoginAt *time.Time
+}
+
+// OIDCLogin is a login that was sent to the provider, found again by its State when the provider
+// redirects back.
+type OIDCLogin struct {
+	State        string `gorm:"primaryKey"`
+	Nonce        string `gorm:"not null"`
+	CodeVerifier string `gorm:"not null"`
+	ExpiresAt    time.Time
+}
+
+func (OIDCLogin) TableName() string {
+	return "oidc_logins"
+}
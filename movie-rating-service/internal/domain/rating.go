@@ -7,15 +7,23 @@ import (
 
 type Rating struct {
 	gorm.Model
-	UserID  uint    `json:"user_id" gorm:"index:,unique,composite:uni_user_movie"`
-	MovieID uint    `json:"movie_id" gorm:"index:,unique,composite:uni_user_movie"`
-	Score   float64 `json:"score"`
-	Review  string  `json:"review"`
+	UserID       uint    `json:"user_id" gorm:"index:,unique,composite:uni_user_movie"`
+	MovieID      uint    `json:"movie_id" gorm:"index:,unique,composite:uni_user_movie;index:idx_ratings_movie_id"`
+	Score        float64 `json:"score"`
+	Review       string  `json:"review"`
+	HelpfulCount int64   `json:"helpful_count" gorm:"not null;default:0"`
 
 	Movie Movie `json:"-" gorm:"foreignKey:MovieID"`
 	User  User  `json:"-" gorm:"foreignKey:UserID"`
 }
 
+// RatingHelpfulVote records that a user found someone else's review helpful; each user can vote once per rating.
+type RatingHelpfulVote struct {
+	gorm.Model
+	RatingID uint `json:"rating_id" gorm:"index:,unique,composite:uni_rating_user"`
+	UserID   uint `json:"user_id" gorm:"index:,unique,composite:uni_rating_user"`
+}
+
 func (r *Rating) CreateRatingResponse() *response.CreateRating {
 	return &response.CreateRating{
 		ID: r.ID,
@@ -44,3 +52,34 @@ func (r *Rating) GetRatingByUserIdResponse() *response.Ratings {
 		},
 	}
 }
+
+func (r *Rating) GetMovieRatingResponse() *response.MovieRating {
+	return &response.MovieRating{
+		ID:           r.ID,
+		Username:     r.User.Username,
+		Score:        r.Score,
+		Review:       r.Review,
+		HelpfulCount: r.HelpfulCount,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func (r *Rating) ExportRatingResponse() *response.ExportedRating {
+	return &response.ExportedRating{
+		ID:           r.ID,
+		MovieID:      r.MovieID,
+		MovieTitle:   r.Movie.Title,
+		Score:        r.Score,
+		Review:       r.Review,
+		HelpfulCount: r.HelpfulCount,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+func (v *RatingHelpfulVote) ExportHelpfulVoteResponse() *response.ExportedHelpfulVote {
+	return &response.ExportedHelpfulVote{
+		RatingID:  v.RatingID,
+		CreatedAt: v.CreatedAt,
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleCurator   = "curator"
+	RoleUser      = "user"
+)
+
+// Permissions are checked per route; roles only group them. The role to permission mapping is
+// seeded by the migrations.
+const (
+	PermissionMovieWrite      = "movie:write"
+	PermissionReviewWrite     = "review:write"
+	PermissionReviewModerate  = "review:moderate"
+	PermissionUserRead        = "user:read"
+	PermissionUserManage      = "user:manage"
+	PermissionRatingReconcile = "rating:reconcile"
+)
+
+type Role struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null;unique"`
+	Description string
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+}
+
+type Permission struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null;unique"`
+	Description string
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// SigningKey is one key of the JWT key ring. It signs access tokens between ActivatesAt and
+// RetiresAt and is published for verification until ExpiresAt, i.e. until the last token it signed
+// has expired. The private key is stored encrypted.
+type SigningKey struct {
+	KID         string `gorm:"primaryKey"`
+	Algorithm   string `gorm:"not null"`
+	PrivateKey  []byte `gorm:"not null"`
+	PublicKey   []byte `gorm:"not null"`
+	CreatedAt   time.Time
+	ActivatesAt time.Time `gorm:"not null"`
+	RetiresAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
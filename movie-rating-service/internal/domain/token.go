@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// RefreshToken is one link of a rotating refresh token chain. Only the SHA-256 hash of the token is
+// stored. Every rotation revokes the presented token and issues a new one in the same family, so
+// presenting a revoked token again means it was replayed and the whole family is revoked.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	FamilyID  string    `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+}
+
+// RevokedToken denylists an access token by its jti until the token would have expired anyway.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// RecoveryCode is a one-time replacement for a TOTP code, for users who lost their authenticator.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"not null"`
+	UsedAt    *time.Time
+}
@@ -3,31 +3,81 @@ package domain
 import (
 	"gorm.io/gorm"
 	"movie-rating-service/internal/application/models/response"
+	"slices"
+	"time"
 )
 
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+	UserStatusBanned   = "banned"
+)
+
+// User is an account. An account that is not active cannot log in, and TokensValidAfter revokes
+// every access token issued in or before its second, e.g. when the account is banned. EmailVerifiedAt is set once
+// the user proved they receive mail at Email, and cleared when Email changes. TOTPSecret is encrypted
+// and only counts once TOTPEnabledAt is set, after the user confirmed a first code; TOTPLastStep is
+// the time step of the last accepted code.
 type User struct {
 	gorm.Model
-	Username string `json:"username" gorm:"unique"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Address  string `json:"address"`
-	IsAdmin  bool   `json:"is_admin"`
+	Username              string     `json:"username" gorm:"unique"`
+	Password              string     `json:"password"`
+	Name                  string     `json:"name"`
+	Surname               string     `json:"surname"`
+	Email                 string     `json:"email"`
+	Phone                 string     `json:"phone"`
+	Address               string     `json:"address"`
+	Roles                 []Role     `json:"roles" gorm:"many2many:user_roles"`
+	Status                string     `json:"status" gorm:"not null;default:active"`
+	StatusReason          string     `json:"status_reason"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	TokensValidAfter      *time.Time `json:"-"`
+	EmailVerifiedAt       *time.Time `json:"-"`
+	TOTPSecret            []byte     `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabledAt         *time.Time `json:"-" gorm:"column:totp_enabled_at"`
+	TOTPLastStep          *int64     `json:"-" gorm:"column:totp_last_step"`
+}
+
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
 }
 
 func (u *User) GetUserResponse() *response.GetUser {
 	return &response.GetUser{
-		ID:       u.ID,
-		Username: u.Username,
-		Name:     u.Name,
-		Surname:  u.Surname,
-		Email:    u.Email,
-		Phone:    u.Phone,
-		Address:  u.Address,
-		IsAdmin:  u.IsAdmin,
+		ID:                    u.ID,
+		Username:              u.Username,
+		Name:                  u.Name,
+		Surname:               u.Surname,
+		Email:                 u.Email,
+		Phone:                 u.Phone,
+		Address:               u.Address,
+		Roles:                 u.RoleNames(),
+		Permissions:           u.PermissionNames(),
+		Status:                u.Status,
+		PasswordResetRequired: u.PasswordResetRequired,
+		EmailVerified:         u.EmailVerifiedAt != nil,
+		TwoFactorEnabled:      u.TOTPEnabledAt != nil,
+	}
+}
+
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PermissionNames returns the sorted union of the permissions of all the user's roles.
+func (u *User) PermissionNames() []string {
+	names := make([]string, 0)
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			names = append(names, permission.Name)
+		}
 	}
+	slices.Sort(names)
+	return slices.Compact(names)
 }
 
 func (u *User) CreateUserResponse() *response.CreateUser {
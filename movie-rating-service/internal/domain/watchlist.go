@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"movie-rating-service/internal/application/models/response"
+	"time"
+)
+
+// WatchlistItem is a movie the user intends to see. Position orders the user's watchlist, lowest
+// first; it is only compared within one user's items and may have gaps.
+type WatchlistItem struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uint `gorm:"not null;uniqueIndex:uni_watchlist_user_movie"`
+	MovieID   uint `gorm:"not null;uniqueIndex:uni_watchlist_user_movie"`
+	Position  int  `gorm:"not null"`
+
+	Movie Movie `gorm:"foreignKey:MovieID"`
+}
+
+func (w *WatchlistItem) GetWatchlistItemResponse() *response.WatchlistItem {
+	return &response.WatchlistItem{
+		Position: w.Position,
+		AddedAt:  w.CreatedAt,
+		Movie:    *w.Movie.GetMovieResponse(),
+	}
+}
+
+func (w *WatchlistItem) ExportWatchlistItemResponse() *response.ExportedWatchlistItem {
+	return &response.ExportedWatchlistItem{
+		MovieID:    w.MovieID,
+		MovieTitle: w.Movie.Title,
+		Position:   w.Position,
+		AddedAt:    w.CreatedAt,
+	}
+}
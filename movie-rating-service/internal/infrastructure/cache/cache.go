@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a byte-oriented key/value store with per-entry expiry. Values are encoded by the caller,
+// so the same code runs against the in-process and the shared backend.
+type Cache interface {
+	// Get reports ok=false on a miss; err is only set when the backend itself failed.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Version returns key's current version, which moves on whenever key is deleted or the cache cleared.
+	Version(ctx context.Context, key string) (uint64, error)
+	// SetIfVersion stores value only while key's version still is version, so a reader that raced with
+	// a Delete does not put back what it read before. It reports whether value was stored.
+	SetIfVersion(ctx context.Context, key string, value []byte, ttl time.Duration, version uint64) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+	// Clear drops every entry written through this cache.
+	Clear(ctx context.Context) error
+	Close() error
+}
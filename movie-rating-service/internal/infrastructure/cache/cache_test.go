@@ -0,0 +1,211 @@
+//go:build unit_test
+
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+// CacheTest runs the same behaviour against every Cache implementation.
+type CacheTest struct {
+	suite.Suite
+	newCache func() Cache
+	cache    Cache
+}
+
+func (c *CacheTest) SetupTest() {
+	c.cache = c.newCache()
+}
+
+func (c *CacheTest) TearDownTest() {
+	_ = c.cache.Close()
+}
+
+func Test_RunMemoryCacheTestSuite(t *testing.T) {
+	suite.Run(t, &CacheTest{newCache: func() Cache {
+		return NewMemory(10, 0, nil)
+	}})
+}
+
+func Test_RunRedisCacheTestSuite(t *testing.T) {
+	server := miniredis.RunT(t)
+	suite.Run(t, &CacheTest{newCache: func() Cache {
+		server.FlushAll()
+		return NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:")
+	}})
+}
+
+func (c *CacheTest) TestSet_Get_Success() {
+	t := c.T()
+	ctx := context.TODO()
+
+	assert.NoError(t, c.cache.Set(ctx, "1", []byte("heat"), time.Minute))
+
+	value, ok, err := c.cache.Get(ctx, "1")
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("heat"), value)
+}
+
+func (c *CacheTest) TestGet_Miss() {
+	t := c.T()
+
+	value, ok, err := c.cache.Get(context.TODO(), "missing")
+
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, value)
+}
+
+func (c *CacheTest) TestDelete_Success() {
+	t := c.T()
+	ctx := context.TODO()
+
+	assert.NoError(t, c.cache.Set(ctx, "1", []byte("heat"), time.Minute))
+	assert.NoError(t, c.cache.Set(ctx, "2", []byte("ronin"), time.Minute))
+
+	assert.NoError(t, c.cache.Delete(ctx, "1", "3"))
+
+	_, ok, _ := c.cache.Get(ctx, "1")
+	assert.False(t, ok)
+	_, ok, _ = c.cache.Get(ctx, "2")
+	assert.True(t, ok)
+}
+
+func (c *CacheTest) TestClear_Success() {
+	t := c.T()
+	ctx := context.TODO()
+
+	assert.NoError(t, c.cache.Set(ctx, "1", []byte("heat"), time.Minute))
+	assert.NoError(t, c.cache.Set(ctx, "2", []byte("ronin"), time.Minute))
+
+	assert.NoError(t, c.cache.Clear(ctx))
+
+	_, ok, _ := c.cache.Get(ctx, "1")
+	assert.False(t, ok)
+	_, ok, _ = c.cache.Get(ctx, "2")
+	assert.False(t, ok)
+}
+
+func (c *CacheTest) TestSetIfVersion_Success() {
+	t := c.T()
+	ctx := context.TODO()
+
+	version, err := c.cache.Version(ctx, "1")
+	assert.NoError(t, err)
+
+	stored, err := c.cache.SetIfVersion(ctx, "1", []byte("heat"), time.Minute, version)
+
+	assert.NoError(t, err)
+	assert.True(t, stored)
+	value, ok, _ := c.cache.Get(ctx, "1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("heat"), value)
+}
+
+func (c *CacheTest) TestSetIfVersion_After_Delete() {
+	t := c.T()
+	ctx := context.TODO()
+
+	version, err := c.cache.Version(ctx, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, c.cache.Delete(ctx, "1"))
+
+	stored, err := c.cache.SetIfVersion(ctx, "1", []byte("heat"), time.Minute, version)
+
+	assert.NoError(t, err)
+	assert.False(t, stored)
+	_, ok, _ := c.cache.Get(ctx, "1")
+	assert.False(t, ok)
+}
+
+func (c *CacheTest) TestSetIfVersion_After_Delete_Of_Other_Key() {
+	t := c.T()
+	ctx := context.TODO()
+
+	version, err := c.cache.Version(ctx, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, c.cache.Delete(ctx, "2"))
+
+	stored, err := c.cache.SetIfVersion(ctx, "1", []byte("heat"), time.Minute, version)
+
+	assert.NoError(t, err)
+	assert.True(t, stored)
+}
+
+func (c *CacheTest) TestSetIfVersion_After_Clear() {
+	t := c.T()
+	ctx := context.TODO()
+
+	assert.NoError(t, c.cache.Delete(ctx, "1"))
+	version, err := c.cache.Version(ctx, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, c.cache.Clear(ctx))
+
+	stored, err := c.cache.SetIfVersion(ctx, "1", []byte("heat"), time.Minute, version)
+
+	assert.NoError(t, err)
+	assert.False(t, stored)
+
+	version, err = c.cache.Version(ctx, "1")
+	assert.NoError(t, err)
+	stored, err = c.cache.SetIfVersion(ctx, "1", []byte("heat"), time.Minute, version)
+	assert.NoError(t, err)
+	assert.True(t, stored)
+}
+
+func Test_RedisCache_Versions_Outlive_Clear_And_Expire(t *testing.T) {
+	server := miniredis.RunT(t)
+	c := NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "movie:")
+	defer c.Close()
+	ctx := context.TODO()
+
+	before, err := c.Version(ctx, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Delete(ctx, "1"))
+	assert.NoError(t, c.Clear(ctx))
+	assert.True(t, server.Exists("version:movie:1"))
+
+	// Once the version key expired, the key falls back to the version of the last Clear, which is
+	// still newer than anything read before the Delete.
+	server.FastForward(2 * versionTTL)
+	assert.False(t, server.Exists("version:movie:1"))
+	after, err := c.Version(ctx, "1")
+	assert.NoError(t, err)
+	assert.Greater(t, after, before)
+
+	stored, err := c.SetIfVersion(ctx, "1", []byte("heat"), 0, before)
+	assert.NoError(t, err)
+	assert.False(t, stored)
+	stored, err = c.SetIfVersion(ctx, "1", []byte("heat"), 0, after)
+	assert.NoError(t, err)
+	assert.True(t, stored)
+	assert.Equal(t, time.Duration(0), server.TTL("movie:1"))
+}
+
+func Test_RedisCache_Keys_Are_Prefixed_And_Expire(t *testing.T) {
+	server := miniredis.RunT(t)
+	c := NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "movie:")
+	defer c.Close()
+	ctx := context.TODO()
+
+	assert.NoError(t, server.Set("other", "kept"))
+	assert.NoError(t, c.Set(ctx, "1", []byte("heat"), time.Minute))
+	assert.True(t, server.Exists("movie:1"))
+
+	assert.NoError(t, c.Clear(ctx))
+	assert.True(t, server.Exists("other"))
+
+	assert.NoError(t, c.Set(ctx, "1", []byte("heat"), time.Minute))
+	server.FastForward(2 * time.Minute)
+	_, ok, err := c.Get(ctx, "1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type EvictReason string
+
+const (
+	EvictExpired     EvictReason = "expired"
+	EvictCapacity    EvictReason = "capacity"
+	EvictInvalidated EvictReason = "invalidated"
+)
+
+// LRU is a size-bounded, TTL-aware least-recently-used cache safe for concurrent use. Expired
+// entries are dropped lazily on access and periodically by a janitor goroutine until Close.
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	items   map[K]*list.Element
+	order   *list.List // front is the most recently used
+	onEvict func(key K, reason EvictReason)
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache holding at most size entries for ttl each. A positive sweepInterval
+// starts the janitor; onEvict, if not nil, is called for every entry dropped without being replaced.
+func NewLRU[K comparable, V any](size int, ttl, sweepInterval time.Duration, onEvict func(key K, reason EvictReason)) *LRU[K, V] {
+	c := &LRU[K, V]{
+		size:    max(size, 1),
+		ttl:     ttl,
+		items:   make(map[K]*list.Element),
+		order:   list.New(),
+		onEvict: onEvict,
+		stop:    make(chan struct{}),
+	}
+	if sweepInterval > 0 {
+		go c.janitor(sweepInterval)
+	}
+	return c
+}
+
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	if !entry.expiresAt.After(time.Now()) {
+		c.remove(element, EvictExpired)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value under key for the cache's default TTL.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back(), EvictCapacity)
+	}
+}
+
+// Delete drops key and reports whether it was cached.
+func (c *LRU[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if ok {
+		c.remove(element, EvictInvalidated)
+	}
+	return ok
+}
+
+// Clear drops every entry.
+func (c *LRU[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for element := c.order.Back(); element != nil; element = c.order.Back() {
+		c.remove(element, EvictInvalidated)
+	}
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Close stops the janitor. The cache stays usable afterwards; expired entries are then only
+// dropped on access.
+func (c *LRU[K, V]) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// remove must be called with mu held.
+func (c *LRU[K, V]) remove(element *list.Element, reason EvictReason) {
+	entry := c.order.Remove(element).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+	if c.onEvict != nil {
+		c.onEvict(entry.key, reason)
+	}
+}
+
+func (c *LRU[K, V]) sweep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for element := c.order.Back(); element != nil; {
+		previous := element.Prev()
+		if !element.Value.(*lruEntry[K, V]).expiresAt.After(now) {
+			c.remove(element, EvictExpired)
+		}
+		element = previous
+	}
+}
+
+func (c *LRU[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.sweep()
+		case <-c.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//go:build unit_test
+
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type LRUTest struct {
+	suite.Suite
+	evicted map[int]EvictReason
+}
+
+func (l *LRUTest) SetupTest() {
+	l.evicted = map[int]EvictReason{}
+}
+
+func (l *LRUTest) onEvict(key int, reason EvictReason) {
+	l.evicted[key] = reason
+}
+
+func Test_RunLRUTestSuite(t *testing.T) {
+	suite.Run(t, new(LRUTest))
+}
+
+func (l *LRUTest) TestGet_Success() {
+	t := l.T()
+
+	c := NewLRU[int, string](2, time.Minute, 0, l.onEvict)
+	c.Set(1, "one")
+
+	value, ok := c.Get(1)
+
+	assert.True(t, ok)
+	assert.Equal(t, "one", value)
+}
+
+func (l *LRUTest) TestSet_Evicts_Least_Recently_Used() {
+	t := l.T()
+
+	c := NewLRU[int, string](2, time.Minute, 0, l.onEvict)
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(1)
+	c.Set(3, "three")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok)
+	_, ok = c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, map[int]EvictReason{2: EvictCapacity}, l.evicted)
+}
+
+func (l *LRUTest) TestGet_Expired() {
+	t := l.T()
+
+	c := NewLRU[int, string](2, time.Minute, 0, l.onEvict)
+	c.SetWithTTL(1, "one", -time.Second)
+
+	_, ok := c.Get(1)
+
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, map[int]EvictReason{1: EvictExpired}, l.evicted)
+}
+
+func (l *LRUTest) TestDelete_Success() {
+	t := l.T()
+
+	c := NewLRU[int, string](2, time.Minute, 0, l.onEvict)
+	c.Set(1, "one")
+
+	assert.True(t, c.Delete(1))
+	assert.False(t, c.Delete(1))
+	assert.Equal(t, map[int]EvictReason{1: EvictInvalidated}, l.evicted)
+}
+
+func (l *LRUTest) TestJanitor_Sweeps_Expired_Entries() {
+	t := l.T()
+
+	c := NewLRU[int, string](10, time.Minute, 5*time.Millisecond, nil)
+	defer c.Close()
+	c.SetWithTTL(1, "one", time.Millisecond)
+	c.Set(2, "two")
+
+	assert.Eventually(t, func() bool { return c.Len() == 1 }, time.Second, 5*time.Millisecond)
+	_, ok := c.Get(2)
+	assert.True(t, ok)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type memoryCache struct {
+	lru      *LRU[string, []byte]
+	versions *versions
+}
+
+// NewMemory returns a Cache kept in process memory, bounded to size entries. It is private to the
+// replica, so other replicas' writes have to be broadcast to it (see db.Listen).
+func NewMemory(size int, sweepInterval time.Duration, onEvict func(key string, reason EvictReason)) Cache {
+	return &memoryCache{lru: NewLRU[string, []byte](size, 0, sweepInterval, onEvict), versions: newVersions()}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := c.lru.Get(key)
+	return value, ok, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.lru.SetWithTTL(key, value, ttl)
+	return nil
+}
+
+func (c *memoryCache) Version(_ context.Context, key string) (uint64, error) {
+	return c.versions.current(key), nil
+}
+
+func (c *memoryCache) SetIfVersion(_ context.Context, key string, value []byte, ttl time.Duration, version uint64) (bool, error) {
+	return c.versions.ifCurrent(key, version, func() { c.lru.SetWithTTL(key, value, ttl) }), nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.versions.bump(key)
+		c.lru.Delete(key)
+	}
+	return nil
+}
+
+func (c *memoryCache) Clear(_ context.Context) error {
+	c.versions.bumpAll()
+	c.lru.Clear()
+	return nil
+}
+
+func (c *memoryCache) Close() error {
+	c.lru.Close()
+	return nil
+}
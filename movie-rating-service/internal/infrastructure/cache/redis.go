@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// versionTTL is how long a deleted key's version is kept. It only has to outlive the reads in flight
+// when the key was deleted; once it expires the key falls back to the version of the last Clear.
+const versionTTL = time.Hour
+
+// Versions are taken from one sequence, so they never repeat even after a version key expired. A
+// key's version is the larger of its own and the one set by the last Clear.
+var (
+	versionScript = redis.NewScript(`
+local key = tonumber(redis.call('GET', KEYS[1]) or '0')
+local all = tonumber(redis.call('GET', KEYS[2]) or '0')
+return math.max(key, all)`)
+
+	setIfVersionScript = redis.NewScript(`
+local key = tonumber(redis.call('GET', KEYS[2]) or '0')
+local all = tonumber(redis.call('GET', KEYS[3]) or '0')
+if math.max(key, all) ~= tonumber(ARGV[1]) then
+	return 0
+end
+if ARGV[3] == '0' then
+	redis.call('SET', KEYS[1], ARGV[2])
+else
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 1`)
+
+	// KEYS are the sequence followed by (entry, version) pairs.
+	deleteScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+for i = 2, #KEYS, 2 do
+	redis.call('DEL', KEYS[i])
+	redis.call('SET', KEYS[i + 1], seq, 'PX', ARGV[1])
+end
+return seq`)
+
+	clearScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+redis.call('SET', KEYS[2], seq)
+return seq`)
+)
+
+type redisCache struct {
+	client *redis.Client
+	prefix string
+	// versionPrefix namespaces the version keys outside of prefix, so Clear does not reset them.
+	versionPrefix string
+}
+
+// NewRedis returns a Cache shared by every replica talking to the same Redis (or Redis-protocol)
+// server. All keys are namespaced with prefix, which also scopes Clear. Versions are kept in Redis
+// too and checked by scripts, so a write on one replica stops a stale SetIfVersion on any other.
+// The scripts touch keys of different hash slots, so only a standalone server (possibly behind
+// Sentinel, see redis.NewFailoverClient) is supported, not Redis Cluster.
+func NewRedis(client *redis.Client, prefix string) Cache {
+	return &redisCache{client: client, prefix: prefix, versionPrefix: "version:" + prefix}
+}
+
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+func (c *redisCache) Version(ctx context.Context, key string) (uint64, error) {
+	return versionScript.Run(ctx, c.client, []string{c.versionPrefix + key, c.versionPrefix + "@all"}).Uint64()
+}
+
+func (c *redisCache) SetIfVersion(ctx context.Context, key string, value []byte, ttl time.Duration, version uint64) (bool, error) {
+	keys := []string{c.prefix + key, c.versionPrefix + key, c.versionPrefix + "@all"}
+	stored, err := setIfVersionScript.Run(ctx, c.client, keys, version, value, ttl.Milliseconds()).Int()
+	return stored == 1, err
+}
+
+func (c *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	scriptKeys := make([]string, 0, 1+2*len(keys))
+	scriptKeys = append(scriptKeys, c.versionPrefix+"@seq")
+	for _, key := range keys {
+		scriptKeys = append(scriptKeys, c.prefix+key, c.versionPrefix+key)
+	}
+	return deleteScript.Run(ctx, c.client, scriptKeys, versionTTL.Milliseconds()).Err()
+}
+
+// Clear moves every version on before it drops the entries, so no read in flight can put one back.
+func (c *redisCache) Clear(ctx context.Context) error {
+	if err := clearScript.Run(ctx, c.client, []string{c.versionPrefix + "@seq", c.versionPrefix + "@all"}).Err(); err != nil {
+		return err
+	}
+
+	iter := c.client.Scan(ctx, 0, c.prefix+"*", 500).Iterator()
+
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == 500 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return c.client.Del(ctx, keys...).Err()
+	}
+	return nil
+}
+
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
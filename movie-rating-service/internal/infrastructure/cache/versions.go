@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+const versionShards = 64
+
+// versions keeps a version per key for the in-process cache, sharded by key so readers of different
+// keys do not contend. Versions come from one increasing sequence, which lets bumpAll supersede every
+// per-key entry and drop them; entries otherwise exist only for keys that have been deleted.
+type versions struct {
+	seed   maphash.Seed
+	seq    atomic.Uint64
+	all    atomic.Uint64
+	shards [versionShards]versionShard
+}
+
+type versionShard struct {
+	mu       sync.Mutex
+	versions map[string]uint64
+}
+
+func newVersions() *versions {
+	return &versions{seed: maphash.MakeSeed()}
+}
+
+func (v *versions) shard(key string) *versionShard {
+	return &v.shards[maphash.String(v.seed, key)%versionShards]
+}
+
+func (v *versions) current(key string) uint64 {
+	s := v.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return v.currentLocked(s, key)
+}
+
+func (v *versions) currentLocked(s *versionShard, key string) uint64 {
+	return max(s.versions[key], v.all.Load())
+}
+
+func (v *versions) bump(key string) {
+	s := v.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.versions == nil {
+		s.versions = make(map[string]uint64)
+	}
+	s.versions[key] = v.seq.Add(1)
+}
+
+func (v *versions) bumpAll() {
+	v.all.Store(v.seq.Add(1))
+	for i := range v.shards {
+		s := &v.shards[i]
+		s.mu.Lock()
+		clear(s.versions)
+		s.mu.Unlock()
+	}
+}
+
+// ifCurrent runs fn only if key's version still is version. A bump waits for fn, so whatever fn
+// writes is dropped by the delete that follows the bump.
+func (v *versions) ifCurrent(key string, version uint64, fn func()) bool {
+	s := v.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v.currentLocked(s, key) != version {
+		return false
+	}
+	fn()
+	return true
+}
@@ -0,0 +1,113 @@
+//go:build unit_test
+
+package migration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+type MigrationTest struct {
+	suite.Suite
+}
+
+func Test_RunMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationTest))
+}
+
+func (m *MigrationTest) TestLoad_Success() {
+	t := m.T()
+
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX a ON b (c);")},
+		"0002_add_index.down.sql": {Data: []byte("DROP INDEX a;")},
+		"0001_baseline.up.sql":    {Data: []byte("CREATE TABLE b (c int);")},
+		"0001_baseline.down.sql":  {Data: []byte("DROP TABLE b;")},
+		"README.md":               {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE b;", migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Equal(t, "CREATE INDEX a ON b (c);", migrations[1].Up)
+}
+
+func (m *MigrationTest) TestLoad_Error_Missing_Down() {
+	t := m.T()
+
+	fsys := fstest.MapFS{
+		"0001_baseline.up.sql": {Data: []byte("CREATE TABLE b (c int);")},
+	}
+
+	migrations, err := Load(fsys)
+
+	assert.ErrorContains(t, err, "migration 1_baseline must have both an up and a down file")
+	assert.Nil(t, migrations)
+}
+
+func (m *MigrationTest) TestLoad_Error_Duplicate_Version() {
+	t := m.T()
+
+	fsys := fstest.MapFS{
+		"0001_baseline.up.sql":   {Data: []byte("SELECT 1;")},
+		"0001_other.down.sql":    {Data: []byte("SELECT 1;")},
+		"0001_baseline.down.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	_, err := Load(fsys)
+
+	assert.ErrorContains(t, err, "migration version 1 is used by both")
+}
+
+func (m *MigrationTest) TestLoad_Embedded() {
+	t := m.T()
+
+	sqlFiles, err := fs.Sub(embedded, "sql")
+	assert.NoError(t, err)
+
+	migrations, err := Load(sqlFiles)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+}
+
+func (m *MigrationTest) TestCreate_Success() {
+	t := m.T()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_baseline.up.sql"), []byte("SELECT 1;"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "0001_baseline.down.sql"), []byte("SELECT 1;"), 0o644))
+
+	files, err := Create(dir, "Add-Watchlist")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0002_add_watchlist.up.sql"),
+		filepath.Join(dir, "0002_add_watchlist.down.sql"),
+	}, files)
+
+	migrations, err := Load(os.DirFS(dir))
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+}
+
+func (m *MigrationTest) TestCreate_Error_Invalid_Name() {
+	t := m.T()
+
+	files, err := Create(t.TempDir(), "drop users;")
+
+	assert.ErrorContains(t, err, "may only contain letters, digits and underscores")
+	assert.Nil(t, files)
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS movie_rating_periods;
+DROP TABLE IF EXISTS movie_rating_buckets;
+DROP TABLE IF EXISTS rating_helpful_votes;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS movies;
+DROP TABLE IF EXISTS users;
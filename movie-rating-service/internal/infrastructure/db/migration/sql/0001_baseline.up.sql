@@ -0,0 +1,114 @@
+-- Baseline: the schema AutoMigrate used to create. Every statement is idempotent so databases
+-- that were set up by AutoMigrate adopt the migration history. Columns added to existing tables
+-- since the first release are added separately, since CREATE TABLE IF NOT EXISTS skips those tables.
+
+CREATE TABLE IF NOT EXISTS users (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    username   text CONSTRAINT uni_users_username UNIQUE,
+    password   text,
+    name       text,
+    surname    text,
+    email      text,
+    phone      text,
+    address    text,
+    is_admin   boolean
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS movies (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz,
+    title           text,
+    description     text,
+    genre           text,
+    director        text,
+    year            bigint,
+    rating          decimal,
+    rating_count    bigint,
+    weighted_rating decimal NOT NULL DEFAULT 0
+);
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS weighted_rating decimal NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_movies_weighted_rating ON movies (weighted_rating);
+CREATE INDEX IF NOT EXISTS idx_movies_deleted_at ON movies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS ratings (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    user_id       bigint CONSTRAINT fk_ratings_user REFERENCES users (id),
+    movie_id      bigint CONSTRAINT fk_ratings_movie REFERENCES movies (id),
+    score         decimal,
+    review        text,
+    helpful_count bigint NOT NULL DEFAULT 0
+);
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS helpful_count bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_ratings_movie_id ON ratings (movie_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ratings_uni_user_movie ON ratings (user_id, movie_id);
+CREATE INDEX IF NOT EXISTS idx_ratings_deleted_at ON ratings (deleted_at);
+
+CREATE TABLE IF NOT EXISTS rating_helpful_votes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    rating_id  bigint,
+    user_id    bigint
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rating_helpful_votes_uni_rating_user ON rating_helpful_votes (rating_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_rating_helpful_votes_deleted_at ON rating_helpful_votes (deleted_at);
+
+CREATE TABLE IF NOT EXISTS movie_rating_buckets (
+    movie_id bigint,
+    bucket   bigint,
+    count    bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, bucket)
+);
+
+CREATE TABLE IF NOT EXISTS movie_rating_periods (
+    movie_id    bigint,
+    period      date,
+    count       bigint  NOT NULL DEFAULT 0,
+    sum         decimal NOT NULL DEFAULT 0,
+    sum_squares decimal NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, period)
+);
+
+-- Movie search: full-text vector over the descriptive columns plus trigram indexes for typo tolerance.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(director, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(genre, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_movies_search_vector ON movies USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_movies_director_trgm ON movies USING GIN (director gin_trgm_ops);
+
+-- Seed the rating statistics from existing ratings while the tables are still empty.
+INSERT INTO movie_rating_buckets (movie_id, bucket, count)
+SELECT movie_id, round((score * 2)::numeric)::int, count(*)
+FROM ratings
+WHERE deleted_at IS NULL
+GROUP BY 1, 2
+HAVING NOT EXISTS (SELECT 1 FROM movie_rating_buckets);
+
+INSERT INTO movie_rating_periods (movie_id, period, count, sum, sum_squares)
+SELECT movie_id, date_trunc('month', created_at AT TIME ZONE 'UTC')::date, count(*), sum(score), sum(score * score)
+FROM ratings
+WHERE deleted_at IS NULL
+GROUP BY 1, 2
+HAVING NOT EXISTS (SELECT 1 FROM movie_rating_periods);
+
+-- Movies that predate the weighted rating get the score of the default prior (3.0 over 10 votes, as
+-- seeded into rating_prior later). The prior refresh that follows new migrations realigns them with
+-- the configured values.
+UPDATE movies
+SET weighted_rating = (coalesce(rating, 0) * coalesce(rating_count, 0) + 10 * 3.0) / (coalesce(rating_count, 0) + 10)
+WHERE weighted_rating = 0;
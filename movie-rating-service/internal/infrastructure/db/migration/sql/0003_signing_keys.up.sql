@@ -0,0 +1,11 @@
+CREATE TABLE signing_keys (
+    kid          text PRIMARY KEY,
+    algorithm    text        NOT NULL,
+    private_key  bytea       NOT NULL,
+    public_key   bytea       NOT NULL,
+    created_at   timestamptz,
+    activates_at timestamptz NOT NULL,
+    retires_at   timestamptz NOT NULL,
+    expires_at   timestamptz NOT NULL
+);
+CREATE INDEX idx_signing_keys_expires_at ON signing_keys (expires_at);
@@ -0,0 +1,69 @@
+-- Roles and permissions replace users.is_admin. Every existing user becomes a "user", former
+-- admins additionally "admin".
+
+CREATE TABLE roles (
+    id          bigserial PRIMARY KEY,
+    name        text NOT NULL CONSTRAINT uni_roles_name UNIQUE,
+    description text
+);
+
+CREATE TABLE permissions (
+    id          bigserial PRIMARY KEY,
+    name        text NOT NULL CONSTRAINT uni_permissions_name UNIQUE,
+    description text
+);
+
+CREATE TABLE role_permissions (
+    role_id       bigint NOT NULL CONSTRAINT fk_role_permissions_role REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id bigint NOT NULL CONSTRAINT fk_role_permissions_permission REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id bigint NOT NULL CONSTRAINT fk_user_roles_user REFERENCES users (id) ON DELETE CASCADE,
+    role_id bigint NOT NULL CONSTRAINT fk_user_roles_role REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE INDEX idx_user_roles_role_id ON user_roles (role_id);
+
+INSERT INTO roles (name, description)
+VALUES ('admin', 'Full access'),
+       ('moderator', 'Moderates reviews and reads user profiles'),
+       ('curator', 'Maintains the movie catalogue'),
+       ('user', 'Rates and reviews movies');
+
+INSERT INTO permissions (name, description)
+VALUES ('movie:write', 'Create, update and delete movies'),
+       ('review:write', 'Rate movies and vote on reviews'),
+       ('review:moderate', 'Remove any review'),
+       ('user:read', 'Read user profiles'),
+       ('rating:reconcile', 'Recompute rating aggregates');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM (VALUES ('admin', 'movie:write'),
+             ('admin', 'review:write'),
+             ('admin', 'review:moderate'),
+             ('admin', 'user:read'),
+             ('admin', 'rating:reconcile'),
+             ('moderator', 'review:write'),
+             ('moderator', 'review:moderate'),
+             ('moderator', 'user:read'),
+             ('curator', 'movie:write'),
+             ('curator', 'review:write'),
+             ('user', 'review:write')) AS m (role, permission)
+         JOIN roles r ON r.name = m.role
+         JOIN permissions p ON p.name = m.permission;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+         JOIN roles r ON r.name = 'user';
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+         JOIN roles r ON r.name = 'admin'
+WHERE u.is_admin;
+
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE name = 'user:manage';
+
+DROP INDEX IF EXISTS idx_users_status;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS tokens_valid_after,
+    DROP COLUMN IF EXISTS password_reset_required,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,15 @@
+ALTER TABLE users
+    ADD COLUMN status                  text    NOT NULL DEFAULT 'active',
+    ADD COLUMN status_reason           text,
+    ADD COLUMN password_reset_required boolean NOT NULL DEFAULT false,
+    ADD COLUMN tokens_valid_after      timestamptz;
+CREATE INDEX idx_users_status ON users (status);
+
+INSERT INTO permissions (name, description)
+VALUES ('user:manage', 'Grant roles, disable or ban accounts and force password resets');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.name = 'user:manage'
+WHERE r.name = 'admin';
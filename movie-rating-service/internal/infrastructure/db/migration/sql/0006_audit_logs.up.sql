@@ -0,0 +1,11 @@
+-- No foreign keys: entries must survive the erasure of the users they mention.
+CREATE TABLE audit_logs (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    actor_id   bigint NOT NULL,
+    action     text   NOT NULL,
+    subject_id bigint NOT NULL,
+    details    jsonb
+);
+CREATE INDEX idx_audit_logs_subject_id ON audit_logs (subject_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,12 @@
+-- Failed logins per username and per client address. A row is forgotten at expires_at, one failure
+-- window after its last failure or the end of its lockout.
+CREATE TABLE login_attempts (
+    scope          text        NOT NULL,
+    key            text        NOT NULL,
+    failures       integer     NOT NULL,
+    last_failed_at timestamptz NOT NULL,
+    locked_until   timestamptz,
+    expires_at     timestamptz NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+CREATE INDEX idx_login_attempts_expires_at ON login_attempts (expires_at);
@@ -0,0 +1,15 @@
+-- totp_secret is encrypted; totp_enabled_at stays NULL until the first code is confirmed.
+-- totp_last_step is the time step of the last accepted code, so a code cannot be replayed.
+ALTER TABLE users
+    ADD COLUMN totp_secret     bytea,
+    ADD COLUMN totp_enabled_at timestamptz,
+    ADD COLUMN totp_last_step  bigint;
+
+CREATE TABLE recovery_codes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  text   NOT NULL,
+    used_at    timestamptz
+);
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
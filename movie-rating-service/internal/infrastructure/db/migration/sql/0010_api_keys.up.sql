@@ -0,0 +1,15 @@
+-- Only the SHA-256 hash of a key is stored; prefix is its first characters, to tell keys apart.
+-- scopes is a space-separated list of permissions.
+CREATE TABLE api_keys (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    user_id      bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         text        NOT NULL,
+    prefix       text        NOT NULL,
+    key_hash     text        NOT NULL UNIQUE,
+    scopes       text        NOT NULL,
+    expires_at   timestamptz NOT NULL,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
+CREATE INDEX idx_api_keys_user_id ON api_keys (user_id);
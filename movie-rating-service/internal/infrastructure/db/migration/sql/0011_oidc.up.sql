@@ -0,0 +1,22 @@
+-- An account created by an OpenID Connect login is linked to the provider's subject, so later logins
+-- find it even after the username or email changed at the provider.
+CREATE TABLE user_identities (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    user_id       bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    issuer        text   NOT NULL,
+    subject       text   NOT NULL,
+    last_login_at timestamptz,
+    UNIQUE (issuer, subject)
+);
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+
+-- A login that was sent to the provider and has not come back yet. The nonce and the PKCE code
+-- verifier never leave the service; the row is deleted when the provider redirects back.
+CREATE TABLE oidc_logins (
+    state         text PRIMARY KEY,
+    nonce         text        NOT NULL,
+    code_verifier text        NOT NULL,
+    expires_at    timestamptz NOT NULL
+);
+CREATE INDEX idx_oidc_logins_expires_at ON oidc_logins (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE movies DROP COLUMN IF EXISTS watchlist_count;
+DROP TABLE IF EXISTS watchlist_items;
@@ -0,0 +1,15 @@
+-- Movies a user intends to see, in the order the user chose. position only orders a user's own
+-- items; gaps are allowed.
+CREATE TABLE watchlist_items (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id   bigint  NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    position   integer NOT NULL,
+    UNIQUE (user_id, movie_id)
+);
+CREATE INDEX idx_watchlist_items_user_id_position ON watchlist_items (user_id, position);
+CREATE INDEX idx_watchlist_items_movie_id ON watchlist_items (movie_id);
+
+-- Kept up to date with watchlist_items, like rating_count with ratings.
+ALTER TABLE movies ADD COLUMN watchlist_count bigint NOT NULL DEFAULT 0;
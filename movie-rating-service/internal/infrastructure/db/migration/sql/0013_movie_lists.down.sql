@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS movie_list_editors;
+DROP TABLE IF EXISTS movie_list_items;
+DROP TABLE IF EXISTS movie_lists;
@@ -0,0 +1,37 @@
+-- Named, ordered lists of movies. visibility is public (browsable), unlisted (reachable by id) or
+-- private (owner and editors only).
+CREATE TABLE movie_lists (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    owner_id       bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name           text   NOT NULL,
+    description    text   NOT NULL DEFAULT '',
+    visibility     text   NOT NULL,
+    cloned_from_id bigint REFERENCES movie_lists (id) ON DELETE SET NULL
+);
+CREATE INDEX idx_movie_lists_owner_id ON movie_lists (owner_id);
+CREATE INDEX idx_movie_lists_visibility_created_at ON movie_lists (visibility, created_at);
+
+-- position only orders the items of one list; gaps are allowed.
+CREATE TABLE movie_list_items (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    list_id     bigint  NOT NULL REFERENCES movie_lists (id) ON DELETE CASCADE,
+    movie_id    bigint  NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    position    integer NOT NULL,
+    note        text    NOT NULL DEFAULT '',
+    added_by_id bigint REFERENCES users (id) ON DELETE SET NULL,
+    UNIQUE (list_id, movie_id)
+);
+CREATE INDEX idx_movie_list_items_list_id_position ON movie_list_items (list_id, position);
+CREATE INDEX idx_movie_list_items_movie_id ON movie_list_items (movie_id);
+
+-- Users the owner invited to edit the list's items.
+CREATE TABLE movie_list_editors (
+    list_id    bigint      NOT NULL REFERENCES movie_lists (id) ON DELETE CASCADE,
+    user_id    bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (list_id, user_id)
+);
+CREATE INDEX idx_movie_list_editors_user_id ON movie_list_editors (user_id);
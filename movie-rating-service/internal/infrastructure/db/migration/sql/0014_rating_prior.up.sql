@@ -0,0 +1,10 @@
+-- The prior mean C of the weighted rating, shared by every replica. It is the global mean of all
+-- ratings (or RATING_PRIOR_MEAN when set), refreshed on migration and by reconciliation rather than
+-- on every write, so incremental updates of weighted_rating stay consistent between refreshes.
+CREATE TABLE rating_prior (
+    id         smallint PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    mean       double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO rating_prior (id, mean) VALUES (1, 3.0);
@@ -0,0 +1 @@
+ALTER TABLE movie_lists DROP COLUMN IF EXISTS slug;
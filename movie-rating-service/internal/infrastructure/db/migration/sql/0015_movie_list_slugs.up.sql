@@ -0,0 +1,4 @@
+-- Unlisted lists are reached through their slug, which unlike the id cannot be guessed. Existing
+-- lists get one too; the volatile default is evaluated for every row.
+ALTER TABLE movie_lists ADD COLUMN slug text NOT NULL DEFAULT replace(gen_random_uuid()::text, '-', '');
+CREATE UNIQUE INDEX uni_movie_lists_slug ON movie_lists (slug);
@@ -0,0 +1,3 @@
+-- The user who created a key, its own user for keys created through /user/me. Keys created before are
+-- left without one, as are keys whose creator has since been deleted.
+ALTER TABLE api_keys ADD COLUMN created_by_id bigint REFERENCES users (id) ON DELETE SET NULL;
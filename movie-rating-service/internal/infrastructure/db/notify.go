@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// Notifier publishes Postgres NOTIFY messages. A notification sent inside WithinTx is delivered
+// by Postgres only when the transaction commits, and dropped if it rolls back.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+}
+
+type notifier struct {
+	db *gorm.DB
+}
+
+func NewNotifier(db *gorm.DB) Notifier {
+	return &notifier{db: db}
+}
+
+func (n *notifier) Notify(ctx context.Context, channel, payload string) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return Conn(ctx, n.db).WithContext(ctxWithTimeout).Exec("SELECT pg_notify(?, ?)", channel, payload).Error
+}
+
+// Listen delivers the payload of every notification on channel to onNotify until ctx is done. It
+// holds its own connection and reconnects with backoff when that connection fails. Notifications
+// sent while disconnected are lost, so onConnect runs after every (re)connect to let the caller
+// resynchronise.
+func Listen(ctx context.Context, dsn, channel string, onConnect func(ctx context.Context), onNotify func(ctx context.Context, payload string)) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := listen(ctx, dsn, channel, func() {
+			backoff = time.Second
+			onConnect(ctx)
+		}, onNotify)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("Listener disconnected", "channel", channel, "error", err, "retry_in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func listen(ctx context.Context, dsn, channel string, onConnect func(), onNotify func(ctx context.Context, payload string)) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	onConnect()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		onNotify(ctx, notification.Payload)
+	}
+}
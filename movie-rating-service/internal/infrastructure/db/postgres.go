@@ -1,12 +1,13 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cast"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"log/slog"
 	"movie-rating-service/config"
-	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/db/migration"
 	"time"
 )
 
@@ -17,14 +18,9 @@ No interface is needed because:
 - Avoids unnecessary interface pollution.
 */
 
-var db *gorm.DB
-
-func BeginTransaction() *gorm.DB {
-	return db.Begin()
-}
-
-func Connect() (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
+// DSN is the connection string built from config.Cfg.DbConfig.
+func DSN() string {
+	return fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		config.Cfg.DbConfig.User,
 		config.Cfg.DbConfig.Password,
@@ -33,7 +29,10 @@ func Connect() (*gorm.DB, error) {
 		config.Cfg.DbConfig.Name,
 		config.Cfg.DbConfig.SSLMode,
 	)
-	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+}
+
+func Connect() (*gorm.DB, error) {
+	dbConn, err := gorm.Open(postgres.Open(DSN()), &gorm.Config{
 		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
@@ -55,17 +54,22 @@ func Connect() (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(10)
 
-	if cast.ToBool(config.Cfg.Migrate) {
-		err = migrate(dbConn)
-		if err != nil {
-			return nil, fmt.Errorf("error occurred while migrating database: %w", err)
-		}
-	}
-
-	db = dbConn
 	return dbConn, nil
 }
 
-func migrate(db *gorm.DB) error {
-	return db.AutoMigrate(&domain.User{}, &domain.Movie{}, &domain.Rating{})
+// Migrate applies pending schema migrations and returns the ones this call applied.
+func Migrate(ctx context.Context, database *gorm.DB) ([]migration.Migration, error) {
+	m, err := migration.NewMigrator(database)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range applied {
+		slog.Info("Applied migration", "version", a.Version, "name", a.Name)
+	}
+	return applied, nil
 }
@@ -4,51 +4,75 @@ import (
 	"gorm.io/gorm"
 	"log/slog"
 	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/password"
+	"time"
 )
 
+// demoPassword is the password of every seeded user. The password policy does not apply to it.
+const demoPassword = "1234"
+
 type seeder struct {
-	db *gorm.DB
+	db     *gorm.DB
+	hasher password.Hasher
 }
 
 type Seeder interface {
 	Seed() error
 }
 
-func NewSeeder(db *gorm.DB) Seeder {
-	return &seeder{db: db}
+func NewSeeder(db *gorm.DB, hasher password.Hasher) Seeder {
+	return &seeder{db: db, hasher: hasher}
 }
 
 func (s *seeder) Seed() error {
+	var user, admin domain.Role
+	if err := s.db.Where("name = ?", domain.RoleUser).First(&user).Error; err != nil {
+		return err
+	}
+	if err := s.db.Where("name = ?", domain.RoleAdmin).First(&admin).Error; err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.Hash(demoPassword)
+	if err != nil {
+		return err
+	}
+
+	// Seeded addresses are not real, so they are taken as verified.
+	verifiedAt := time.Now()
 	users := []domain.User{
 		{
-			Username: "alice",
-			Password: "$2a$10$HuR2tFF3FV2Suyv9/rMX/Om6ylABQFADP15d2WuHsYGyFMqwFh38W",
-			Name:     "Alice",
-			Surname:  "Wonder",
-			Email:    "alice@mail.com",
-			Phone:    "1234567890",
-			Address:  "123 Elm St",
-			IsAdmin:  false,
+			Username:        "alice",
+			Password:        hash,
+			Name:            "Alice",
+			Surname:         "Wonder",
+			Email:           "alice@mail.com",
+			Phone:           "1234567890",
+			Address:         "123 Elm St",
+			Roles:           []domain.Role{user},
+			EmailVerifiedAt: &verifiedAt,
 		},
 		{
-			Username: "bob",
-			Password: "$2a$10$HuR2tFF3FV2Suyv9/rMX/Om6ylABQFADP15d2WuHsYGyFMqwFh38W",
-			Name:     "Bob",
-			Surname:  "Builder",
-			Email:    "bob@mail.com",
-			Phone:    "2345678901",
-			Address:  "456 Oak St",
-			IsAdmin:  false,
+			Username:        "bob",
+			Password:        hash,
+			Name:            "Bob",
+			Surname:         "Builder",
+			Email:           "bob@mail.com",
+			Phone:           "2345678901",
+			Address:         "456 Oak St",
+			Roles:           []domain.Role{user},
+			EmailVerifiedAt: &verifiedAt,
 		},
 		{
-			Username: "carol",
-			Password: "$2a$10$HuR2tFF3FV2Suyv9/rMX/Om6ylABQFADP15d2WuHsYGyFMqwFh38W",
-			Name:     "Carol",
-			Surname:  "Smith",
-			Email:    "carol@mail.com",
-			Phone:    "3456789012",
-			Address:  "789 Pine St",
-			IsAdmin:  true,
+			Username:        "carol",
+			Password:        hash,
+			Name:            "Carol",
+			Surname:         "Smith",
+			Email:           "carol@mail.com",
+			Phone:           "3456789012",
+			Address:         "789 Pine St",
+			Roles:           []domain.Role{user, admin},
+			EmailVerifiedAt: &verifiedAt,
 		},
 	}
 
@@ -110,7 +134,7 @@ func (s *seeder) Seed() error {
 	}
 
 	slog.Info("Seeder start...")
-	s.db.Save(&users)
+	s.db.Omit("Roles.*").Save(&users)
 	s.db.Save(&movies)
 	s.db.Save(&ratings)
 	slog.Info("Seeder end...")
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"sync"
+)
+
+type txKey struct{}
+
+// txState is what WithinTx puts into the context: the open transaction (or savepoint) and the
+// callbacks waiting for it to commit.
+type txState struct {
+	db          *gorm.DB
+	mu          sync.Mutex
+	afterCommit []func(ctx context.Context)
+}
+
+// TxManager runs a unit of work in a database transaction. The transaction travels in the context
+// passed to fn, and repositories pick it up through Conn, so services never touch *gorm.DB.
+type TxManager interface {
+	// WithinTx commits when fn returns nil and rolls back when it returns an error or panics.
+	// Calls nested inside another WithinTx run in a savepoint of the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, nested := ctx.Value(txKey{}).(*txState)
+
+	db := m.db.WithContext(ctx)
+	if nested {
+		db = parent.db
+	}
+
+	state := &txState{}
+	// gorm.DB.Transaction opens a savepoint when db is already a transaction, and rolls back and
+	// re-panics if fn panics.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	// A released savepoint can still be rolled back with its parent, so its callbacks wait for the
+	// outermost commit.
+	if nested {
+		parent.mu.Lock()
+		parent.afterCommit = append(parent.afterCommit, state.afterCommit...)
+		parent.mu.Unlock()
+		return nil
+	}
+
+	for _, callback := range state.afterCommit {
+		callback(ctx)
+	}
+	return nil
+}
+
+// Conn returns the transaction carried by ctx, or fallback when ctx is not inside WithinTx.
+func Conn(ctx context.Context, fallback *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.db
+	}
+	return fallback
+}
+
+// InTx reports whether ctx carries a transaction started by WithinTx.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction carried by ctx has committed, and never if it rolls back.
+// Outside of a transaction fn runs immediately. fn receives a context without the transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	state.mu.Lock()
+	state.afterCommit = append(state.afterCommit, fn)
+	state.mu.Unlock()
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes every message to its own .eml file, which mail clients can open.
+type fileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFile(from, dir string) Mailer {
+	return &fileMailer{from: from, dir: dir}
+}
+
+func (m *fileMailer) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	data, err := compose(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, now.UTC().Format("20060102T150405.000000000")+"-"+hex.EncodeToString(suffix)+".eml")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	slog.Info("Mail written", "to", msg.To, "subject", msg.Subject, "path", path)
+	return nil
+}
+
+// logMailer only logs messages, body included, so tokens can be copied from the log.
+type logMailer struct {
+	from string
+}
+
+func NewLog(from string) Mailer {
+	return &logMailer{from: from}
+}
+
+func (m *logMailer) Send(_ context.Context, msg Message) error {
+	slog.Info("Mail", "from", m.from, "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
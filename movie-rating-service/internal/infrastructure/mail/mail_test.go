@@ -0,0 +1,143 @@
+//go:build unit_test
+
+package mail
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"mime/quotedprintable"
+	"movie-rating-service/config"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MailTest struct {
+	suite.Suite
+}
+
+func Test_RunMailTestSuite(t *testing.T) {
+	suite.Run(t, new(MailTest))
+}
+
+func (m *MailTest) TestCompose_Success() {
+	t := m.T()
+
+	data, err := compose("no-reply@example.com", Message{To: "alice@example.com", Subject: "Passwort zurücksetzen", Body: "Token: a=b\nBye"}, time.Now())
+	assert.NoError(t, err)
+
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice@example.com", parsed.Header.Get("To"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-Id"), "@example.com>"))
+	subject, err := new(netmail.AddressParser).WordDecoder.DecodeHeader(parsed.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Passwort zurücksetzen", subject)
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, "Token: a=b\r\nBye", string(body))
+}
+
+func (m *MailTest) TestCompose_Error_Header_Injection() {
+	t := m.T()
+
+	_, err := compose("no-reply@example.com", Message{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "Hi"}, time.Now())
+
+	assert.ErrorContains(t, err, "must not contain line breaks")
+}
+
+func (m *MailTest) TestFile_Send_Success() {
+	t := m.T()
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	err := NewFile("no-reply@example.com", dir).Send(context.TODO(), Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"})
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Name(), ".eml"))
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "To: alice@example.com\r\n")
+}
+
+func (m *MailTest) TestSMTP_Send_Success() {
+	t := m.T()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	m.Require().NoError(err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	mailer := NewSMTP(config.MailConfig{From: "no-reply@example.com", SMTPHost: "127.0.0.1", SMTPPort: addr.Port})
+
+	err = mailer.Send(context.TODO(), Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"})
+	assert.NoError(t, err)
+
+	commands := <-received
+	assert.Contains(t, commands, "MAIL FROM:<no-reply@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<alice@example.com>")
+	assert.Contains(t, commands, "To: alice@example.com")
+}
+
+func (m *MailTest) TestSMTP_Send_Error_Unreachable() {
+	t := m.T()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	m.Require().NoError(err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	m.Require().NoError(listener.Close())
+
+	err = NewSMTP(config.MailConfig{From: "no-reply@example.com", SMTPHost: "127.0.0.1", SMTPPort: port}).
+		Send(context.TODO(), Message{To: "alice@example.com", Subject: "Hi", Body: "Hello"})
+
+	assert.ErrorContains(t, err, "failed to connect to smtp server")
+}
+
+// serveSMTP accepts one session without STARTTLS or AUTH and reports every line the client sent.
+func serveSMTP(listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		received <- nil
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	var lines []string
+	_ = text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			break
+		}
+		lines = append(lines, line)
+		switch {
+		case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+			_ = text.PrintfLine("250 localhost")
+		case line == "DATA":
+			_ = text.PrintfLine("354 go ahead")
+			data, _ := text.ReadDotLines()
+			lines = append(lines, data...)
+			_ = text.PrintfLine("250 queued as %d", len(data))
+		case line == "QUIT":
+			_ = text.PrintfLine("221 bye")
+			received <- lines
+			return
+		default:
+			_ = text.PrintfLine("250 OK")
+		}
+	}
+	received <- lines
+}
@@ -0,0 +1,287 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"io"
+	"movie-rating-service/config"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRejected is returned when the provider refuses an authorization code or returns an ID token
+// that does not verify. Anything else, e.g. the provider being unreachable, is a plain error.
+var ErrRejected = errors.New("rejected by the identity provider")
+
+// supportedAlgorithms are the ID token signing algorithms accepted when the provider advertises them;
+// "none" and HMAC never are.
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Claims are the claims of a verified ID token that the service uses.
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+	Groups            []string
+	Nonce             string
+}
+
+// Provider is an OpenID Connect provider, configured by discovery on first use.
+type Provider interface {
+	Issuer() string
+	// AuthCodeURL is the URL of the authorization endpoint to send the browser to, for the
+	// authorization code flow with a PKCE S256 code challenge.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and returns the claims of the ID token, once its
+	// signature, issuer, audience and lifetime are verified. The nonce is left to the caller.
+	Exchange(ctx context.Context, code, codeVerifier string) (*Claims, error)
+}
+
+// metadata is the part of the discovery document (OpenID Connect Discovery 1.0) the client uses.
+type metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// provider caches the discovery document for good, and the signing keys until a token is signed
+// with a key it does not know, which happens when the provider rotates its keys.
+type provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *metadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+const (
+	// keyRefreshInterval is how often at most the keys are fetched again for an unknown key id.
+	keyRefreshInterval = time.Minute
+	// clockSkew is the leeway for the lifetime of ID tokens.
+	clockSkew = time.Minute
+)
+
+func NewProvider(cfg config.OIDCConfig, client *http.Client) Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &provider{cfg: cfg, client: client}
+}
+
+func (p *provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return md.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code, codeVerifier string) (*Claims, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	// client_secret_basic is the default of the specification; public clients only send their id.
+	useBasicAuth := p.cfg.ClientSecret != "" && (len(md.TokenEndpointAuthMethodsSupported) == 0 ||
+		slices.Contains(md.TokenEndpointAuthMethodsSupported, "client_secret_basic"))
+	if !useBasicAuth {
+		form.Set("client_id", p.cfg.ClientID)
+		if p.cfg.ClientSecret != "" {
+			form.Set("client_secret", p.cfg.ClientSecret)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&body); err != nil && res.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if res.StatusCode >= 400 && res.StatusCode < 500 {
+		return nil, fmt.Errorf("%w: %s %s", ErrRejected, body.Error, body.ErrorDescription)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint answered %d", res.StatusCode)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in the token response", ErrRejected)
+	}
+	return p.verify(ctx, md, body.IDToken)
+}
+
+func (p *provider) verify(ctx context.Context, md *metadata, idToken string) (*Claims, error) {
+	methods := []string{"RS256"}
+	if len(md.IDTokenSigningAlgValuesSupported) > 0 {
+		methods = slices.DeleteFunc(slices.Clone(md.IDTokenSigningAlgValuesSupported), func(alg string) bool {
+			return !slices.Contains(supportedAlgorithms, alg)
+		})
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, md, kid)
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id token: %w", ErrRejected, err)
+	}
+	// With several audiences, the token must have been issued to this client (OpenID Connect Core 3.1.3.7).
+	if audience, _ := claims.GetAudience(); len(audience) > 1 && claims["azp"] != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: id token was issued to %v", ErrRejected, claims["azp"])
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: id token has no subject", ErrRejected)
+	}
+	result := &Claims{Subject: subject, Groups: stringList(claims[p.cfg.GroupsClaim])}
+	result.Email, _ = claims["email"].(string)
+	result.EmailVerified, _ = claims["email_verified"].(bool)
+	result.PreferredUsername, _ = claims["preferred_username"].(string)
+	result.GivenName, _ = claims["given_name"].(string)
+	result.FamilyName, _ = claims["family_name"].(string)
+	result.Nonce, _ = claims["nonce"].(string)
+	return result, nil
+}
+
+// stringList reads a claim that is a list of strings, or a single string as some providers send
+// for a single group.
+func stringList(claim interface{}) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []interface{}:
+		list := make([]string, 0, len(claim))
+		for _, value := range claim {
+			if value, ok := value.(string); ok {
+				list = append(list, value)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func (p *provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var md metadata
+	if err := p.get(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &md); err != nil {
+		return nil, fmt.Errorf("failed to discover provider: %w", err)
+	}
+	if md.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("provider reports issuer %q instead of %q", md.Issuer, p.cfg.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("provider metadata lacks an authorization, token or jwks endpoint")
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+func (p *provider) key(ctx context.Context, md *metadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	var set jwks
+	if err := p.get(ctx, md.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	p.keys, p.keysFetchedAt = set.publicKeys(), time.Now()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *provider) get(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d", url, res.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(v)
+}
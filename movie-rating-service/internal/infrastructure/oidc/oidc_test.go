@@ -0,0 +1,316 @@
+//go:build unit_test
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"math/big"
+	"movie-rating-service/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockProvider is a minimal OpenID Connect provider: discovery, JWKS, and a token endpoint that
+// redeems the codes handed out by authorize, checking the client and the PKCE verifier. codes maps
+// each code to its code challenge.
+type mockProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	mu     sync.Mutex
+	codes  map[string]string
+	claims jwt.MapClaims
+}
+
+func newMockProvider() *mockProvider {
+	m := &mockProvider{codes: map[string]string{}}
+	m.rotate("k1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256", "HS256"},
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(jwks{Keys: []jwk{{
+			Kty: "RSA",
+			Kid: m.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "movie-rating" || secret != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		challenge, ok := m.codes[r.PostFormValue("code")]
+		delete(m.codes, r.PostFormValue("code"))
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !ok || challenge != base64.RawURLEncoding.EncodeToString(sum[:]) || r.PostFormValue("redirect_uri") != "http://localhost:8080/auth/oidc/callback" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": m.sign(m.claims)})
+	})
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+func (m *mockProvider) rotate(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	m.mu.Lock()
+	m.key, m.kid = key, kid
+	m.mu.Unlock()
+}
+
+// authorize hands out a code, as the provider does after the user signed in.
+func (m *mockProvider) authorize(challenge string, claims jwt.MapClaims) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes["code"] = challenge
+	m.claims = claims
+	return "code"
+}
+
+func (m *mockProvider) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+type ProviderTest struct {
+	suite.Suite
+	mock     *mockProvider
+	provider *provider
+}
+
+func (p *ProviderTest) SetupTest() {
+	p.mock = newMockProvider()
+	p.provider = NewProvider(config.OIDCConfig{
+		Issuer:       p.mock.server.URL,
+		ClientID:     "movie-rating",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/callback",
+		Scopes:       []string{"openid", "profile", "email"},
+		GroupsClaim:  "groups",
+	}, p.mock.server.Client()).(*provider)
+}
+
+func (p *ProviderTest) TearDownTest() {
+	p.mock.server.Close()
+}
+
+func Test_RunProviderTestSuite(t *testing.T) {
+	suite.Run(t, new(ProviderTest))
+}
+
+func (p *ProviderTest) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":                p.mock.server.URL,
+		"sub":                "248289761001",
+		"aud":                "movie-rating",
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Minute).Unix(),
+		"nonce":              "nonce",
+		"email":              "jane@example.com",
+		"email_verified":     true,
+		"preferred_username": "jane",
+		"given_name":         "Jane",
+		"family_name":        "Doe",
+		"groups":             []string{"movie-admins", "staff"},
+	}
+}
+
+func challengeOf(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *ProviderTest) TestAuthCodeURL_Success() {
+	t := p.T()
+
+	authURL, err := p.provider.AuthCodeURL(context.TODO(), "state", "nonce", "challenge")
+
+	assert.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, p.mock.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "movie-rating", query.Get("client_id"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "nonce", query.Get("nonce"))
+	assert.Equal(t, "challenge", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+}
+
+func (p *ProviderTest) TestAuthCodeURL_Error_Issuer_Mismatch() {
+	t := p.T()
+
+	p.provider.cfg.Issuer = p.mock.server.URL + "/"
+
+	_, err := p.provider.AuthCodeURL(context.TODO(), "state", "nonce", "challenge")
+
+	assert.ErrorContains(t, err, "reports issuer")
+}
+
+func (p *ProviderTest) TestExchange_Success() {
+	t := p.T()
+
+	code := p.mock.authorize(challengeOf("verifier"), p.claims())
+
+	claims, err := p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Claims{
+		Subject:           "248289761001",
+		Email:             "jane@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "jane",
+		GivenName:         "Jane",
+		FamilyName:        "Doe",
+		Groups:            []string{"movie-admins", "staff"},
+		Nonce:             "nonce",
+	}, claims)
+}
+
+func (p *ProviderTest) TestExchange_Error_Wrong_Code_Verifier() {
+	t := p.T()
+
+	code := p.mock.authorize(challengeOf("verifier"), p.claims())
+
+	_, err := p.provider.Exchange(context.TODO(), code, "another-verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func (p *ProviderTest) TestExchange_Error_Code_Used_Twice() {
+	t := p.T()
+
+	code := p.mock.authorize(challengeOf("verifier"), p.claims())
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+	p.Require().NoError(err)
+
+	_, err = p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestExchange_Error_Wrong_Audience() {
+	t := p.T()
+
+	claims := p.claims()
+	claims["aud"] = "another-client"
+	code := p.mock.authorize(challengeOf("verifier"), claims)
+
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestExchange_Error_Issued_To_Another_Party() {
+	t := p.T()
+
+	claims := p.claims()
+	claims["aud"] = []string{"movie-rating", "another-client"}
+	claims["azp"] = "another-client"
+	code := p.mock.authorize(challengeOf("verifier"), claims)
+
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestExchange_Error_Wrong_Issuer() {
+	t := p.T()
+
+	claims := p.claims()
+	claims["iss"] = "https://evil.example.com"
+	code := p.mock.authorize(challengeOf("verifier"), claims)
+
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestExchange_Error_Expired() {
+	t := p.T()
+
+	claims := p.claims()
+	claims["iat"] = time.Now().Add(-time.Hour).Unix()
+	claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+	code := p.mock.authorize(challengeOf("verifier"), claims)
+
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestVerify_Error_HMAC_Token() {
+	t := p.T()
+
+	md, err := p.provider.discover(context.TODO())
+	p.Require().NoError(err)
+	// Signed with the client secret, which the provider advertises but the client never accepts.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, p.claims())
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString([]byte("client-secret"))
+	p.Require().NoError(err)
+
+	_, err = p.provider.verify(context.TODO(), md, signed)
+
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func (p *ProviderTest) TestExchange_Fetches_Rotated_Keys() {
+	t := p.T()
+
+	code := p.mock.authorize(challengeOf("verifier"), p.claims())
+	_, err := p.provider.Exchange(context.TODO(), code, "verifier")
+	p.Require().NoError(err)
+
+	p.mock.rotate("k2")
+	p.provider.keysFetchedAt = time.Now().Add(-keyRefreshInterval)
+	code = p.mock.authorize(challengeOf("verifier"), p.claims())
+
+	_, err = p.provider.Exchange(context.TODO(), code, "verifier")
+
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+const (
+	argon2idPrefix     = "$argon2id$"
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// argon2idParams are the cost parameters stored with an argon2id hash; memory is in KiB.
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	keyLen  uint32
+}
+
+// hashArgon2id encodes the hash in the PHC string format used by the reference implementation,
+// e.g. $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>.
+func hashArgon2id(password string, params argon2idParams) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, params.keyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, params.memory, params.time, params.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func verifyArgon2id(hash, password string) (argon2idParams, bool) {
+	var params argon2idParams
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, false
+	}
+	// Parameters argon2 would panic on, or that would take the service down, are not from this service.
+	if params.time < 1 || params.threads < 1 || params.memory < 8*uint32(params.threads) || params.memory > 4<<20 {
+		return params, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) < 16 {
+		return params, false
+	}
+	params.keyLen = uint32(len(key))
+
+	computed := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, params.keyLen)
+	return params, subtle.ConstantTimeCompare(computed, key) == 1
+}
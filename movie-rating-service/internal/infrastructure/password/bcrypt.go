@@ -0,0 +1,24 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxLength is the length in bytes beyond which bcrypt refuses to hash a password.
+const bcryptMaxLength = 72
+
+func hashBcrypt(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func verifyBcrypt(hash, password string) (int, bool) {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return 0, false
+	}
+	return cost, bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
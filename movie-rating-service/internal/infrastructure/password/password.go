@@ -0,0 +1,54 @@
+package password
+
+import (
+	"movie-rating-service/config"
+	"strings"
+)
+
+// Hasher hashes passwords with the configured algorithm and verifies hashes of every supported
+// algorithm, so that switching the algorithm or its parameters does not lock anyone out.
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the hash, and if so, whether the hash was made
+	// with another algorithm or other parameters than configured and should be replaced. An empty or
+	// malformed hash matches no password.
+	Verify(hash, password string) (match, rehash bool)
+}
+
+type hasher struct {
+	cfg config.PasswordConfig
+}
+
+func NewHasher(cfg config.PasswordConfig) Hasher {
+	return &hasher{cfg: cfg}
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	switch h.cfg.Algorithm {
+	case "bcrypt":
+		return hashBcrypt(password, h.cfg.BcryptCost)
+	default:
+		return hashArgon2id(password, h.argon2idParams())
+	}
+}
+
+func (h *hasher) Verify(hash, password string) (bool, bool) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		params, match := verifyArgon2id(hash, password)
+		return match, match && (h.cfg.Algorithm != "argon2id" || params != h.argon2idParams())
+	case strings.HasPrefix(hash, "$2"):
+		cost, match := verifyBcrypt(hash, password)
+		return match, match && (h.cfg.Algorithm != "bcrypt" || cost != h.cfg.BcryptCost)
+	}
+	return false, false
+}
+
+func (h *hasher) argon2idParams() argon2idParams {
+	return argon2idParams{
+		memory:  h.cfg.Argon2Memory,
+		time:    h.cfg.Argon2Time,
+		threads: h.cfg.Argon2Threads,
+		keyLen:  argon2idKeyLength,
+	}
+}
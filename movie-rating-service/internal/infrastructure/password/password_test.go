@@ -0,0 +1,195 @@
+//go:build unit_test
+
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+	"movie-rating-service/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type PasswordTest struct {
+	suite.Suite
+	cfg config.PasswordConfig
+}
+
+func (p *PasswordTest) SetupTest() {
+	// Far below the defaults, to keep the tests fast.
+	p.cfg = config.PasswordConfig{
+		Algorithm:     "argon2id",
+		Argon2Memory:  64,
+		Argon2Time:    1,
+		Argon2Threads: 1,
+		BcryptCost:    bcrypt.MinCost,
+		MinLength:     8,
+		MaxLength:     72,
+	}
+}
+
+func Test_RunPasswordTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordTest))
+}
+
+func (p *PasswordTest) TestArgon2id_Success() {
+	t := p.T()
+	hasher := NewHasher(p.cfg)
+
+	hash, err := hasher.Hash("correct horse")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+	match, rehash := hasher.Verify(hash, "correct horse")
+	assert.True(t, match)
+	assert.False(t, rehash)
+	match, _ = hasher.Verify(hash, "correct horsE")
+	assert.False(t, match)
+
+	other, err := hasher.Hash("correct horse")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func (p *PasswordTest) TestArgon2id_Reference_Hash() {
+	t := p.T()
+
+	// A test vector of the reference implementation: password "password", salt "somesalt".
+	hash := "$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ$ZVrRXqxlLcWfcXCnMyv0m4Rpvh/bnCi7"
+
+	params, match := verifyArgon2id(hash, "password")
+
+	assert.True(t, match)
+	assert.Equal(t, argon2idParams{memory: 64, time: 1, threads: 1, keyLen: 24}, params)
+	// The configured key length differs, so the hash is replaced.
+	_, rehash := NewHasher(p.cfg).Verify(hash, "password")
+	assert.True(t, rehash)
+}
+
+func (p *PasswordTest) TestVerify_Rehash_On_Changed_Parameters() {
+	t := p.T()
+
+	hash, err := NewHasher(p.cfg).Hash("correct horse")
+	p.Require().NoError(err)
+	p.cfg.Argon2Time = 2
+
+	match, rehash := NewHasher(p.cfg).Verify(hash, "correct horse")
+
+	assert.True(t, match)
+	assert.True(t, rehash)
+}
+
+func (p *PasswordTest) TestVerify_Bcrypt_Hash_With_Argon2id_Configured() {
+	t := p.T()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	p.Require().NoError(err)
+	hasher := NewHasher(p.cfg)
+
+	match, rehash := hasher.Verify(string(hash), "correct horse")
+	assert.True(t, match)
+	assert.True(t, rehash)
+	// A wrong password never asks for a rehash.
+	match, rehash = hasher.Verify(string(hash), "wrong horse")
+	assert.False(t, match)
+	assert.False(t, rehash)
+}
+
+func (p *PasswordTest) TestBcrypt_Success() {
+	t := p.T()
+
+	p.cfg.Algorithm = "bcrypt"
+	hasher := NewHasher(p.cfg)
+
+	hash, err := hasher.Hash("correct horse")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+	match, rehash := hasher.Verify(hash, "correct horse")
+	assert.True(t, match)
+	assert.False(t, rehash)
+
+	p.cfg.BcryptCost++
+	_, rehash = NewHasher(p.cfg).Verify(hash, "correct horse")
+	assert.True(t, rehash)
+}
+
+func (p *PasswordTest) TestVerify_Error_Malformed_Hash() {
+	t := p.T()
+	hasher := NewHasher(p.cfg)
+
+	for _, hash := range []string{
+		"",
+		"correct horse",
+		"$argon2id$v=19$m=64,t=1,p=1$c29tZXNhbHQ",
+		"$argon2id$v=16$m=64,t=1,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=64,t=0,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=64,t=1,p=0$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$2a$04$invalid",
+	} {
+		match, rehash := hasher.Verify(hash, "correct horse")
+		assert.False(t, match, hash)
+		assert.False(t, rehash, hash)
+	}
+}
+
+func (p *PasswordTest) TestPolicy_Length() {
+	t := p.T()
+
+	policy, err := NewPolicy(p.cfg)
+	p.Require().NoError(err)
+
+	assert.ErrorIs(t, policy.Check("short"), ErrWeak)
+	assert.NoError(t, policy.Check("eight ch"))
+	// Characters are counted, not bytes.
+	assert.NoError(t, policy.Check("ÄÖÜäöüßé"))
+	assert.NoError(t, policy.Check(strings.Repeat("a", 72)))
+	assert.ErrorIs(t, policy.Check(strings.Repeat("a", 73)), ErrWeak)
+}
+
+func (p *PasswordTest) TestPolicy_Bcrypt_Byte_Limit() {
+	t := p.T()
+
+	p.cfg.Algorithm = "bcrypt"
+	policy, err := NewPolicy(p.cfg)
+	p.Require().NoError(err)
+
+	// 40 characters, but 80 bytes: bcrypt would refuse to hash it.
+	err = policy.Check(strings.Repeat("ä", 40))
+
+	assert.ErrorIs(t, err, ErrWeak)
+	assert.ErrorContains(t, err, "bytes")
+}
+
+func (p *PasswordTest) TestPolicy_Breached_List() {
+	t := p.T()
+
+	sum := sha1.Sum([]byte("P@ssw0rd!"))
+	list := "password123\r\n\nletmein-please\n" + strings.ToUpper(hex.EncodeToString(sum[:])) + ":1337\n"
+	p.cfg.BreachedList = filepath.Join(t.TempDir(), "breached.txt")
+	p.Require().NoError(os.WriteFile(p.cfg.BreachedList, []byte(list), 0o600))
+
+	policy, err := NewPolicy(p.cfg)
+
+	assert.NoError(t, err)
+	assert.ErrorIs(t, policy.Check("password123"), ErrWeak)
+	assert.ErrorIs(t, policy.Check("letmein-please"), ErrWeak)
+	assert.ErrorIs(t, policy.Check("P@ssw0rd!"), ErrWeak)
+	assert.NoError(t, policy.Check("Password123"))
+}
+
+func (p *PasswordTest) TestPolicy_Error_Missing_List() {
+	t := p.T()
+
+	p.cfg.BreachedList = filepath.Join(t.TempDir(), "missing.txt")
+
+	_, err := NewPolicy(p.cfg)
+
+	assert.ErrorContains(t, err, "failed to open breached password list")
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"movie-rating-service/config"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrWeak is returned for a password that the policy does not accept.
+var ErrWeak = errors.New("password does not meet the password policy")
+
+// Policy decides which new passwords are accepted. It only applies when a password is set, so
+// existing passwords keep working after it is tightened.
+type Policy struct {
+	minLength int
+	maxLength int
+	maxBytes  int
+	breached  map[[sha1.Size]byte]struct{}
+}
+
+// NewPolicy loads the breached password list, if configured. Each line of the list is either a
+// password or, as in the Have I Been Pwned downloads, the hex SHA-1 hash of one, optionally
+// followed by a colon and a count.
+func NewPolicy(cfg config.PasswordConfig) (*Policy, error) {
+	policy := &Policy{minLength: cfg.MinLength, maxLength: cfg.MaxLength, breached: map[[sha1.Size]byte]struct{}{}}
+	if cfg.Algorithm == "bcrypt" {
+		policy.maxBytes = bcryptMaxLength
+	}
+	if cfg.BreachedList == "" {
+		return policy, nil
+	}
+
+	file, err := os.Open(cfg.BreachedList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		policy.breached[breachedListKey(line)] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return policy, nil
+}
+
+// Check returns an error wrapping ErrWeak that tells what is wrong with the password.
+func (p *Policy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength {
+		return fmt.Errorf("%w: it must be at least %d characters long", ErrWeak, p.minLength)
+	}
+	if length > p.maxLength {
+		return fmt.Errorf("%w: it must be at most %d characters long", ErrWeak, p.maxLength)
+	}
+	if p.maxBytes > 0 && len(password) > p.maxBytes {
+		return fmt.Errorf("%w: it must be at most %d bytes long, where accented and other non-ASCII characters take several", ErrWeak, p.maxBytes)
+	}
+	if _, ok := p.breached[sha1.Sum([]byte(password))]; ok {
+		return fmt.Errorf("%w: it appears in a list of breached passwords, choose another one", ErrWeak)
+	}
+	return nil
+}
+
+func breachedListKey(line string) [sha1.Size]byte {
+	hash, _, _ := strings.Cut(line, ":")
+	var key [sha1.Size]byte
+	if len(hash) == 2*sha1.Size {
+		if _, err := hex.Decode(key[:], []byte(hash)); err == nil {
+			return key
+		}
+	}
+	return sha1.Sum([]byte(line))
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type apiKeyRepository struct {
+	DB *gorm.DB
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key domain.APIKey) (*domain.APIKey, error)
+	// ListByUser returns every key of the user, revoked and expired ones included, newest first.
+	ListByUser(ctx context.Context, userID uint) ([]domain.APIKey, error)
+	GetByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	// Revoke returns gorm.ErrRecordNotFound when the user has no such key that is not revoked yet.
+	Revoke(ctx context.Context, userID, keyID uint, at time.Time) error
+	// Touch sets last_used_at, unless the key was already used after notBefore, so that a busy key
+	// is not written on every request.
+	Touch(ctx context.Context, keyID uint, at, notBefore time.Time) error
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{DB: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key domain.APIKey) (*domain.APIKey, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := db.WithContext(ctxWithTimeout).Create(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uint) ([]domain.APIKey, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var keys []domain.APIKey
+	err := db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	key := domain.APIKey{}
+	return &key, db.WithContext(ctxWithTimeout).Where("key_hash = ?", hash).First(&key).Error
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, keyID uint, at time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", at.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, keyID uint, at, notBefore time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, notBefore.UTC()).
+		Update("last_used_at", at.UTC()).Error
+}
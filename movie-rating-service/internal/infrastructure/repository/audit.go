@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type auditRepository struct {
+	DB *gorm.DB
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry domain.AuditLog) error
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{DB: db}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry domain.AuditLog) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Create(&entry).Error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/infrastructure/db"
+)
+
+// conn returns the transaction started by db.TxManager for ctx, or the repository's own
+// connection outside of one.
+func conn(ctx context.Context, fallback *gorm.DB) *gorm.DB {
+	return db.Conn(ctx, fallback)
+}
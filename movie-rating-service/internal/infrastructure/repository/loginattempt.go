@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type loginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+type LoginAttemptRepository interface {
+	// Acquire counts a login attempt for the key before its credentials are checked. Counting,
+	// reading the count and locking the key once the count reaches maxFailures happen in one
+	// statement, so concurrent attempts cannot slip past the limit. lockouts are the lockout lengths
+	// by attempts beyond the limit; the last one repeats. Acquire returns the count including this
+	// attempt, or, if the key is locked, the end of the lockout and does not count the attempt. The
+	// count starts over once the row has expired.
+	Acquire(ctx context.Context, key domain.LoginAttemptKey, at time.Time, window time.Duration, maxFailures int, lockouts []time.Duration) (int, *time.Time, error)
+	// Release takes back an attempt whose credentials were right. unlock lifts the lockout that the
+	// attempt itself started by reaching the limit.
+	Release(ctx context.Context, key domain.LoginAttemptKey, unlock bool) error
+	Reset(ctx context.Context, key domain.LoginAttemptKey) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{DB: db}
+}
+
+func (r *loginAttemptRepository) Acquire(ctx context.Context, key domain.LoginAttemptKey, at time.Time, window time.Duration, maxFailures int, lockouts []time.Duration) (int, *time.Time, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	seconds := make([]string, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		seconds = append(seconds, strconv.FormatFloat(lockout.Seconds(), 'f', -1, 64))
+	}
+	// The first attempt of a key only locks it if the limit is a single attempt.
+	var lockedUntil *time.Time
+	expiresAt := at.Add(window)
+	if maxFailures <= 1 {
+		until := at.Add(lockouts[0])
+		lockedUntil, expiresAt = &until, until.Add(window)
+	}
+
+	// A locked row is left alone by the upsert, so the attempt CTE is empty and the lockout of the
+	// row as it was before the statement is returned instead.
+	var result struct {
+		Failures    int
+		LockedUntil *time.Time
+	}
+	err := db.WithContext(ctxWithTimeout).Raw(`WITH attempt AS (
+			INSERT INTO login_attempts AS a (scope, key, failures, last_failed_at, locked_until, expires_at)
+			VALUES (@scope, @key, 1, @at, @locked_until, @expires_at)
+			ON CONFLICT (scope, key) DO UPDATE SET
+				failures = `+attemptCount+`,
+				last_failed_at = EXCLUDED.last_failed_at,
+				locked_until = `+attemptLock+`,
+				expires_at = GREATEST(a.expires_at, EXCLUDED.expires_at, `+attemptLock+` + make_interval(secs => @window))
+			WHERE a.locked_until IS NULL OR a.locked_until <= EXCLUDED.last_failed_at
+			RETURNING failures
+		)
+		SELECT failures, NULL::timestamptz AS locked_until FROM attempt
+		UNION ALL
+		SELECT 0, locked_until FROM login_attempts
+		WHERE scope = @scope AND key = @key AND NOT EXISTS (SELECT 1 FROM attempt)`,
+		map[string]interface{}{
+			"scope":        key.Scope,
+			"key":          key.Key,
+			"at":           at.UTC(),
+			"locked_until": lockedUntil,
+			"expires_at":   expiresAt.UTC(),
+			"window":       window.Seconds(),
+			"max":          maxFailures,
+			"lockouts":     "{" + strings.Join(seconds, ",") + "}",
+			"last":         len(lockouts) - 1,
+		}).Scan(&result).Error
+	return result.Failures, result.LockedUntil, err
+}
+
+const (
+	// attemptCount is the count of an existing row including the attempt.
+	attemptCount = "CASE WHEN a.expires_at <= EXCLUDED.last_failed_at THEN 1 ELSE a.failures + 1 END"
+	// attemptLock locks the row once the count reaches the limit, for the lockout by attempts beyond it.
+	attemptLock = "CASE WHEN " + attemptCount + " >= @max::int THEN EXCLUDED.last_failed_at + " +
+		"make_interval(secs => (@lockouts::float8[])[LEAST(" + attemptCount + " - @max::int, @last::int) + 1]) END"
+)
+
+func (r *loginAttemptRepository) Release(ctx context.Context, key domain.LoginAttemptKey, unlock bool) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	updates := map[string]interface{}{
+		"failures": gorm.Expr("GREATEST(failures - 1, 0)"),
+	}
+	if unlock {
+		updates["locked_until"] = nil
+	}
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.LoginAttempt{}).
+		Where("scope = ? AND key = ?", key.Scope, key.Key).
+		Updates(updates).Error
+}
+
+func (r *loginAttemptRepository) Reset(ctx context.Context, key domain.LoginAttemptKey) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Where("scope = ? AND key = ?", key.Scope, key.Key).
+		Delete(&domain.LoginAttempt{}).Error
+}
+
+func (r *loginAttemptRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Where("expires_at < ?", before.UTC()).Delete(&domain.LoginAttempt{}).Error
+}
@@ -2,35 +2,80 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"movie-rating-service/config"
 	"movie-rating-service/internal/domain"
 	"time"
 )
 
 type movieRepository struct {
-	DB *gorm.DB
+	DB           *gorm.DB
+	ratingConfig config.RatingConfig
 }
 
 type MovieRepository interface {
-	Create(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) (*domain.Movie, error)
-	Update(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error
-	Delete(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error
+	Create(ctx context.Context, movie domain.Movie) (*domain.Movie, error)
+	Update(ctx context.Context, movie domain.Movie) error
+	Delete(ctx context.Context, movie domain.Movie) error
 	Get(ctx context.Context, id uint) (*domain.Movie, error)
-	List(ctx context.Context) ([]domain.Movie, error)
-	AddRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error
-	UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64, tx ...*gorm.DB) error
-	DeleteRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error
+	List(ctx context.Context, filter MovieFilter) ([]domain.Movie, int64, error)
+	AddRating(ctx context.Context, movieID uint, score float64) error
+	UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64) error
+	DeleteRating(ctx context.Context, movieID uint, score float64) error
+	ListRatingAggregates(ctx context.Context, afterID uint, limit int) ([]domain.RatingAggregate, error)
+	FixRatingAggregate(ctx context.Context, aggregate domain.RatingAggregate) (bool, error)
+	// RecomputeRatingAggregate overwrites the movie's aggregates with the ones derived from its ratings.
+	RecomputeRatingAggregate(ctx context.Context, movieID uint) error
+	// RatingPrior returns the prior mean the weighted ratings are currently computed with.
+	RatingPrior(ctx context.Context) (float64, error)
+	// RefreshRatingPrior recomputes the prior mean and realigns every movie's weighted rating with it.
+	RefreshRatingPrior(ctx context.Context) (float64, error)
+	// UpdateWatchlistCount adds delta to the number of users who have the movie on their watchlist.
+	UpdateWatchlistCount(ctx context.Context, movieID uint, delta int) error
 }
 
-func NewMovieRepository(db *gorm.DB) MovieRepository {
-	return &movieRepository{DB: db}
+type MovieFilter struct {
+	Genre          string
+	Director       string
+	YearFrom       int
+	YearTo         int
+	MinRating      float64
+	MinRatingCount int64
+	SortBy         string
+	Descending     bool
+	Limit          int
+	Offset         int
 }
 
-func (r *movieRepository) Create(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) (*domain.Movie, error) {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
+// movieSortColumns whitelists the columns a listing can be ordered by, so user input never reaches ORDER BY.
+var movieSortColumns = map[string]string{
+	"rating":          "rating",
+	"weighted_rating": "weighted_rating",
+	"year":            "year",
+	"title":           "title",
+	"created_at":      "created_at",
+}
+
+// fallbackPriorMean is the prior until anything has been rated and no RATING_PRIOR_MEAN is set.
+const fallbackPriorMean = 3.0
+
+// ratingPriorQuery reads the shared prior, so every replica applies the one stored by the last refresh.
+const ratingPriorQuery = "(SELECT mean FROM rating_prior WHERE id = 1)"
+
+func NewMovieRepository(db *gorm.DB, ratingConfig config.RatingConfig) MovieRepository {
+	return &movieRepository{DB: db, ratingConfig: ratingConfig}
+}
+
+func (r *movieRepository) Create(ctx context.Context, movie domain.Movie) (*domain.Movie, error) {
+	db := conn(ctx, r.DB)
+
+	prior, err := r.RatingPrior(ctx)
+	if err != nil {
+		return nil, err
 	}
+	movie.WeightedRating = domain.WeightedRating(0, 0, prior, r.ratingConfig.MinVotes)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -40,11 +85,8 @@ func (r *movieRepository) Create(ctx context.Context, movie domain.Movie, tx ...
 	return &movie, nil
 }
 
-func (r *movieRepository) Update(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *movieRepository) Update(ctx context.Context, movie domain.Movie) error {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -61,11 +103,8 @@ func (r *movieRepository) Update(ctx context.Context, movie domain.Movie, tx ...
 		}).Error
 }
 
-func (r *movieRepository) Delete(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *movieRepository) Delete(ctx context.Context, movie domain.Movie) error {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -74,7 +113,7 @@ func (r *movieRepository) Delete(ctx context.Context, movie domain.Movie, tx ...
 }
 
 func (r *movieRepository) Get(ctx context.Context, id uint) (*domain.Movie, error) {
-	db := r.DB
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -82,56 +121,228 @@ func (r *movieRepository) Get(ctx context.Context, id uint) (*domain.Movie, erro
 	return &movie, db.WithContext(ctxWithTimeout).Where("id=?", id).First(&movie).Error
 }
 
-func (r *movieRepository) List(ctx context.Context) ([]domain.Movie, error) {
-	db := r.DB
+func (r *movieRepository) List(ctx context.Context, filter MovieFilter) ([]domain.Movie, int64, error) {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
+
+	query := db.WithContext(ctxWithTimeout).Model(&domain.Movie{})
+	if filter.Genre != "" {
+		query = query.Where("LOWER(genre) = LOWER(?)", filter.Genre)
+	}
+	if filter.Director != "" {
+		query = query.Where("director ILIKE ?", "%"+escapeLike(filter.Director)+"%")
+	}
+	if filter.YearFrom > 0 {
+		query = query.Where("year >= ?", filter.YearFrom)
+	}
+	if filter.YearTo > 0 {
+		query = query.Where("year <= ?", filter.YearTo)
+	}
+	if filter.MinRating > 0 {
+		query = query.Where("rating >= ?", filter.MinRating)
+	}
+	if filter.MinRatingCount > 0 {
+		query = query.Where("rating_count >= ?", filter.MinRatingCount)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	// id is always the last key so pages stay stable when the sort column has ties.
+	order := "id " + direction
+	if column, ok := movieSortColumns[filter.SortBy]; ok {
+		order = column + " " + direction + ", " + order
+	}
+
 	var movies []domain.Movie
-	err := db.WithContext(ctxWithTimeout).Find(&movies).Error
-	return movies, err
+	err := query.
+		Order(order).
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&movies).Error
+	return movies, total, err
 }
 
-func (r *movieRepository) AddRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *movieRepository) AddRating(ctx context.Context, movieID uint, score float64) error {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
 	return db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where("id=?", movieID).Updates(map[string]interface{}{
-		"rating":       gorm.Expr("((rating * rating_count) + ? ) / GREATEST(rating_count + 1, 1)", score),
-		"rating_count": gorm.Expr("rating_count + 1"),
+		"rating":          gorm.Expr("((rating * rating_count) + ? ) / GREATEST(rating_count + 1, 1)", score),
+		"rating_count":    gorm.Expr("rating_count + 1"),
+		"weighted_rating": r.weightedRatingExpr("(rating * rating_count) + ?", "rating_count + 1", score),
 	}).Error
 }
 
-func (r *movieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *movieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64) error {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
 	return db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where("id=?", movieID).Updates(map[string]interface{}{
-		"rating": gorm.Expr("((rating * rating_count) - ? + ? ) / GREATEST(rating_count, 1)", oldScore, newScore),
+		"rating":          gorm.Expr("((rating * rating_count) - ? + ? ) / GREATEST(rating_count, 1)", oldScore, newScore),
+		"weighted_rating": r.weightedRatingExpr("(rating * rating_count) - ? + ?", "rating_count", oldScore, newScore),
 	}).Error
 }
 
-func (r *movieRepository) DeleteRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *movieRepository) DeleteRating(ctx context.Context, movieID uint, score float64) error {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
 	return db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where("id=?", movieID).Updates(map[string]interface{}{
-		"rating":       gorm.Expr("((rating * rating_count) - ? ) / GREATEST(rating_count - 1, 1)", score),
-		"rating_count": gorm.Expr("rating_count - 1"),
+		"rating":          gorm.Expr("((rating * rating_count) - ? ) / GREATEST(rating_count - 1, 1)", score),
+		"rating_count":    gorm.Expr("rating_count - 1"),
+		"weighted_rating": r.weightedRatingExpr("(rating * rating_count) - ?", "rating_count - 1", score),
+	}).Error
+}
+
+// weightedRatingExpr renders domain.WeightedRating in SQL with the stored prior. sum and count are
+// expressions over the row's current columns describing the values after the change; SET clauses all
+// see the old row.
+func (r *movieRepository) weightedRatingExpr(sum, count string, args ...interface{}) clause.Expr {
+	minVotes := r.ratingConfig.MinVotes
+	args = append(args, minVotes, minVotes)
+	return gorm.Expr("(("+sum+") + ? * "+ratingPriorQuery+") / GREATEST(("+count+") + ?, 1)", args...)
+}
+
+// ListRatingAggregates walks movies in id order (keyset pagination on afterID) and recomputes each
+// one's count and score sum from the ratings table next to the values stored on the movie.
+func (r *movieRepository) ListRatingAggregates(ctx context.Context, afterID uint, limit int) ([]domain.RatingAggregate, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	var aggregates []domain.RatingAggregate
+	err := db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).
+		Select(`movies.id AS movie_id,
+			movies.rating AS stored_rating,
+			movies.rating_count AS stored_count,
+			movies.weighted_rating AS stored_weighted,
+			actual.count AS actual_count,
+			actual.sum AS actual_sum`).
+		Joins(`LEFT JOIN LATERAL (
+			SELECT count(*) AS count, COALESCE(sum(score), 0) AS sum
+			FROM ratings
+			WHERE ratings.movie_id = movies.id AND ratings.deleted_at IS NULL
+		) actual ON true`).
+		Where("movies.id > ?", afterID).
+		Order("movies.id").
+		Limit(limit).
+		Scan(&aggregates).Error
+	return aggregates, err
+}
+
+// FixRatingAggregate overwrites the stored aggregates with the actual ones, but only if the stored values
+// are still the ones that were read. It reports false when a concurrent rating write got there first;
+// that movie is left for the next run instead of losing the write.
+func (r *movieRepository) FixRatingAggregate(ctx context.Context, aggregate domain.RatingAggregate) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).
+		Where("id = ?", aggregate.MovieID).
+		// rating columns are numeric and were read back as float64, so compare them with a tolerance.
+		Where("rating_count = ? AND abs(rating - ?) < 1e-9 AND abs(weighted_rating - ?) < 1e-9",
+			aggregate.StoredCount, aggregate.StoredRating, aggregate.StoredWeighted).
+		Updates(map[string]interface{}{
+			"rating":          aggregate.ActualRating(),
+			"rating_count":    aggregate.ActualCount,
+			"weighted_rating": r.weightedRatingExpr("?", "?", aggregate.ActualSum, aggregate.ActualCount),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieRepository) RecomputeRatingAggregate(ctx context.Context, movieID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	const (
+		sum   = "SELECT COALESCE(sum(score), 0) FROM ratings WHERE movie_id = ? AND deleted_at IS NULL"
+		count = "SELECT count(*) FROM ratings WHERE movie_id = ? AND deleted_at IS NULL"
+	)
+	return db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where("id = ?", movieID).Updates(map[string]interface{}{
+		"rating":          gorm.Expr("("+sum+") / GREATEST(("+count+"), 1)", movieID, movieID),
+		"rating_count":    gorm.Expr("("+count+")", movieID),
+		"weighted_rating": r.weightedRatingExpr(sum, count, movieID, movieID),
 	}).Error
 }
+
+func (r *movieRepository) RatingPrior(ctx context.Context) (float64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var prior float64
+	err := db.WithContext(ctxWithTimeout).Raw(ratingPriorQuery).Scan(&prior).Error
+	return prior, err
+}
+
+// RefreshRatingPrior sets the prior to RATING_PRIOR_MEAN or, when that is unset, to the mean of all
+// ratings. The mean is taken from the movie aggregates and rounded to two decimals, so a refresh only
+// rewrites every movie's weighted rating once the mean has actually moved.
+func (r *movieRepository) RefreshRatingPrior(ctx context.Context) (float64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	var prior float64
+	err := db.WithContext(ctxWithTimeout).Transaction(func(tx *gorm.DB) error {
+		if r.ratingConfig.PriorMean != nil {
+			prior = *r.ratingConfig.PriorMean
+		} else {
+			var mean sql.NullFloat64
+			err := tx.Model(&domain.Movie{}).
+				Select("round(sum(rating * rating_count) / NULLIF(sum(rating_count), 0), 2)").
+				Scan(&mean).Error
+			if err != nil {
+				return err
+			}
+			prior = fallbackPriorMean
+			if mean.Valid {
+				prior = mean.Float64
+			}
+		}
+
+		err := tx.Exec("UPDATE rating_prior SET mean = ?, updated_at = now() WHERE id = 1 AND mean <> ?", prior, prior).Error
+		if err != nil {
+			return err
+		}
+
+		weighted := r.weightedRatingExpr("rating * rating_count", "rating_count")
+		return tx.Model(&domain.Movie{}).
+			Where("weighted_rating IS DISTINCT FROM ?", weighted).
+			Update("weighted_rating", weighted).Error
+	})
+	return prior, err
+}
+
+func (r *movieRepository) UpdateWatchlistCount(ctx context.Context, movieID uint, delta int) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where("id = ?", movieID).
+		Update("watchlist_count", gorm.Expr("GREATEST(watchlist_count + ?, 0)", delta)).Error
+}
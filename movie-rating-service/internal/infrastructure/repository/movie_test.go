@@ -0,0 +1,76 @@
+//go:build unit_test
+
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"movie-rating-service/config"
+	"testing"
+)
+
+type MovieRepositoryTest struct {
+	suite.Suite
+}
+
+func Test_RunMovieRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieRepositoryTest))
+}
+
+func (s *MovieRepositoryTest) TestWeightedRatingExpr() {
+	t := s.T()
+
+	tests := []struct {
+		name     string
+		minVotes int
+		sum      string
+		count    string
+		args     []interface{}
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{
+			name:     "add rating",
+			minVotes: 10,
+			sum:      "(rating * rating_count) + ?",
+			count:    "rating_count + 1",
+			args:     []interface{}{4.5},
+			wantSQL:  "(((rating * rating_count) + ?) + ? * " + ratingPriorQuery + ") / GREATEST((rating_count + 1) + ?, 1)",
+			wantVars: []interface{}{4.5, 10, 10},
+		},
+		{
+			name:     "update rating",
+			minVotes: 10,
+			sum:      "(rating * rating_count) - ? + ?",
+			count:    "rating_count",
+			args:     []interface{}{2.0, 4.0},
+			wantSQL:  "(((rating * rating_count) - ? + ?) + ? * " + ratingPriorQuery + ") / GREATEST((rating_count) + ?, 1)",
+			wantVars: []interface{}{2.0, 4.0, 10, 10},
+		},
+		{
+			name:     "fixed aggregate without votes",
+			minVotes: 10,
+			sum:      "?",
+			count:    "?",
+			args:     []interface{}{0.0, int64(0)},
+			wantSQL:  "((?) + ? * " + ratingPriorQuery + ") / GREATEST((?) + ?, 1)",
+			wantVars: []interface{}{0.0, int64(0), 10, 10},
+		},
+		{
+			name:     "no vote threshold",
+			minVotes: 0,
+			sum:      "rating * rating_count",
+			count:    "rating_count",
+			wantSQL:  "((rating * rating_count) + ? * " + ratingPriorQuery + ") / GREATEST((rating_count) + ?, 1)",
+			wantVars: []interface{}{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		r := &movieRepository{ratingConfig: config.RatingConfig{MinVotes: tt.minVotes}}
+
+		expr := r.weightedRatingExpr(tt.sum, tt.count, tt.args...)
+
+		assert.Equal(t, tt.wantSQL, expr.SQL, tt.name)
+		assert.Equal(t, tt.wantVars, expr.Vars, tt.name)
+	}
+}
@@ -2,120 +2,382 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"log/slog"
+	"movie-rating-service/config"
 	"movie-rating-service/internal/domain"
-	"sync"
+	"movie-rating-service/internal/infrastructure/cache"
+	"movie-rating-service/internal/infrastructure/db"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// movieCacheChannel is the Postgres notification channel announcing changed movies. Payloads are
+// "<instance>:<movie id>", so a replica can skip its own announcements; movieCacheAll in place of
+// the id announces a change to every movie.
+const (
+	movieCacheChannel = "movie_cache_invalidate"
+	movieCacheAll     = "*"
+)
+
+var (
+	movieCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "movie_cache_hits_total",
+		Help: "Movie lookups served from the cache.",
+	})
+	movieCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "movie_cache_misses_total",
+		Help: "Movie lookups that had to go to the database.",
+	})
+	movieCacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "movie_cache_evictions_total",
+		Help: "Movie cache entries dropped, by reason (expired, capacity, invalidated).",
+	}, []string{"reason"})
+)
+
+// CachedMovieRepository is a MovieRepository that keeps recently read movies in a cache.Cache.
+type CachedMovieRepository interface {
+	MovieRepository
+	// Invalidate drops a movie changed by another replica.
+	Invalidate(ctx context.Context, id uint)
+	// InvalidateAll drops every cached movie, e.g. after invalidations may have been missed.
+	InvalidateAll(ctx context.Context)
+	Close()
+}
+
 type cachedMovieRepository struct {
 	movieRepository MovieRepository
-	idCache         map[uint]cacheItem
-	mu              sync.RWMutex
-	ttl             time.Duration
+	// idCache holds JSON "null" for IDs known not to exist.
+	idCache     cache.Cache
+	notifier    db.Notifier
+	instance    string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	loads       singleflight.Group
 }
-type cacheItem struct {
-	data      *domain.Movie
-	expiresAt time.Time
+
+// NewMovieCache builds the cache backend selected by cfg.Backend.
+func NewMovieCache(cfg config.MovieCacheConfig, redisConfig config.RedisConfig) cache.Cache {
+	if cfg.Backend == "redis" {
+		return cache.NewRedis(redis.NewClient(&redis.Options{
+			Addr:     redisConfig.Addr,
+			Password: redisConfig.Password,
+			DB:       redisConfig.DB,
+		}), "movie:")
+	}
+
+	return cache.NewMemory(cfg.Size, cfg.SweepInterval, func(_ string, reason cache.EvictReason) {
+		movieCacheEvictions.WithLabelValues(string(reason)).Inc()
+	})
 }
 
-func NewCachedMovieRepository(movieRepository MovieRepository, ttl time.Duration) MovieRepository {
+// NewCachedMovieRepository decorates movieRepository with idCache. With a notifier, every write is
+// announced to the other replicas (see ListenMovieCacheInvalidations); a shared idCache needs none.
+func NewCachedMovieRepository(movieRepository MovieRepository, idCache cache.Cache, notifier db.Notifier, cfg config.MovieCacheConfig) (CachedMovieRepository, error) {
+	instance := make([]byte, 8)
+	if _, err := rand.Read(instance); err != nil {
+		return nil, fmt.Errorf("failed to generate movie cache instance id: %w", err)
+	}
+
 	return &cachedMovieRepository{
 		movieRepository: movieRepository,
-		idCache:         make(map[uint]cacheItem),
-		ttl:             ttl,
+		idCache:         idCache,
+		notifier:        notifier,
+		instance:        hex.EncodeToString(instance),
+		ttl:             cfg.TTL,
+		negativeTTL:     cfg.NegativeTTL,
+	}, nil
+}
+
+// ListenMovieCacheInvalidations applies other replicas' movie changes to repo until ctx is done.
+func ListenMovieCacheInvalidations(ctx context.Context, dsn string, repo CachedMovieRepository) {
+	c, ok := repo.(*cachedMovieRepository)
+	if !ok {
+		return
 	}
+
+	db.Listen(ctx, dsn, movieCacheChannel, c.InvalidateAll, func(ctx context.Context, payload string) {
+		instance, id, found := strings.Cut(payload, ":")
+		if !found || instance == c.instance {
+			return
+		}
+		if id == movieCacheAll {
+			c.InvalidateAll(ctx)
+			return
+		}
+		movieID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return
+		}
+		c.Invalidate(ctx, uint(movieID))
+	})
 }
 
 func (c *cachedMovieRepository) Get(ctx context.Context, id uint) (*domain.Movie, error) {
-	now := time.Now()
+	data, ok, err := c.idCache.Get(ctx, movieKey(id))
+	if err != nil {
+		slog.Warn("Movie cache read failed", "id", id, "error", err)
+	}
+	if ok {
+		var movie *domain.Movie
+		if err = json.Unmarshal(data, &movie); err == nil {
+			movieCacheHits.Inc()
+			if movie == nil {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return movie, nil
+		}
+	}
+	movieCacheMisses.Inc()
 
-	c.mu.RLock()
-	item, ok := c.idCache[id]
-	if ok && item.expiresAt.After(now) {
-		c.mu.RUnlock()
-		return item.data, nil
+	// Inside a transaction the movie may include uncommitted changes, so it is neither cached nor
+	// shared with other callers.
+	if db.InTx(ctx) {
+		return c.movieRepository.Get(ctx, id)
 	}
-	c.mu.RUnlock()
 
-	movie, err := c.movieRepository.Get(ctx, id)
+	// Concurrent misses for the same ID share one query. It must not fail because the caller that
+	// happened to start it went away.
+	movie, err, _ := c.loads.Do(movieKey(id), func() (interface{}, error) {
+		return c.load(context.WithoutCancel(ctx), id)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	c.mu.Lock()
-	c.idCache[id] = cacheItem{data: movie, expiresAt: now.Add(c.ttl)}
-	c.mu.Unlock()
-
-	return movie, nil
+	return movie.(*domain.Movie), nil
 }
 
-func (c *cachedMovieRepository) Create(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) (*domain.Movie, error) {
-	return c.movieRepository.Create(ctx, movie, tx...)
+func (c *cachedMovieRepository) Invalidate(ctx context.Context, id uint) {
+	c.invalidate(ctx, id)
 }
 
-func (c *cachedMovieRepository) Update(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	return c.movieRepository.Update(ctx, movie, tx...)
+func (c *cachedMovieRepository) InvalidateAll(ctx context.Context) {
+	if err := c.idCache.Clear(ctx); err != nil {
+		slog.Warn("Movie cache clear failed", "error", err)
+	}
 }
 
-func (c *cachedMovieRepository) Delete(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	return c.movieRepository.Delete(ctx, movie, tx...)
+func (c *cachedMovieRepository) Close() {
+	if err := c.idCache.Close(); err != nil {
+		slog.Warn("Movie cache close failed", "error", err)
+	}
 }
 
-func (c *cachedMovieRepository) List(ctx context.Context) ([]domain.Movie, error) {
-	return c.movieRepository.List(ctx)
+func (c *cachedMovieRepository) Create(ctx context.Context, movie domain.Movie) (*domain.Movie, error) {
+	created, err := c.movieRepository.Create(ctx, movie)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new ID may have been looked up, and negatively cached, before it existed.
+	if err = c.changed(ctx, created.ID); err != nil {
+		return nil, err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.invalidate(ctx, created.ID) })
+	return created, nil
 }
 
-func (c *cachedMovieRepository) AddRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	err := c.movieRepository.AddRating(ctx, movieID, score, tx...)
+func (c *cachedMovieRepository) Update(ctx context.Context, movie domain.Movie) error {
+	err := c.movieRepository.Update(ctx, movie)
 	if err != nil {
 		return err
 	}
 
-	movie, err := c.movieRepository.Get(ctx, movieID)
-	if err != nil {
+	if err = c.changed(ctx, movie.ID); err != nil {
 		return err
 	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.invalidate(ctx, movie.ID) })
+	return nil
+}
 
-	c.mu.Lock()
-	c.idCache[movieID] = cacheItem{data: movie, expiresAt: time.Now().Add(c.ttl)}
-	c.mu.Unlock()
+func (c *cachedMovieRepository) Delete(ctx context.Context, movie domain.Movie) error {
+	err := c.movieRepository.Delete(ctx, movie)
+	if err != nil {
+		return err
+	}
 
+	if err = c.changed(ctx, movie.ID); err != nil {
+		return err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.invalidate(ctx, movie.ID) })
 	return nil
 }
 
-func (c *cachedMovieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64, tx ...*gorm.DB) error {
-	err := c.movieRepository.UpdateRating(ctx, movieID, oldScore, newScore, tx...)
+func (c *cachedMovieRepository) List(ctx context.Context, filter MovieFilter) ([]domain.Movie, int64, error) {
+	return c.movieRepository.List(ctx, filter)
+}
+
+func (c *cachedMovieRepository) AddRating(ctx context.Context, movieID uint, score float64) error {
+	err := c.movieRepository.AddRating(ctx, movieID, score)
 	if err != nil {
 		return err
 	}
 
-	movie, err := c.movieRepository.Get(ctx, movieID)
-	if err != nil {
+	if err = c.changed(ctx, movieID); err != nil {
 		return err
 	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.refresh(ctx, movieID) })
+	return nil
+}
 
-	c.mu.Lock()
-	c.idCache[movieID] = cacheItem{data: movie, expiresAt: time.Now().Add(c.ttl)}
-	c.mu.Unlock()
+func (c *cachedMovieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore, newScore float64) error {
+	err := c.movieRepository.UpdateRating(ctx, movieID, oldScore, newScore)
+	if err != nil {
+		return err
+	}
 
+	if err = c.changed(ctx, movieID); err != nil {
+		return err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.refresh(ctx, movieID) })
 	return nil
 }
 
-func (c *cachedMovieRepository) DeleteRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	err := c.movieRepository.DeleteRating(ctx, movieID, score, tx...)
+func (c *cachedMovieRepository) DeleteRating(ctx context.Context, movieID uint, score float64) error {
+	err := c.movieRepository.DeleteRating(ctx, movieID, score)
 	if err != nil {
 		return err
 	}
 
-	movie, err := c.movieRepository.Get(ctx, movieID)
+	if err = c.changed(ctx, movieID); err != nil {
+		return err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.refresh(ctx, movieID) })
+	return nil
+}
+
+func (c *cachedMovieRepository) ListRatingAggregates(ctx context.Context, afterID uint, limit int) ([]domain.RatingAggregate, error) {
+	return c.movieRepository.ListRatingAggregates(ctx, afterID, limit)
+}
+
+func (c *cachedMovieRepository) FixRatingAggregate(ctx context.Context, aggregate domain.RatingAggregate) (bool, error) {
+	fixed, err := c.movieRepository.FixRatingAggregate(ctx, aggregate)
+	if err != nil {
+		return false, err
+	}
+	if !fixed {
+		return false, nil
+	}
+
+	if err = c.changed(ctx, aggregate.MovieID); err != nil {
+		return false, err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.invalidate(ctx, aggregate.MovieID) })
+	return true, nil
+}
+
+func (c *cachedMovieRepository) RecomputeRatingAggregate(ctx context.Context, movieID uint) error {
+	err := c.movieRepository.RecomputeRatingAggregate(ctx, movieID)
 	if err != nil {
 		return err
 	}
 
-	c.mu.Lock()
-	c.idCache[movieID] = cacheItem{data: movie, expiresAt: time.Now().Add(c.ttl)}
-	c.mu.Unlock()
+	if err = c.changed(ctx, movieID); err != nil {
+		return err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.refresh(ctx, movieID) })
+	return nil
+}
 
+func (c *cachedMovieRepository) RatingPrior(ctx context.Context) (float64, error) {
+	return c.movieRepository.RatingPrior(ctx)
+}
+
+func (c *cachedMovieRepository) RefreshRatingPrior(ctx context.Context) (float64, error) {
+	prior, err := c.movieRepository.RefreshRatingPrior(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.notifier != nil {
+		if err = c.notifier.Notify(ctx, movieCacheChannel, c.instance+":"+movieCacheAll); err != nil {
+			return 0, err
+		}
+	}
+	db.AfterCommit(ctx, c.InvalidateAll)
+	return prior, nil
+}
+
+func (c *cachedMovieRepository) UpdateWatchlistCount(ctx context.Context, movieID uint, delta int) error {
+	err := c.movieRepository.UpdateWatchlistCount(ctx, movieID, delta)
+	if err != nil {
+		return err
+	}
+
+	if err = c.changed(ctx, movieID); err != nil {
+		return err
+	}
+	db.AfterCommit(ctx, func(ctx context.Context) { c.refresh(ctx, movieID) })
 	return nil
 }
+
+func (c *cachedMovieRepository) load(ctx context.Context, id uint) (*domain.Movie, error) {
+	// Writes move the version on, so a load that raced with a write does not store what it read
+	// before the write. Without a version nothing is stored.
+	version, err := c.idCache.Version(ctx, movieKey(id))
+	if err != nil {
+		slog.Warn("Movie cache read failed", "id", id, "error", err)
+		return c.movieRepository.Get(ctx, id)
+	}
+
+	movie, err := c.movieRepository.Get(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if c.negativeTTL > 0 {
+			c.store(ctx, id, nil, c.negativeTTL, version)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(ctx, id, movie, c.ttl, version)
+	return movie, nil
+}
+
+func (c *cachedMovieRepository) store(ctx context.Context, id uint, movie *domain.Movie, ttl time.Duration, version uint64) {
+	data, err := json.Marshal(movie)
+	if err == nil {
+		_, err = c.idCache.SetIfVersion(ctx, movieKey(id), data, ttl, version)
+	}
+	if err != nil {
+		slog.Warn("Movie cache write failed", "id", id, "error", err)
+	}
+}
+
+func (c *cachedMovieRepository) invalidate(ctx context.Context, id uint) {
+	c.loads.Forget(movieKey(id))
+	if err := c.idCache.Delete(ctx, movieKey(id)); err != nil {
+		slog.Warn("Movie cache invalidation failed", "id", id, "error", err)
+	}
+}
+
+// refresh reloads a movie after its aggregates changed, so the next read does not pay for a miss.
+// The write already succeeded, so a failed reload only leaves the entry out.
+func (c *cachedMovieRepository) refresh(ctx context.Context, id uint) {
+	c.invalidate(ctx, id)
+	_, _ = c.load(ctx, id)
+}
+
+// changed announces a write to the other replicas. Inside a transaction Postgres holds the
+// notification back until commit, so they never drop an entry for a write that rolls back.
+func (c *cachedMovieRepository) changed(ctx context.Context, id uint) error {
+	if c.notifier == nil {
+		return nil
+	}
+	return c.notifier.Notify(ctx, movieCacheChannel, c.instance+":"+strconv.FormatUint(uint64(id), 10))
+}
+
+func movieKey(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
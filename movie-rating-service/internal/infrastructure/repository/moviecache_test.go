@@ -0,0 +1,317 @@
+//go:build unit_test
+
+package repository
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"movie-rating-service/config"
+	"movie-rating-service/internal/domain"
+	"movie-rating-service/internal/infrastructure/cache"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubMovieRepository keeps movies in memory and counts reads, standing in for the database.
+type stubMovieRepository struct {
+	MovieRepository
+	movies map[uint]domain.Movie
+	gets   atomic.Int32
+	// gate, if set, holds every Get until it is closed.
+	gate chan struct{}
+}
+
+func (s *stubMovieRepository) Get(_ context.Context, id uint) (*domain.Movie, error) {
+	s.gets.Add(1)
+	if s.gate != nil {
+		<-s.gate
+	}
+	movie, ok := s.movies[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &movie, nil
+}
+
+func (s *stubMovieRepository) Create(_ context.Context, movie domain.Movie) (*domain.Movie, error) {
+	s.movies[movie.ID] = movie
+	return &movie, nil
+}
+
+func (s *stubMovieRepository) Update(_ context.Context, movie domain.Movie) error {
+	s.movies[movie.ID] = movie
+	return nil
+}
+
+func (s *stubMovieRepository) Delete(_ context.Context, movie domain.Movie) error {
+	delete(s.movies, movie.ID)
+	return nil
+}
+
+func (s *stubMovieRepository) AddRating(_ context.Context, movieID uint, score float64) error {
+	movie := s.movies[movieID]
+	movie.Rating = (movie.Rating*float64(movie.RatingCount) + score) / float64(movie.RatingCount+1)
+	movie.RatingCount++
+	s.movies[movieID] = movie
+	return nil
+}
+
+func (s *stubMovieRepository) UpdateWatchlistCount(_ context.Context, movieID uint, delta int) error {
+	movie := s.movies[movieID]
+	movie.WatchlistCount += int64(delta)
+	s.movies[movieID] = movie
+	return nil
+}
+
+type stubNotifier struct {
+	payloads []string
+}
+
+func (s *stubNotifier) Notify(_ context.Context, channel, payload string) error {
+	if channel == movieCacheChannel {
+		s.payloads = append(s.payloads, payload)
+	}
+	return nil
+}
+
+type MovieCacheTest struct {
+	suite.Suite
+	repo     *stubMovieRepository
+	notifier *stubNotifier
+	cache    CachedMovieRepository
+}
+
+func (m *MovieCacheTest) SetupTest() {
+	m.repo = &stubMovieRepository{movies: map[uint]domain.Movie{
+		1: {Model: gorm.Model{ID: 1}, Title: "Heat"},
+	}}
+	m.notifier = &stubNotifier{}
+	var err error
+	m.cache, err = NewCachedMovieRepository(m.repo, cache.NewMemory(10, 0, nil), m.notifier, config.MovieCacheConfig{TTL: time.Minute, NegativeTTL: time.Minute})
+	m.Require().NoError(err)
+}
+
+func (m *MovieCacheTest) TearDownTest() {
+	m.cache.Close()
+}
+
+func Test_RunMovieCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieCacheTest))
+}
+
+func (m *MovieCacheTest) TestGet_Served_From_Cache() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, err := m.cache.Get(ctx, 1)
+	assert.NoError(t, err)
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Heat", movie.Title)
+	assert.Equal(t, int32(1), m.repo.gets.Load())
+}
+
+func (m *MovieCacheTest) TestUpdate_Invalidates() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, _ = m.cache.Get(ctx, 1)
+	err := m.cache.Update(ctx, domain.Movie{Model: gorm.Model{ID: 1}, Title: "Heat (1995)"})
+	assert.NoError(t, err)
+
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Heat (1995)", movie.Title)
+	assert.Equal(t, int32(2), m.repo.gets.Load())
+	assert.Len(t, m.notifier.payloads, 1)
+	assert.True(t, strings.HasSuffix(m.notifier.payloads[0], ":1"))
+}
+
+func (m *MovieCacheTest) TestDelete_Invalidates() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, _ = m.cache.Get(ctx, 1)
+	err := m.cache.Delete(ctx, domain.Movie{Model: gorm.Model{ID: 1}})
+	assert.NoError(t, err)
+
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Nil(t, movie)
+}
+
+func (m *MovieCacheTest) TestAddRating_Refreshes() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, _ = m.cache.Get(ctx, 1)
+	err := m.cache.AddRating(ctx, 1, 4)
+	assert.NoError(t, err)
+
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), movie.RatingCount)
+	assert.Equal(t, 4.0, movie.Rating)
+	// One load before the rating and the refresh after it; the last Get is a hit.
+	assert.Equal(t, int32(2), m.repo.gets.Load())
+}
+
+func (m *MovieCacheTest) TestUpdateWatchlistCount_Refreshes() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, _ = m.cache.Get(ctx, 1)
+	err := m.cache.UpdateWatchlistCount(ctx, 1, 1)
+	assert.NoError(t, err)
+
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), movie.WatchlistCount)
+	assert.Len(t, m.notifier.payloads, 1)
+	assert.True(t, strings.HasSuffix(m.notifier.payloads[0], ":1"))
+}
+
+func (m *MovieCacheTest) TestGet_Caches_Not_Found() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, err := m.cache.Get(ctx, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = m.cache.Get(ctx, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Equal(t, int32(1), m.repo.gets.Load())
+
+	_, err = m.cache.Create(ctx, domain.Movie{Model: gorm.Model{ID: 2}, Title: "Ronin"})
+	assert.NoError(t, err)
+
+	movie, err := m.cache.Get(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Ronin", movie.Title)
+}
+
+func (m *MovieCacheTest) TestGet_Coalesces_Concurrent_Misses() {
+	t := m.T()
+	ctx := context.TODO()
+
+	m.repo.gate = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			movie, err := m.cache.Get(ctx, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Heat", movie.Title)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return m.repo.gets.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(m.repo.gate)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), m.repo.gets.Load())
+}
+
+func (m *MovieCacheTest) TestInvalidate_From_Other_Replica() {
+	t := m.T()
+	ctx := context.TODO()
+
+	_, _ = m.cache.Get(ctx, 1)
+	// Another replica renamed the movie; only its notification reaches this one.
+	m.repo.movies[1] = domain.Movie{Model: gorm.Model{ID: 1}, Title: "Heat (1995)"}
+	m.cache.Invalidate(ctx, 1)
+
+	movie, err := m.cache.Get(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Heat (1995)", movie.Title)
+}
+
+// loadRacingWith reads movie 1 while invalidate runs, then reports how often the database was read
+// once the cache is asked again.
+func (m *MovieCacheTest) loadRacingWith(invalidate func(ctx context.Context)) int32 {
+	ctx := context.TODO()
+
+	m.repo.movies[2] = domain.Movie{Model: gorm.Model{ID: 2}, Title: "Ronin"}
+	m.repo.gate = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = m.cache.Get(ctx, 1)
+	}()
+
+	assert.Eventually(m.T(), func() bool { return m.repo.gets.Load() == 1 }, time.Second, time.Millisecond)
+	invalidate(ctx)
+	close(m.repo.gate)
+	<-done
+
+	_, _ = m.cache.Get(ctx, 1)
+	return m.repo.gets.Load()
+}
+
+func (m *MovieCacheTest) TestLoad_Racing_Write_To_Same_Movie_Not_Stored() {
+	gets := m.loadRacingWith(func(ctx context.Context) { m.cache.Invalidate(ctx, 1) })
+
+	assert.Equal(m.T(), int32(2), gets)
+}
+
+func (m *MovieCacheTest) TestLoad_Racing_Write_To_Other_Movie_Stored() {
+	gets := m.loadRacingWith(func(ctx context.Context) { m.cache.Invalidate(ctx, 2) })
+
+	assert.Equal(m.T(), int32(1), gets)
+}
+
+func (m *MovieCacheTest) TestLoad_Racing_Invalidate_All_Not_Stored() {
+	gets := m.loadRacingWith(m.cache.InvalidateAll)
+
+	assert.Equal(m.T(), int32(2), gets)
+}
+
+func Test_MovieCache_Redis_Load_Racing_Other_Replica_Write(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.TODO()
+
+	repo := &stubMovieRepository{movies: map[uint]domain.Movie{1: {Model: gorm.Model{ID: 1}, Title: "Heat"}}, gate: make(chan struct{})}
+	newReplica := func() CachedMovieRepository {
+		replica, err := NewCachedMovieRepository(repo, cache.NewRedis(redis.NewClient(&redis.Options{Addr: server.Addr()}), "movie:"), nil, config.MovieCacheConfig{TTL: time.Minute})
+		assert.NoError(t, err)
+		return replica
+	}
+	reader, writer := newReplica(), newReplica()
+	defer reader.Close()
+	defer writer.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = reader.Get(ctx, 1)
+	}()
+	assert.Eventually(t, func() bool { return repo.gets.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The write lands on the other replica while the reader still holds the old movie.
+	repo.movies[1] = domain.Movie{Model: gorm.Model{ID: 1}, Title: "Heat (1995)"}
+	writer.Invalidate(ctx, 1)
+	close(repo.gate)
+	<-done
+
+	assert.False(t, server.Exists("movie:1"))
+	movie, err := reader.Get(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Heat (1995)", movie.Title)
+}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"strings"
+	"time"
+)
+
+type movieListRepository struct {
+	DB *gorm.DB
+}
+
+type MovieListRepository interface {
+	Create(ctx context.Context, list domain.MovieList) (*domain.MovieList, error)
+	// Get returns the list with its owner and editors, deleted owners included, and the owner,
+	// editors and visibility of the list it was cloned from.
+	Get(ctx context.Context, id uint) (*domain.MovieList, error)
+	// GetBySlug returns the list like Get.
+	GetBySlug(ctx context.Context, slug string) (*domain.MovieList, error)
+	// Lock locks the list's row until the end of the transaction, so that changes to its items are
+	// made one at a time.
+	Lock(ctx context.Context, id uint) error
+	Update(ctx context.Context, id uint, changes map[string]interface{}) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter MovieListFilter) ([]domain.MovieListSummary, int64, error)
+	// GetByOwnerID returns the user's lists with all their items, for an export.
+	GetByOwnerID(ctx context.Context, ownerID uint) ([]domain.MovieList, error)
+
+	// AddEditor reports false if the user already was an editor.
+	AddEditor(ctx context.Context, listID, userID uint) (bool, error)
+	// RemoveEditor reports false if the user was not an editor.
+	RemoveEditor(ctx context.Context, listID, userID uint) (bool, error)
+
+	CountItems(ctx context.Context, listID uint) (int64, error)
+	// AddItem puts the movie at the end of the list. It reports false if it was already there.
+	AddItem(ctx context.Context, item domain.MovieListItem) (bool, error)
+	// UpdateItemNote reports false if the movie is not on the list.
+	UpdateItemNote(ctx context.Context, listID, movieID uint, note string) (bool, error)
+	// RemoveItem reports false if the movie was not on the list.
+	RemoveItem(ctx context.Context, listID, movieID uint) (bool, error)
+	// ListMovieIDs returns the movies on the list in order, and locks the items until the end of the
+	// transaction.
+	ListMovieIDs(ctx context.Context, listID uint) ([]uint, error)
+	// Reorder gives each movie the position of its index in movieIDs.
+	Reorder(ctx context.Context, listID uint, movieIDs []uint) error
+	// ListItems leaves out movies deleted since they were added.
+	ListItems(ctx context.Context, listID uint, limit, offset int) ([]domain.MovieListItem, int64, error)
+	// CopyItems copies the items of one list, notes and order included, to another. Deleted movies
+	// are left out.
+	CopyItems(ctx context.Context, fromListID, toListID, addedByID uint) error
+}
+
+type MovieListFilter struct {
+	// Visibility, if set, keeps only lists with that visibility.
+	Visibility string
+	// MemberID, if set, keeps only lists the user owns or edits.
+	MemberID uint
+	Query    string
+	Limit    int
+	Offset   int
+}
+
+func NewMovieListRepository(db *gorm.DB) MovieListRepository {
+	return &movieListRepository{DB: db}
+}
+
+func (r *movieListRepository) Create(ctx context.Context, list domain.MovieList) (*domain.MovieList, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := db.WithContext(ctxWithTimeout).Omit("Owner", "Editors", "Items", "ClonedFrom").Create(&list).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (r *movieListRepository) Get(ctx context.Context, id uint) (*domain.MovieList, error) {
+	return r.get(ctx, "id = ?", id)
+}
+
+func (r *movieListRepository) GetBySlug(ctx context.Context, slug string) (*domain.MovieList, error) {
+	return r.get(ctx, "slug = ?", slug)
+}
+
+func (r *movieListRepository) get(ctx context.Context, query string, args ...interface{}) (*domain.MovieList, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	list := domain.MovieList{}
+	err := preloadClonedFrom(db.WithContext(ctxWithTimeout)).
+		Preload("Owner", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "username")
+		}).
+		Preload("Editors", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username").Order("username")
+		}).
+		Where(query, args...).
+		First(&list).Error
+	return &list, err
+}
+
+// preloadClonedFrom loads what it takes to tell who may see the list a list was cloned from.
+func preloadClonedFrom(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("ClonedFrom", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "owner_id", "visibility")
+		}).
+		Preload("ClonedFrom.Editors", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id")
+		})
+}
+
+func (r *movieListRepository) Lock(ctx context.Context, id uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var lockedID uint
+	result := db.WithContext(ctxWithTimeout).Raw("SELECT id FROM movie_lists WHERE id = ? FOR UPDATE", id).Scan(&lockedID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *movieListRepository) Update(ctx context.Context, id uint, changes map[string]interface{}) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Model(&domain.MovieList{ID: id}).Updates(changes).Error
+}
+
+func (r *movieListRepository) Delete(ctx context.Context, id uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Delete(&domain.MovieList{}, id).Error
+}
+
+func (r *movieListRepository) List(ctx context.Context, filter MovieListFilter) ([]domain.MovieListSummary, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	query := db.WithContext(ctxWithTimeout).Model(&domain.MovieList{})
+	if filter.Visibility != "" {
+		query = query.Where("visibility = ?", filter.Visibility)
+	}
+	if filter.MemberID != 0 {
+		query = query.Where("owner_id = ? OR id IN (SELECT list_id FROM movie_list_editors WHERE user_id = ?)", filter.MemberID, filter.MemberID)
+	}
+	if filter.Query != "" {
+		query = query.Where("name ILIKE ?", "%"+escapeLike(filter.Query)+"%")
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var lists []domain.MovieListSummary
+	err := preloadClonedFrom(query).
+		Select("movie_lists.*, (SELECT count(*) FROM movie_list_items WHERE list_id = movie_lists.id) AS item_count").
+		Preload("Owner", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "username")
+		}).
+		Order("created_at DESC, id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&lists).Error
+	return lists, total, err
+}
+
+func (r *movieListRepository) GetByOwnerID(ctx context.Context, ownerID uint) ([]domain.MovieList, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	var lists []domain.MovieList
+	err := db.WithContext(ctxWithTimeout).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Items.Movie", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Where("owner_id = ?", ownerID).
+		Order("id").
+		Find(&lists).Error
+	return lists, err
+}
+
+func (r *movieListRepository) AddEditor(ctx context.Context, listID, userID uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Exec(`INSERT INTO movie_list_editors (list_id, user_id) VALUES (?, ?)
+		ON CONFLICT (list_id, user_id) DO NOTHING`, listID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieListRepository) RemoveEditor(ctx context.Context, listID, userID uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Exec("DELETE FROM movie_list_editors WHERE list_id = ? AND user_id = ?", listID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieListRepository) CountItems(ctx context.Context, listID uint) (int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var count int64
+	err := db.WithContext(ctxWithTimeout).Model(&domain.MovieListItem{}).Where("list_id = ?", listID).Count(&count).Error
+	return count, err
+}
+
+func (r *movieListRepository) AddItem(ctx context.Context, item domain.MovieListItem) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Exec(`INSERT INTO movie_list_items (created_at, list_id, movie_id, position, note, added_by_id)
+		SELECT now(), ?, ?, COALESCE(MAX(position) + 1, 0), ?, ? FROM movie_list_items WHERE list_id = ?
+		ON CONFLICT (list_id, movie_id) DO NOTHING`, item.ListID, item.MovieID, item.Note, item.AddedByID, item.ListID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieListRepository) UpdateItemNote(ctx context.Context, listID, movieID uint, note string) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Model(&domain.MovieListItem{}).
+		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Update("note", note)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieListRepository) RemoveItem(ctx context.Context, listID, movieID uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&domain.MovieListItem{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *movieListRepository) ListMovieIDs(ctx context.Context, listID uint) ([]uint, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var movieIDs []uint
+	err := db.WithContext(ctxWithTimeout).
+		Raw("SELECT movie_id FROM movie_list_items WHERE list_id = ? ORDER BY position, id FOR UPDATE", listID).
+		Scan(&movieIDs).Error
+	return movieIDs, err
+}
+
+func (r *movieListRepository) Reorder(ctx context.Context, listID uint, movieIDs []uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if len(movieIDs) == 0 {
+		return nil
+	}
+	var cases strings.Builder
+	args := make([]interface{}, 0, 2*len(movieIDs))
+	for position, movieID := range movieIDs {
+		cases.WriteString(" WHEN ? THEN ?")
+		args = append(args, movieID, position)
+	}
+	return db.WithContext(ctxWithTimeout).Model(&domain.MovieListItem{}).
+		Where("list_id = ? AND movie_id IN ?", listID, movieIDs).
+		Update("position", gorm.Expr("CASE movie_id"+cases.String()+" END", args...)).Error
+}
+
+func (r *movieListRepository) ListItems(ctx context.Context, listID uint, limit, offset int) ([]domain.MovieListItem, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	query := db.WithContext(ctxWithTimeout).Model(&domain.MovieListItem{}).
+		Joins("JOIN movies ON movies.id = movie_list_items.movie_id AND movies.deleted_at IS NULL").
+		Where("movie_list_items.list_id = ?", listID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.MovieListItem
+	err := query.
+		Preload("Movie").
+		Order("movie_list_items.position, movie_list_items.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error
+	return items, total, err
+}
+
+func (r *movieListRepository) CopyItems(ctx context.Context, fromListID, toListID, addedByID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Exec(`INSERT INTO movie_list_items (created_at, list_id, movie_id, position, note, added_by_id)
+		SELECT now(), ?, i.movie_id, i.position, i.note, ?
+		FROM movie_list_items i JOIN movies m ON m.id = i.movie_id AND m.deleted_at IS NULL
+		WHERE i.list_id = ?`, toListID, addedByID, fromListID).Error
+}
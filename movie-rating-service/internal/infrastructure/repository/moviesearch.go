@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"html"
+	"movie-rating-service/internal/domain"
+	"strings"
+	"time"
+)
+
+// The search_vector column and trigram indexes used here are created by the baseline migration.
+const (
+	searchMatch = `(search_vector @@ websearch_to_tsquery('english', @q) OR @q <% title OR @q <% director)`
+	searchRank  = `ts_rank(search_vector, websearch_to_tsquery('english', @q)) + word_similarity(@q, title) AS rank`
+
+	// ts_headline marks matches with two private-use characters, which are first removed from the text itself.
+	// highlightHTML then escapes the text and turns them into <mark> tags, so that no markup stored with a
+	// movie reaches clients.
+	highlightStart   = "\uE000"
+	highlightStop    = "\uE001"
+	highlightOptions = `StartSel="` + highlightStart + `", StopSel="` + highlightStop + `"`
+	highlightStrip   = `'` + highlightStart + highlightStop + `', ''`
+	searchHighlight  = `ts_headline('english', translate(title, ` + highlightStrip + `), websearch_to_tsquery('english', @q), '` + highlightOptions + `, HighlightAll=true') AS highlight`
+	searchSnippet    = `ts_headline('english', translate(coalesce(description, ''), ` + highlightStrip + `), websearch_to_tsquery('english', @q), '` + highlightOptions + `, MaxWords=30, MinWords=10, MaxFragments=2') AS snippet`
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+type movieSearchRepository struct {
+	DB *gorm.DB
+}
+
+type MovieSearchRepository interface {
+	Search(ctx context.Context, query string, limit, offset int) ([]domain.MovieSearchHit, int64, error)
+	Suggest(ctx context.Context, prefix string, limit int) ([]domain.Movie, error)
+}
+
+func NewMovieSearchRepository(db *gorm.DB) MovieSearchRepository {
+	return &movieSearchRepository{DB: db}
+}
+
+func (r *movieSearchRepository) Search(ctx context.Context, query string, limit, offset int) ([]domain.MovieSearchHit, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	q := sql.Named("q", query)
+	base := db.WithContext(ctxWithTimeout).Model(&domain.Movie{}).Where(searchMatch, q).Session(&gorm.Session{})
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var hits []domain.MovieSearchHit
+	err := base.
+		Select("movies.*, "+searchRank+", "+searchHighlight+", "+searchSnippet, q).
+		Order("rank DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&hits).Error
+	for i := range hits {
+		hits[i].Highlight = highlightHTML(hits[i].Highlight)
+		hits[i].Snippet = highlightHTML(hits[i].Snippet)
+	}
+	return hits, total, err
+}
+
+func (r *movieSearchRepository) Suggest(ctx context.Context, prefix string, limit int) ([]domain.Movie, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	like := escapeLike(prefix) + "%"
+
+	// Exact prefix matches come first, fuzzy (typo) matches after them, popular titles break ties.
+	var movies []domain.Movie
+	err := db.WithContext(ctxWithTimeout).
+		Select("id", "title").
+		Where("title ILIKE ? OR ? <% title", like, prefix).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "title ILIKE ? DESC, word_similarity(?, title) DESC, rating_count DESC, id",
+			Vars:               []interface{}{like, prefix},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&movies).Error
+	return movies, err
+}
+
+// highlightHTML escapes a ts_headline result for HTML and wraps its matches in <mark>.
+func highlightHTML(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,57 @@
+//go:build unit_test
+
+package repository
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type MovieSearchTest struct {
+	suite.Suite
+}
+
+func Test_RunMovieSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(MovieSearchTest))
+}
+
+func (s *MovieSearchTest) TestHighlightHTML() {
+	t := s.T()
+
+	tests := []struct {
+		name     string
+		headline string
+		want     string
+	}{
+		{name: "no match", headline: "Heat", want: "Heat"},
+		{name: "match", headline: highlightStart + "Heat" + highlightStop + " (1995)", want: "<mark>Heat</mark> (1995)"},
+		{
+			name:     "markup in the movie data",
+			headline: `<script>alert("x")</script> ` + highlightStart + "Heat" + highlightStop,
+			want:     `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>Heat</mark>`,
+		},
+		{name: "entities in the movie data", headline: "Tom &amp; " + highlightStart + "Jerry" + highlightStop, want: "Tom &amp;amp; <mark>Jerry</mark>"},
+		{name: "quote", headline: "Ocean's " + highlightStart + "Eleven" + highlightStop, want: "Ocean&#39;s <mark>Eleven</mark>"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, highlightHTML(tt.headline), tt.name)
+	}
+}
+
+func (s *MovieSearchTest) TestEscapeLike() {
+	t := s.T()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "nolan", want: "nolan"},
+		{in: "100%", want: `100\%`},
+		{in: "a_b", want: `a\_b`},
+		{in: `back\slash`, want: `back\\slash`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, escapeLike(tt.in), tt.in)
+	}
+}
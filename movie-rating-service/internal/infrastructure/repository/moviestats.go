@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type movieStatsRepository struct {
+	DB *gorm.DB
+}
+
+// MovieStatsRepository keeps the histogram and monthly series of a movie's ratings in step with
+// the ratings table. Callers pass the transaction used for the rating write so both commit together.
+type MovieStatsRepository interface {
+	AddScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error
+	ReplaceScore(ctx context.Context, movieID uint, oldScore, newScore float64, ratedAt time.Time) error
+	RemoveScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error
+	GetBuckets(ctx context.Context, movieID uint) ([]domain.MovieRatingBucket, error)
+	GetPeriods(ctx context.Context, movieID uint) ([]domain.MovieRatingPeriod, error)
+}
+
+func NewMovieStatsRepository(db *gorm.DB) MovieStatsRepository {
+	return &movieStatsRepository{DB: db}
+}
+
+func (r *movieStatsRepository) AddScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	db = db.WithContext(ctxWithTimeout)
+
+	if err := incrementBucket(db, movieID, domain.RatingBucket(score)); err != nil {
+		return err
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "movie_id"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":       gorm.Expr("movie_rating_periods.count + 1"),
+			"sum":         gorm.Expr("movie_rating_periods.sum + ?", score),
+			"sum_squares": gorm.Expr("movie_rating_periods.sum_squares + ?", score*score),
+		}),
+	}).Create(&domain.MovieRatingPeriod{
+		MovieID:    movieID,
+		Period:     domain.RatingPeriod(ratedAt),
+		Count:      1,
+		Sum:        score,
+		SumSquares: score * score,
+	}).Error
+}
+
+func (r *movieStatsRepository) ReplaceScore(ctx context.Context, movieID uint, oldScore, newScore float64, ratedAt time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	db = db.WithContext(ctxWithTimeout)
+
+	if oldBucket, newBucket := domain.RatingBucket(oldScore), domain.RatingBucket(newScore); oldBucket != newBucket {
+		if err := decrementBucket(db, movieID, oldBucket); err != nil {
+			return err
+		}
+		if err := incrementBucket(db, movieID, newBucket); err != nil {
+			return err
+		}
+	}
+
+	return db.Model(&domain.MovieRatingPeriod{}).
+		Where("movie_id = ? AND period = ?", movieID, domain.RatingPeriod(ratedAt)).
+		Updates(map[string]interface{}{
+			"sum":         gorm.Expr("sum - ? + ?", oldScore, newScore),
+			"sum_squares": gorm.Expr("sum_squares - ? + ?", oldScore*oldScore, newScore*newScore),
+		}).Error
+}
+
+func (r *movieStatsRepository) RemoveScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	db = db.WithContext(ctxWithTimeout)
+
+	if err := decrementBucket(db, movieID, domain.RatingBucket(score)); err != nil {
+		return err
+	}
+
+	return db.Model(&domain.MovieRatingPeriod{}).
+		Where("movie_id = ? AND period = ?", movieID, domain.RatingPeriod(ratedAt)).
+		Updates(map[string]interface{}{
+			"count":       gorm.Expr("GREATEST(count - 1, 0)"),
+			"sum":         gorm.Expr("sum - ?", score),
+			"sum_squares": gorm.Expr("sum_squares - ?", score*score),
+		}).Error
+}
+
+func (r *movieStatsRepository) GetBuckets(ctx context.Context, movieID uint) ([]domain.MovieRatingBucket, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	var buckets []domain.MovieRatingBucket
+	err := db.WithContext(ctxWithTimeout).Where("movie_id = ? AND count > 0", movieID).Order("bucket").Find(&buckets).Error
+	return buckets, err
+}
+
+func (r *movieStatsRepository) GetPeriods(ctx context.Context, movieID uint) ([]domain.MovieRatingPeriod, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	var periods []domain.MovieRatingPeriod
+	err := db.WithContext(ctxWithTimeout).Where("movie_id = ? AND count > 0", movieID).Order("period").Find(&periods).Error
+	return periods, err
+}
+
+func incrementBucket(db *gorm.DB, movieID uint, bucket int) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "movie_id"}, {Name: "bucket"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("movie_rating_buckets.count + 1")}),
+	}).Create(&domain.MovieRatingBucket{MovieID: movieID, Bucket: bucket, Count: 1}).Error
+}
+
+func decrementBucket(db *gorm.DB, movieID uint, bucket int) error {
+	return db.Model(&domain.MovieRatingBucket{}).
+		Where("movie_id = ? AND bucket = ?", movieID, bucket).
+		Update("count", gorm.Expr("GREATEST(count - 1, 0)")).Error
+}
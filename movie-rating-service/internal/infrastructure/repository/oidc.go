@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type oidcRepository struct {
+	DB *gorm.DB
+}
+
+type OIDCRepository interface {
+	CreateLogin(ctx context.Context, login domain.OIDCLogin) error
+	// ConsumeLogin deletes the pending login and returns it, so that it can only be completed once.
+	ConsumeLogin(ctx context.Context, state string) (*domain.OIDCLogin, error)
+	DeleteExpiredLogins(ctx context.Context, before time.Time) error
+	GetIdentity(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity domain.UserIdentity) error
+	TouchIdentity(ctx context.Context, identityID uint, at time.Time) error
+}
+
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{DB: db}
+}
+
+func (r *oidcRepository) CreateLogin(ctx context.Context, login domain.OIDCLogin) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	login.ExpiresAt = login.ExpiresAt.UTC()
+	return db.WithContext(ctxWithTimeout).Create(&login).Error
+}
+
+func (r *oidcRepository) ConsumeLogin(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var logins []domain.OIDCLogin
+	result := db.WithContext(ctxWithTimeout).
+		Clauses(clause.Returning{}).
+		Where("state = ?", state).
+		Delete(&logins)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(logins) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &logins[0], nil
+}
+
+func (r *oidcRepository) DeleteExpiredLogins(ctx context.Context, before time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Where("expires_at < ?", before.UTC()).Delete(&domain.OIDCLogin{}).Error
+}
+
+func (r *oidcRepository) GetIdentity(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	identity := domain.UserIdentity{}
+	return &identity, db.WithContext(ctxWithTimeout).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+}
+
+func (r *oidcRepository) CreateIdentity(ctx context.Context, identity domain.UserIdentity) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Create(&identity).Error
+}
+
+func (r *oidcRepository) TouchIdentity(ctx context.Context, identityID uint, at time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Model(&domain.UserIdentity{}).Where("id = ?", identityID).Update("last_login_at", at.UTC()).Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"movie-rating-service/internal/domain"
 	"time"
 )
@@ -12,22 +13,41 @@ type ratingRepository struct {
 }
 
 type RatingRepository interface {
-	Create(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) (*domain.Rating, error)
-	GetByUserID(ctx context.Context, userID uint, tx ...*gorm.DB) ([]domain.Rating, error)
-	GetByUserIDAndMovieID(ctx context.Context, userID, movieID uint, tx ...*gorm.DB) (*domain.Rating, error)
-	Update(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error
-	Delete(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error
+	Create(ctx context.Context, rating domain.Rating) (*domain.Rating, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.Rating, error)
+	GetByUserIDAndMovieID(ctx context.Context, userID, movieID uint) (*domain.Rating, error)
+	GetByID(ctx context.Context, id uint) (*domain.Rating, error)
+	// LockByID and LockByUserIDAndMovieID read a rating and lock its row until the end of the
+	// transaction, so that the score a change starts from cannot be changed underneath it.
+	LockByID(ctx context.Context, id uint) (*domain.Rating, error)
+	LockByUserIDAndMovieID(ctx context.Context, userID, movieID uint) (*domain.Rating, error)
+	ListByMovieID(ctx context.Context, movieID uint, sort string, limit, offset int) ([]domain.Rating, int64, error)
+	CreateHelpfulVote(ctx context.Context, vote domain.RatingHelpfulVote) error
+	IncrementHelpfulCount(ctx context.Context, ratingID uint) error
+	Update(ctx context.Context, rating domain.Rating) error
+	// Delete returns the number of ratings deleted, 0 if it was deleted already.
+	Delete(ctx context.Context, id uint) (int64, error)
+	GetHelpfulVotesByUserID(ctx context.Context, userID uint) ([]domain.RatingHelpfulVote, error)
+	// EraseByUserID permanently deletes the user's ratings, including deleted ones, the votes on them
+	// and the votes the user cast, whose ratings lose one helpful vote each. It returns the number of
+	// ratings deleted. Movie aggregates are left to the caller.
+	EraseByUserID(ctx context.Context, userID uint) (int64, error)
+}
+
+// ratingSortOrders maps the public sort names of a movie's rating listing to ORDER BY clauses.
+var ratingSortOrders = map[string]string{
+	"newest":       "created_at DESC",
+	"highest":      "score DESC",
+	"lowest":       "score ASC",
+	"most_helpful": "helpful_count DESC",
 }
 
 func NewRatingRepository(db *gorm.DB) RatingRepository {
 	return &ratingRepository{DB: db}
 }
 
-func (r *ratingRepository) Create(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) (*domain.Rating, error) {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *ratingRepository) Create(ctx context.Context, rating domain.Rating) (*domain.Rating, error) {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -37,11 +57,8 @@ func (r *ratingRepository) Create(ctx context.Context, rating domain.Rating, tx
 	return &rating, nil
 }
 
-func (r *ratingRepository) GetByUserID(ctx context.Context, userID uint, tx ...*gorm.DB) ([]domain.Rating, error) {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *ratingRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Rating, error) {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -52,11 +69,8 @@ func (r *ratingRepository) GetByUserID(ctx context.Context, userID uint, tx ...*
 	return ratings, nil
 }
 
-func (r *ratingRepository) GetByUserIDAndMovieID(ctx context.Context, userID, movieID uint, tx ...*gorm.DB) (*domain.Rating, error) {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *ratingRepository) GetByUserIDAndMovieID(ctx context.Context, userID, movieID uint) (*domain.Rating, error) {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
@@ -67,12 +81,90 @@ func (r *ratingRepository) GetByUserIDAndMovieID(ctx context.Context, userID, mo
 		First(&ratings).Error
 }
 
-func (r *ratingRepository) Update(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
+func (r *ratingRepository) GetByID(ctx context.Context, id uint) (*domain.Rating, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	rating := domain.Rating{}
+	return &rating, db.WithContext(ctxWithTimeout).Where("id = ?", id).First(&rating).Error
+}
+
+func (r *ratingRepository) LockByID(ctx context.Context, id uint) (*domain.Rating, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	rating := domain.Rating{}
+	return &rating, db.WithContext(ctxWithTimeout).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&rating).Error
+}
+
+func (r *ratingRepository) LockByUserIDAndMovieID(ctx context.Context, userID, movieID uint) (*domain.Rating, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	rating := domain.Rating{}
+	return &rating, db.WithContext(ctxWithTimeout).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		Where("movie_id = ?", movieID).
+		First(&rating).Error
+}
+
+func (r *ratingRepository) ListByMovieID(ctx context.Context, movieID uint, sort string, limit, offset int) ([]domain.Rating, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	query := db.WithContext(ctxWithTimeout).Model(&domain.Rating{}).Where("movie_id = ?", movieID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, ok := ratingSortOrders[sort]
+	if !ok {
+		order = ratingSortOrders["newest"]
 	}
 
+	var ratings []domain.Rating
+	err := query.
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped().Select("id", "username")
+		}).
+		Order(order + ", id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&ratings).Error
+	return ratings, total, err
+}
+
+func (r *ratingRepository) CreateHelpfulVote(ctx context.Context, vote domain.RatingHelpfulVote) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Create(&vote).Error
+}
+
+func (r *ratingRepository) IncrementHelpfulCount(ctx context.Context, ratingID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Model(&domain.Rating{}).Where("id = ?", ratingID).
+		Update("helpful_count", gorm.Expr("helpful_count + 1")).Error
+}
+
+func (r *ratingRepository) Update(ctx context.Context, rating domain.Rating) error {
+	db := conn(ctx, r.DB)
+
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
@@ -87,21 +179,46 @@ func (r *ratingRepository) Update(ctx context.Context, rating domain.Rating, tx
 	return nil
 }
 
-func (r *ratingRepository) Delete(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error {
-	db := r.DB
-	if len(tx) > 0 {
-		db = tx[0]
-	}
+func (r *ratingRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	db := conn(ctx, r.DB)
 
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
-	if err := db.WithContext(ctxWithTimeout).
-		Where("user_id = ?", rating.UserID).
-		Where("movie_id = ?", rating.MovieID).
-		Delete(&domain.Rating{}).Error; err != nil {
-		return err
+	result := db.WithContext(ctxWithTimeout).Where("id = ?", id).Delete(&domain.Rating{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *ratingRepository) GetHelpfulVotesByUserID(ctx context.Context, userID uint) ([]domain.RatingHelpfulVote, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	var votes []domain.RatingHelpfulVote
+	if err := db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Order("id").Find(&votes).Error; err != nil {
+		return nil, err
 	}
+	return votes, nil
+}
 
-	return nil
+func (r *ratingRepository) EraseByUserID(ctx context.Context, userID uint) (int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+	db = db.WithContext(ctxWithTimeout)
+
+	err := db.Exec(`UPDATE ratings SET helpful_count = GREATEST(helpful_count - 1, 0)
+		WHERE id IN (SELECT rating_id FROM rating_helpful_votes WHERE user_id = ? AND deleted_at IS NULL)`, userID).Error
+	if err != nil {
+		return 0, err
+	}
+	err = db.Exec(`DELETE FROM rating_helpful_votes
+		WHERE user_id = ? OR rating_id IN (SELECT id FROM ratings WHERE user_id = ?)`, userID, userID).Error
+	if err != nil {
+		return 0, err
+	}
+
+	result := db.Exec("DELETE FROM ratings WHERE user_id = ?", userID)
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type roleRepository struct {
+	DB *gorm.DB
+}
+
+type RoleRepository interface {
+	GetByName(ctx context.Context, name string) (*domain.Role, error)
+	GetByNames(ctx context.Context, names []string) ([]domain.Role, error)
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{DB: db}
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	role := domain.Role{}
+	return &role, db.WithContext(ctxWithTimeout).Where("name = ?", name).First(&role).Error
+}
+
+func (r *roleRepository) GetByNames(ctx context.Context, names []string) ([]domain.Role, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var roles []domain.Role
+	err := db.WithContext(ctxWithTimeout).Preload("Permissions").Where("name IN ?", names).Order("id").Find(&roles).Error
+	return roles, err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+// signingKeyLockKey serialises key rotation between replicas.
+const signingKeyLockKey = 727_001_013
+
+type signingKeyRepository struct {
+	DB *gorm.DB
+}
+
+type SigningKeyRepository interface {
+	// Lock blocks until no other transaction rotates keys. It must be called within a transaction
+	// and is released when that transaction ends.
+	Lock(ctx context.Context) error
+	Create(ctx context.Context, key domain.SigningKey) error
+	ListUnexpired(ctx context.Context, at time.Time) ([]domain.SigningKey, error)
+	Retire(ctx context.Context, kid string, at time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{DB: db}
+}
+
+func (r *signingKeyRepository) Lock(ctx context.Context) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockKey).Error
+}
+
+func (r *signingKeyRepository) Create(ctx context.Context, key domain.SigningKey) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Create(&key).Error
+}
+
+func (r *signingKeyRepository) ListUnexpired(ctx context.Context, at time.Time) ([]domain.SigningKey, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var keys []domain.SigningKey
+	err := db.WithContext(ctxWithTimeout).Where("expires_at > ?", at).Order("activates_at").Find(&keys).Error
+	return keys, err
+}
+
+// Retire stops kid from signing at the given time without shortening how long it is published.
+func (r *signingKeyRepository) Retire(ctx context.Context, kid string, at time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.SigningKey{}).
+		Where("kid = ? AND retires_at > ?", kid, at).
+		Update("retires_at", at).Error
+}
+
+func (r *signingKeyRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Where("expires_at <= ?", before).Delete(&domain.SigningKey{}).Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type tokenRepository struct {
+	DB *gorm.DB
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uint) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint) error
+	RevokeAccessToken(ctx context.Context, token domain.RevokedToken) error
+	// IsAccessTokenRevoked reports whether the token was denylisted, issued in or before the second
+	// its user's tokens were revoked as a whole, or belongs to a user that was deleted.
+	IsAccessTokenRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) error
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{DB: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Create(&token).Error
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	token := domain.RefreshToken{}
+	return &token, db.WithContext(ctxWithTimeout).Where("token_hash = ?", hash).First(&token).Error
+}
+
+// RevokeRefreshToken reports false when the token had already been revoked, e.g. by a concurrent
+// refresh with the same token.
+func (r *tokenRepository) RevokeRefreshToken(ctx context.Context, id uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now().UTC())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *tokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now().UTC()).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(ctx context.Context, token domain.RevokedToken) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var revoked bool
+	err := db.WithContext(ctxWithTimeout).Raw(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)
+		OR NOT EXISTS (SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL
+			AND (tokens_valid_after IS NULL OR tokens_valid_after < ?))`, jti, userID, issuedAt.UTC().Truncate(time.Second)).Scan(&revoked).Error
+	return revoked, err
+}
+
+func (r *tokenRepository) DeleteExpiredRevokedTokens(ctx context.Context, before time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Where("expires_at < ?", before).Delete(&domain.RevokedToken{}).Error
+}
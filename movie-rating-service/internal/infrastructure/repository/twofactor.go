@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"time"
+)
+
+type twoFactorRepository struct {
+	DB *gorm.DB
+}
+
+type TwoFactorRepository interface {
+	// SetPendingSecret stores a new secret that is not used until EnableTOTP. It reports false when
+	// two-factor authentication is already enabled.
+	SetPendingSecret(ctx context.Context, userID uint, secret []byte) (bool, error)
+	EnableTOTP(ctx context.Context, userID uint, at time.Time, step int64) error
+	DisableTOTP(ctx context.Context, userID uint) error
+	// UseTOTPStep records the time step of an accepted code. It reports false when a code of the
+	// same or a later step was accepted before, i.e. the code is replayed.
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// ReplaceRecoveryCodes drops every recovery code of the user and stores the given hashes.
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error
+	// UseRecoveryCode reports false when the user has no unused recovery code with the hash.
+	UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) (bool, error)
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{DB: db}
+}
+
+func (r *twoFactorRepository) SetPendingSecret(ctx context.Context, userID uint, secret []byte) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", userID).
+		Updates(map[string]interface{}{"totp_secret": secret, "totp_last_step": nil})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, userID uint, at time.Time, step int64) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_enabled_at": at.UTC(), "totp_last_step": step}).Error
+}
+
+func (r *twoFactorRepository) DisableTOTP(ctx context.Context, userID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	err := db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"totp_secret": nil, "totp_enabled_at": nil, "totp_last_step": nil}).Error
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+}
+
+func (r *twoFactorRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if err := db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]domain.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		codes = append(codes, domain.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return db.WithContext(ctxWithTimeout).Create(&codes).Error
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at.UTC())
+	return result.RowsAffected == 1, result.Error
+}
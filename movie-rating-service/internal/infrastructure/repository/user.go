@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"movie-rating-service/internal/domain"
 )
 
@@ -16,6 +18,43 @@ type UserRepository interface {
 	Create(ctx context.Context, user domain.User) (*domain.User, error)
 	GetByID(ctx context.Context, userID uint) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	List(ctx context.Context, filter UserFilter) ([]domain.User, int64, error)
+	AddRole(ctx context.Context, userID, roleID uint) error
+	RemoveRole(ctx context.Context, userID, roleID uint) error
+	UpdateStatus(ctx context.Context, userID uint, status, reason string) error
+	SetPasswordResetRequired(ctx context.Context, userID uint, required bool) error
+	// RevokeTokens invalidates every access token of the user issued in or before the second of at.
+	// Access tokens carry iat in whole seconds, so tokens issued earlier in that second are covered.
+	RevokeTokens(ctx context.Context, userID uint, at time.Time) error
+	// GetTokensRevokedAt returns the second up to which the user's access tokens are revoked, or the
+	// zero time if they never were.
+	GetTokensRevokedAt(ctx context.Context, userID uint) (time.Time, error)
+	// UpdateProfile applies the changed columns. A changed email is no longer verified.
+	UpdateProfile(ctx context.Context, userID uint, changes map[string]interface{}) error
+	// ListByEmail returns the accounts using the email, matched case-insensitively.
+	ListByEmail(ctx context.Context, email string) ([]domain.User, error)
+	MarkEmailVerified(ctx context.Context, userID uint, at time.Time) error
+	// UpdatePassword stores a new password hash and clears a pending forced password reset.
+	UpdatePassword(ctx context.Context, userID uint, hash string) error
+	// RehashPassword replaces a password hash with a new hash of the same password, unless the
+	// password was changed in the meantime.
+	RehashPassword(ctx context.Context, userID uint, oldHash, newHash string) error
+	// Anonymize erases the user's personal data and credentials, drops their roles, refresh tokens, API
+	// keys, recovery codes and links to identity providers and deletes the account. Rows referencing the
+	// user, such as ratings, are kept.
+	Anonymize(ctx context.Context, userID uint) error
+	// Erase permanently deletes the user row, including an already deleted account. Roles and
+	// refresh tokens go with it; ratings must be erased first.
+	Erase(ctx context.Context, userID uint) error
+}
+
+type UserFilter struct {
+	// Query matches username, name, surname and email.
+	Query  string
+	Role   string
+	Status string
+	Limit  int
+	Offset int
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
@@ -25,7 +64,8 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 func (r *userRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
-	if err := r.DB.WithContext(ctxWithTimeout).Create(&user).Error; err != nil {
+	// Roles are referenced, not created.
+	if err := conn(ctx, r.DB).WithContext(ctxWithTimeout).Omit("Roles.*").Create(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -35,12 +75,246 @@ func (r *userRepository) GetByID(ctx context.Context, userID uint) (*domain.User
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 	user := domain.User{}
-	return &user, r.DB.WithContext(ctxWithTimeout).Where("id=?", userID).First(&user).Error
+	return &user, conn(ctx, r.DB).WithContext(ctxWithTimeout).Preload("Roles.Permissions").Where("id=?", userID).First(&user).Error
 }
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 	user := domain.User{}
-	return &user, r.DB.WithContext(ctxWithTimeout).Where("username = ?", username).First(&user).Error
+	return &user, conn(ctx, r.DB).WithContext(ctxWithTimeout).Preload("Roles.Permissions").Where("username = ?", username).First(&user).Error
+}
+
+func (r *userRepository) List(ctx context.Context, filter UserFilter) ([]domain.User, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	query := db.WithContext(ctxWithTimeout).Model(&domain.User{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where("username ILIKE ? OR name ILIKE ? OR surname ILIKE ? OR email ILIKE ?", pattern, pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("id IN (SELECT ur.user_id FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE r.name = ?)", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.
+		Preload("Roles.Permissions").
+		Order("id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) AddRole(ctx context.Context, userID, roleID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Table("user_roles").
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(map[string]interface{}{"user_id": userID, "role_id": roleID}).Error
+}
+
+func (r *userRepository) RemoveRole(ctx context.Context, userID, roleID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).Exec("DELETE FROM user_roles WHERE user_id = ? AND role_id = ?", userID, roleID).Error
+}
+
+func (r *userRepository) UpdateStatus(ctx context.Context, userID uint, status, reason string) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"status": status, "status_reason": reason}).Error
+}
+
+func (r *userRepository) SetPasswordResetRequired(ctx context.Context, userID uint, required bool) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("password_reset_required", required).Error
+}
+
+func (r *userRepository) RevokeTokens(ctx context.Context, userID uint, at time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("tokens_valid_after", at.UTC().Truncate(time.Second)).Error
+}
+
+func (r *userRepository) GetTokensRevokedAt(ctx context.Context, userID uint) (time.Time, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var revokedAt *time.Time
+	err := db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Pluck("tokens_valid_after", &revokedAt).Error
+	if err != nil || revokedAt == nil {
+		return time.Time{}, err
+	}
+	return *revokedAt, nil
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, userID uint, changes map[string]interface{}) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	if email, ok := changes["email"]; ok {
+		changes["email_verified_at"] = gorm.Expr("CASE WHEN email = ? THEN email_verified_at END", email)
+	}
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(changes).Error
+}
+
+func (r *userRepository) ListByEmail(ctx context.Context, email string) ([]domain.User, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var users []domain.User
+	err := db.WithContext(ctxWithTimeout).Where("lower(email) = lower(?)", email).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uint, at time.Time) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Update("email_verified_at", at.UTC()).Error
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID uint, hash string) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{"password": hash, "password_reset_required": false}).Error
+}
+
+func (r *userRepository) RehashPassword(ctx context.Context, userID uint, oldHash, newHash string) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ? AND password = ?", userID, oldHash).
+		Update("password", newHash).Error
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, userID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	// The username stays unique so that it can still be shown next to the user's ratings.
+	err := db.WithContext(ctxWithTimeout).
+		Model(&domain.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"username":                fmt.Sprintf("deleted-user-%d", userID),
+			"password":                "",
+			"name":                    "",
+			"surname":                 "",
+			"email":                   "",
+			"phone":                   "",
+			"address":                 "",
+			"status_reason":           "",
+			"password_reset_required": false,
+			"email_verified_at":       nil,
+			"tokens_valid_after":      nil,
+			"totp_secret":             nil,
+			"totp_enabled_at":         nil,
+			"totp_last_step":          nil,
+		}).Error
+	if err != nil {
+		return err
+	}
+	if err = db.WithContext(ctxWithTimeout).Exec("DELETE FROM user_roles WHERE user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	// Tokens of a deleted account are refused anyway; the rows only go so that none of them is left.
+	if err = db.WithContext(ctxWithTimeout).Exec("DELETE FROM refresh_tokens WHERE user_id = ?", userID).Error; err != nil {
+		return err
+	}
+	if err = db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if err = db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Delete(&domain.APIKey{}).Error; err != nil {
+		return err
+	}
+	if err = db.WithContext(ctxWithTimeout).Where("user_id = ?", userID).Delete(&domain.UserIdentity{}).Error; err != nil {
+		return err
+	}
+	return db.WithContext(ctxWithTimeout).Delete(&domain.User{}, userID).Error
+}
+
+func (r *userRepository) Erase(ctx context.Context, userID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Unscoped().Delete(&domain.User{}, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
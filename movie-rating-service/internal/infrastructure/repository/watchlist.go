@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"movie-rating-service/internal/domain"
+	"strings"
+	"time"
+)
+
+type watchlistRepository struct {
+	DB *gorm.DB
+}
+
+type WatchlistRepository interface {
+	// Add puts the movie at the end of the user's watchlist. It reports false if it was already there.
+	Add(ctx context.Context, userID, movieID uint) (bool, error)
+	// Remove reports false if the movie was not on the user's watchlist.
+	Remove(ctx context.Context, userID, movieID uint) (bool, error)
+	// RemoveAll empties the user's watchlist and returns the movies that were on it. The movies'
+	// watchlist counts are left to the caller.
+	RemoveAll(ctx context.Context, userID uint) ([]uint, error)
+	// Lock serializes changes to the user's watchlist until the end of the transaction, by locking
+	// the user's row.
+	Lock(ctx context.Context, userID uint) error
+	Count(ctx context.Context, userID uint) (int64, error)
+	// ListMovieIDs returns the movies on the user's watchlist in order, and locks the items until the
+	// end of the transaction.
+	ListMovieIDs(ctx context.Context, userID uint) ([]uint, error)
+	// Reorder gives each movie the position of its index in movieIDs.
+	Reorder(ctx context.Context, userID uint, movieIDs []uint) error
+	List(ctx context.Context, filter WatchlistFilter) ([]domain.WatchlistItem, int64, error)
+	GetByUserID(ctx context.Context, userID uint) ([]domain.WatchlistItem, error)
+}
+
+type WatchlistFilter struct {
+	UserID uint
+	Genre  string
+	// Released keeps only movies released (true) or not yet released (false) by ReleasedBy, a year.
+	// A movie without a year counts as not yet released.
+	Released   *bool
+	ReleasedBy int
+	Limit      int
+	Offset     int
+}
+
+func NewWatchlistRepository(db *gorm.DB) WatchlistRepository {
+	return &watchlistRepository{DB: db}
+}
+
+func (r *watchlistRepository) Add(ctx context.Context, userID, movieID uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).Exec(`INSERT INTO watchlist_items (created_at, user_id, movie_id, position)
+		SELECT now(), ?, ?, COALESCE(MAX(position) + 1, 0) FROM watchlist_items WHERE user_id = ?
+		ON CONFLICT (user_id, movie_id) DO NOTHING`, userID, movieID, userID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *watchlistRepository) Remove(ctx context.Context, userID, movieID uint) (bool, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	result := db.WithContext(ctxWithTimeout).
+		Where("user_id = ? AND movie_id = ?", userID, movieID).
+		Delete(&domain.WatchlistItem{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *watchlistRepository) RemoveAll(ctx context.Context, userID uint) ([]uint, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var movieIDs []uint
+	err := db.WithContext(ctxWithTimeout).
+		Raw("DELETE FROM watchlist_items WHERE user_id = ? RETURNING movie_id", userID).
+		Scan(&movieIDs).Error
+	return movieIDs, err
+}
+
+func (r *watchlistRepository) Lock(ctx context.Context, userID uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	// Unlike FOR UPDATE, this does not hold up inserts elsewhere that reference the user.
+	var id uint
+	result := db.WithContext(ctxWithTimeout).Raw("SELECT id FROM users WHERE id = ? FOR NO KEY UPDATE", userID).Scan(&id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *watchlistRepository) Count(ctx context.Context, userID uint) (int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var count int64
+	err := db.WithContext(ctxWithTimeout).Model(&domain.WatchlistItem{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *watchlistRepository) ListMovieIDs(ctx context.Context, userID uint) ([]uint, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var movieIDs []uint
+	err := db.WithContext(ctxWithTimeout).
+		Raw("SELECT movie_id FROM watchlist_items WHERE user_id = ? ORDER BY position, id FOR UPDATE", userID).
+		Scan(&movieIDs).Error
+	return movieIDs, err
+}
+
+func (r *watchlistRepository) Reorder(ctx context.Context, userID uint, movieIDs []uint) error {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	if len(movieIDs) == 0 {
+		return nil
+	}
+	var cases strings.Builder
+	args := make([]interface{}, 0, 2*len(movieIDs))
+	for position, movieID := range movieIDs {
+		cases.WriteString(" WHEN ? THEN ?")
+		args = append(args, movieID, position)
+	}
+	return db.WithContext(ctxWithTimeout).Model(&domain.WatchlistItem{}).
+		Where("user_id = ? AND movie_id IN ?", userID, movieIDs).
+		Update("position", gorm.Expr("CASE movie_id"+cases.String()+" END", args...)).Error
+}
+
+func (r *watchlistRepository) List(ctx context.Context, filter WatchlistFilter) ([]domain.WatchlistItem, int64, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	// The inner join leaves out movies deleted since they were added.
+	query := db.WithContext(ctxWithTimeout).Model(&domain.WatchlistItem{}).
+		Joins("JOIN movies ON movies.id = watchlist_items.movie_id AND movies.deleted_at IS NULL").
+		Where("watchlist_items.user_id = ?", filter.UserID)
+	if filter.Genre != "" {
+		query = query.Where("LOWER(movies.genre) = LOWER(?)", filter.Genre)
+	}
+	if filter.Released != nil {
+		if *filter.Released {
+			query = query.Where("movies.year BETWEEN 1 AND ?", filter.ReleasedBy)
+		} else {
+			query = query.Where("(movies.year > ? OR movies.year < 1 OR movies.year IS NULL)", filter.ReleasedBy)
+		}
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.WatchlistItem
+	err := query.
+		Preload("Movie").
+		Order("watchlist_items.position, watchlist_items.id").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&items).Error
+	return items, total, err
+}
+
+func (r *watchlistRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.WatchlistItem, error) {
+	db := conn(ctx, r.DB)
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	var items []domain.WatchlistItem
+	err := db.WithContext(ctxWithTimeout).
+		Preload("Movie", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Where("user_id = ?", userID).
+		Order("position, id").
+		Find(&items).Error
+	return items, err
+}
@@ -2,22 +2,31 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/ansrivas/fiberprometheus/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/monitor"
 	"github.com/gofiber/swagger"
+	"gorm.io/gorm"
 	"log/slog"
 	"movie-rating-service/config"
 	_ "movie-rating-service/docs"
 	"movie-rating-service/internal/application/controller"
+	"movie-rating-service/internal/application/middleware"
+	"movie-rating-service/internal/application/models/request"
 	"movie-rating-service/internal/application/service"
 	"movie-rating-service/internal/common"
 	"movie-rating-service/internal/infrastructure/db"
+	"movie-rating-service/internal/infrastructure/db/migration"
 	"movie-rating-service/internal/infrastructure/db/seeder"
+	"movie-rating-service/internal/infrastructure/mail"
+	"movie-rating-service/internal/infrastructure/oidc"
+	"movie-rating-service/internal/infrastructure/password"
 	"movie-rating-service/internal/infrastructure/repository"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -31,6 +40,9 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 // @BasePath  /
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
@@ -41,13 +53,29 @@ func main() {
 		panic(err)
 	}
 
+	if len(os.Args) > 1 && strings.EqualFold(os.Args[1], "migrate") {
+		if err = migrateCommand(os.Args[2:]); err != nil {
+			slog.Error("Migrate error", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	database, err := db.Connect()
 	if err != nil {
 		panic(err)
 	}
 
+	if config.Cfg.Migrate {
+		if err = migrate(context.Background(), database); err != nil {
+			panic(err)
+		}
+	}
+
+	passwordHasher := password.NewHasher(config.Cfg.Password)
+
 	if len(os.Args) > 1 && strings.EqualFold(os.Args[1], "seeder") {
-		s := seeder.NewSeeder(database)
+		s := seeder.NewSeeder(database, passwordHasher)
 		err = s.Seed()
 		if err != nil {
 			slog.Info("Seeder error", "error", err)
@@ -55,10 +83,36 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && strings.EqualFold(os.Args[1], "reconcile") {
+		flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+		dryRun := flags.Bool("dry-run", false, "only report discrepancies, do not fix them")
+		batchSize := flags.Int("batch-size", 500, "movies per batch")
+		_ = flags.Parse(os.Args[2:])
+
+		// Fixed movies are invalidated in the shared cache or announced to the running replicas.
+		movieCacheRepository, _, err := newMovieRepository(database)
+		if err != nil {
+			panic(err)
+		}
+		r := service.NewReconcileService(movieCacheRepository, config.Cfg.RatingConfig)
+		res, err := r.Reconcile(context.Background(), request.Reconcile{DryRun: *dryRun, BatchSize: *batchSize})
+		movieCacheRepository.Close()
+		if err != nil {
+			slog.Error("Reconcile error", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Reconcile finished", "scanned", res.Scanned, "mismatched", res.Mismatched, "fixed", res.Fixed, "dry_run", res.DryRun)
+		return
+	}
+
 	app := fiber.New(fiber.Config{
-		ReadTimeout:  time.Second * 10,
-		WriteTimeout: time.Second * 10,
-		ErrorHandler: common.ErrorHandler()})
+		ReadTimeout:             time.Second * 10,
+		WriteTimeout:            time.Second * 10,
+		ErrorHandler:            common.ErrorHandler(),
+		ProxyHeader:             config.Cfg.Proxy.Header,
+		EnableTrustedProxyCheck: config.Cfg.Proxy.Header != "",
+		TrustedProxies:          config.Cfg.Proxy.TrustedProxies,
+		EnableIPValidation:      true})
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusOK)
 	})
@@ -71,19 +125,94 @@ func main() {
 
 	app.Get("/monitor", monitor.New())
 
+	txManager := db.NewTxManager(database)
+
+	listenCtx, stopListening := context.WithCancel(context.Background())
+	defer stopListening()
+
+	movieCacheRepository, movieCacheNotifier, err := newMovieRepository(database)
+	if err != nil {
+		panic(err)
+	}
+
+	if movieCacheNotifier != nil {
+		go repository.ListenMovieCacheInvalidations(listenCtx, db.DSN(), movieCacheRepository)
+	}
+
 	userRepository := repository.NewUserRepository(database)
-	userService := service.NewUserService(userRepository)
-	controller.NewUserController(app, userService)
+	roleRepository := repository.NewRoleRepository(database)
+	tokenRepository := repository.NewTokenRepository(database)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(database)
+	watchlistRepository := repository.NewWatchlistRepository(database)
+	passwordPolicy, err := password.NewPolicy(config.Cfg.Password)
+	if err != nil {
+		panic(err)
+	}
+	userService, err := service.NewUserService(txManager, userRepository, roleRepository, tokenRepository, loginAttemptRepository, watchlistRepository, movieCacheRepository, passwordHasher, passwordPolicy, config.Cfg.Login)
+	if err != nil {
+		panic(err)
+	}
 
-	movieRepository := repository.NewMovieRepository(database)
-	movieCacheRepository := repository.NewCachedMovieRepository(movieRepository, time.Second*30)
+	signingKeyRepository := repository.NewSigningKeyRepository(database)
+	keyRing, err := service.NewKeyRing(txManager, signingKeyRepository, config.Cfg.JWTKeys.Algorithm, config.Cfg.JWTKeys.Rotation, config.Cfg.JWTAccessTTL, config.Cfg.JWTSecret)
+	if err != nil {
+		panic(err)
+	}
+	if err = keyRing.Refresh(context.Background()); err != nil {
+		panic(err)
+	}
+	go keyRing.Run(listenCtx, config.Cfg.JWTKeys.RefreshInterval)
+	controller.NewKeyController(app, keyRing)
+
+	tokenService := service.NewTokenService(txManager, tokenRepository, userRepository, keyRing, config.Cfg.JWTAccessTTL, config.Cfg.JWTRefreshTTL, config.Cfg.Account.RequireVerifiedEmail, config.Cfg.TwoFactor.RequiredRoles)
+	apiKeyRepository := repository.NewAPIKeyRepository(database)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, config.Cfg.APIKeys, config.Cfg.Account.RequireVerifiedEmail, config.Cfg.TwoFactor.RequiredRoles)
+	authMiddleware := middleware.NewAuthMiddleware(keyRing, tokenService, apiKeyService)
+	controller.NewAPIKeyController(app, apiKeyService, authMiddleware)
+
+	mailer := mail.NewMailer(config.Cfg.Mail)
+	accountService := service.NewAccountService(txManager, userRepository, tokenRepository, mailer, passwordHasher, passwordPolicy, config.Cfg.JWTSecret, config.Cfg.Account)
+	controller.NewAccountController(app, accountService, authMiddleware)
+
+	twoFactorRepository := repository.NewTwoFactorRepository(database)
+	twoFactorService, err := service.NewTwoFactorService(txManager, userRepository, twoFactorRepository, loginAttemptRepository, passwordHasher, config.Cfg.JWTSecret, config.Cfg.TwoFactor, config.Cfg.Login)
+	if err != nil {
+		panic(err)
+	}
+	controller.NewTwoFactorController(app, twoFactorService, tokenService, authMiddleware)
 
-	movieService := service.NewMovieService(movieCacheRepository)
-	controller.NewMovieController(app, movieService)
+	controller.NewUserController(app, userService, tokenService, accountService, twoFactorService, authMiddleware)
+
+	if config.Cfg.OIDC.Issuer != "" {
+		oidcRepository := repository.NewOIDCRepository(database)
+		oidcService := service.NewOIDCService(txManager, userRepository, roleRepository, oidcRepository, oidc.NewProvider(config.Cfg.OIDC, nil), config.Cfg.OIDC)
+		controller.NewOIDCController(app, oidcService, tokenService, twoFactorService)
+	}
+
+	movieSearchRepository := repository.NewMovieSearchRepository(database)
+	movieStatsRepository := repository.NewMovieStatsRepository(database)
+
+	movieService := service.NewMovieService(movieCacheRepository, movieSearchRepository, movieStatsRepository)
+	controller.NewMovieController(app, movieService, authMiddleware)
 
 	ratingRepository := repository.NewRatingRepository(database)
-	ratingService := service.NewRatingService(ratingRepository, movieRepository)
-	controller.NewRatingController(app, ratingService)
+	ratingService := service.NewRatingService(txManager, ratingRepository, movieCacheRepository, movieStatsRepository, watchlistRepository)
+	controller.NewRatingController(app, ratingService, authMiddleware)
+
+	watchlistService := service.NewWatchlistService(txManager, watchlistRepository, movieCacheRepository)
+	controller.NewWatchlistController(app, watchlistService, authMiddleware)
+
+	movieListRepository := repository.NewMovieListRepository(database)
+	movieListService := service.NewMovieListService(txManager, movieListRepository, movieCacheRepository, userRepository)
+	controller.NewMovieListController(app, movieListService, authMiddleware)
+
+	reconcileService := service.NewReconcileService(movieCacheRepository, config.Cfg.RatingConfig)
+	userAdminService := service.NewUserAdminService(txManager, userRepository, roleRepository, tokenRepository)
+	controller.NewAdminController(app, reconcileService, userAdminService, authMiddleware)
+
+	auditRepository := repository.NewAuditRepository(database)
+	privacyService := service.NewPrivacyService(txManager, userRepository, ratingRepository, movieCacheRepository, movieStatsRepository, auditRepository, watchlistRepository, movieListRepository, passwordHasher)
+	controller.NewPrivacyController(app, privacyService, authMiddleware)
 
 	go func() {
 		if err = app.Listen(fmt.Sprintf(":%d", config.Cfg.Port)); err != nil {
@@ -103,4 +232,110 @@ func main() {
 	} else {
 		slog.Info("Server gracefully stopped")
 	}
+	stopListening()
+	accountService.Wait()
+	movieCacheRepository.Close()
+}
+
+// migrate applies pending migrations. Only the replica that applied some refreshes the rating prior,
+// since a refresh may rewrite every movie; changing RATING_PRIOR_MEAN or RATING_MIN_VOTES alone takes
+// effect with the next reconcile run.
+func migrate(ctx context.Context, database *gorm.DB) error {
+	applied, err := db.Migrate(ctx, database)
+	if err != nil || len(applied) == 0 {
+		return err
+	}
+
+	// Replicas still running the previous release cache the weighted ratings the refresh rewrites.
+	movieCacheRepository, _, err := newMovieRepository(database)
+	if err != nil {
+		return err
+	}
+	defer movieCacheRepository.Close()
+
+	prior, err := movieCacheRepository.RefreshRatingPrior(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh rating prior: %w", err)
+	}
+	slog.Info("Rating prior refreshed", "prior_mean", prior)
+	return nil
+}
+
+// newMovieRepository builds the cached movie repository every writer of movies has to go through. A
+// per-replica memory cache learns about other replicas' writes through Postgres notifications, sent by
+// the returned notifier; a shared Redis cache is invalidated directly by the writer and needs none.
+func newMovieRepository(database *gorm.DB) (repository.CachedMovieRepository, db.Notifier, error) {
+	movieRepository := repository.NewMovieRepository(database, config.Cfg.RatingConfig)
+	movieCache := repository.NewMovieCache(config.Cfg.MovieCache, config.Cfg.RedisConfig)
+	var movieCacheNotifier db.Notifier
+	if config.Cfg.MovieCache.Backend == "memory" {
+		movieCacheNotifier = db.NewNotifier(database)
+	}
+	movieCacheRepository, err := repository.NewCachedMovieRepository(movieRepository, movieCache, movieCacheNotifier, config.Cfg.MovieCache)
+	if err != nil {
+		return nil, nil, err
+	}
+	return movieCacheRepository, movieCacheNotifier, nil
+}
+
+// migrateCommand handles `migrate up|down [n]|status|create <name>`.
+func migrateCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [n]|status|create <name>")
+	}
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return fmt.Errorf("usage: migrate create <name>")
+		}
+		files, err := migration.Create(migration.Dir, args[1])
+		if err != nil {
+			return err
+		}
+		slog.Info("Created migration", "files", files)
+		return nil
+	}
+
+	database, err := db.Connect()
+	if err != nil {
+		return err
+	}
+	m, err := migration.NewMigrator(database)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		// Same as the MIGRATE=true startup path, including the rating prior refresh after new migrations.
+		return migrate(ctx, database)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, r := range reverted {
+			slog.Info("Reverted migration", "version", r.Version, "name", r.Name)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				slog.Info("Migration", "version", s.Version, "name", s.Name, "applied_at", *s.AppliedAt)
+			} else {
+				slog.Info("Migration", "version", s.Version, "name", s.Name, "applied_at", "pending")
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
 }
@@ -0,0 +1,139 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// APIKeyService is an autogenerated mock type for the APIKeyService type
+type APIKeyService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: ctx, key
+func (_m *APIKeyService) Authenticate(ctx context.Context, key string) (*response.APIKeyIdentity, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *response.APIKeyIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*response.APIKeyIdentity, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *response.APIKeyIdentity); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.APIKeyIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *APIKeyService) Create(ctx context.Context, req request.CreateAPIKey) (*response.CreatedAPIKey, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *response.CreatedAPIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.CreateAPIKey) (*response.CreatedAPIKey, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.CreateAPIKey) *response.CreatedAPIKey); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.CreatedAPIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.CreateAPIKey) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, req
+func (_m *APIKeyService) List(ctx context.Context, req request.ListAPIKeys) ([]response.APIKey, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []response.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListAPIKeys) ([]response.APIKey, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListAPIKeys) []response.APIKey); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListAPIKeys) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: ctx, req
+func (_m *APIKeyService) Revoke(ctx context.Context, req request.RevokeAPIKey) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RevokeAPIKey) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyService {
+	mock := &APIKeyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
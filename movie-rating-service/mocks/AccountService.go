@@ -0,0 +1,120 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// AccountService is an autogenerated mock type for the AccountService type
+type AccountService struct {
+	mock.Mock
+}
+
+// ForgotPassword provides a mock function with given fields: ctx, req
+func (_m *AccountService) ForgotPassword(ctx context.Context, req request.ForgotPassword) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForgotPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ForgotPassword) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: ctx, req
+func (_m *AccountService) ResetPassword(ctx context.Context, req request.ResetPassword) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ResetPassword) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendVerification provides a mock function with given fields: ctx, req
+func (_m *AccountService) SendVerification(ctx context.Context, req request.SendVerification) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.SendVerification) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyEmail provides a mock function with given fields: ctx, req
+func (_m *AccountService) VerifyEmail(ctx context.Context, req request.VerifyEmail) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyEmail")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.VerifyEmail) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.VerifyEmail) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.VerifyEmail) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Wait provides a mock function with no fields
+func (_m *AccountService) Wait() {
+	_m.Called()
+}
+
+// NewAccountService creates a new instance of AccountService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAccountService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AccountService {
+	mock := &AccountService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
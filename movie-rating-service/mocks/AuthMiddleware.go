@@ -13,12 +13,12 @@ type AuthMiddleware struct {
 	mock.Mock
 }
 
-// AdminHandler provides a mock function with given fields: ctx
-func (_m *AuthMiddleware) AdminHandler(ctx *fiber.Ctx) error {
+// OptionalUserHandler provides a mock function with given fields: ctx
+func (_m *AuthMiddleware) OptionalUserHandler(ctx *fiber.Ctx) error {
 	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for AdminHandler")
+		panic("no return value specified for OptionalUserHandler")
 	}
 
 	var r0 error
@@ -31,6 +31,58 @@ func (_m *AuthMiddleware) AdminHandler(ctx *fiber.Ctx) error {
 	return r0
 }
 
+// RequireLoginPermission provides a mock function with given fields: permissions
+func (_m *AuthMiddleware) RequireLoginPermission(permissions ...string) func(*fiber.Ctx) error {
+	_va := make([]interface{}, len(permissions))
+	for _i := range permissions {
+		_va[_i] = permissions[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequireLoginPermission")
+	}
+
+	var r0 func(*fiber.Ctx) error
+	if rf, ok := ret.Get(0).(func(...string) func(*fiber.Ctx) error); ok {
+		r0 = rf(permissions...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func(*fiber.Ctx) error)
+		}
+	}
+
+	return r0
+}
+
+// RequirePermission provides a mock function with given fields: permissions
+func (_m *AuthMiddleware) RequirePermission(permissions ...string) func(*fiber.Ctx) error {
+	_va := make([]interface{}, len(permissions))
+	for _i := range permissions {
+		_va[_i] = permissions[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequirePermission")
+	}
+
+	var r0 func(*fiber.Ctx) error
+	if rf, ok := ret.Get(0).(func(...string) func(*fiber.Ctx) error); ok {
+		r0 = rf(permissions...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(func(*fiber.Ctx) error)
+		}
+	}
+
+	return r0
+}
+
 // UserHandler provides a mock function with given fields: ctx
 func (_m *AuthMiddleware) UserHandler(ctx *fiber.Ctx) error {
 	ret := _m.Called(ctx)
@@ -0,0 +1,383 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "movie-rating-service/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	repository "movie-rating-service/internal/infrastructure/repository"
+)
+
+// CachedMovieRepository is an autogenerated mock type for the CachedMovieRepository type
+type CachedMovieRepository struct {
+	mock.Mock
+}
+
+// AddRating provides a mock function with given fields: ctx, movieID, score
+func (_m *CachedMovieRepository) AddRating(ctx context.Context, movieID uint, score float64) error {
+	ret := _m.Called(ctx, movieID, score)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRating")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64) error); ok {
+		r0 = rf(ctx, movieID, score)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Close provides a mock function with no fields
+func (_m *CachedMovieRepository) Close() {
+	_m.Called()
+}
+
+// Create provides a mock function with given fields: ctx, movie
+func (_m *CachedMovieRepository) Create(ctx context.Context, movie domain.Movie) (*domain.Movie, error) {
+	ret := _m.Called(ctx, movie)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *domain.Movie
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) (*domain.Movie, error)); ok {
+		return rf(ctx, movie)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) *domain.Movie); ok {
+		r0 = rf(ctx, movie)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Movie)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Movie) error); ok {
+		r1 = rf(ctx, movie)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, movie
+func (_m *CachedMovieRepository) Delete(ctx context.Context, movie domain.Movie) error {
+	ret := _m.Called(ctx, movie)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) error); ok {
+		r0 = rf(ctx, movie)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRating provides a mock function with given fields: ctx, movieID, score
+func (_m *CachedMovieRepository) DeleteRating(ctx context.Context, movieID uint, score float64) error {
+	ret := _m.Called(ctx, movieID, score)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRating")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64) error); ok {
+		r0 = rf(ctx, movieID, score)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FixRatingAggregate provides a mock function with given fields: ctx, aggregate
+func (_m *CachedMovieRepository) FixRatingAggregate(ctx context.Context, aggregate domain.RatingAggregate) (bool, error) {
+	ret := _m.Called(ctx, aggregate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FixRatingAggregate")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RatingAggregate) (bool, error)); ok {
+		return rf(ctx, aggregate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RatingAggregate) bool); ok {
+		r0 = rf(ctx, aggregate)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.RatingAggregate) error); ok {
+		r1 = rf(ctx, aggregate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *CachedMovieRepository) Get(ctx context.Context, id uint) (*domain.Movie, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.Movie
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Movie, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Movie); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Movie)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Invalidate provides a mock function with given fields: ctx, id
+func (_m *CachedMovieRepository) Invalidate(ctx context.Context, id uint) {
+	_m.Called(ctx, id)
+}
+
+// InvalidateAll provides a mock function with given fields: ctx
+func (_m *CachedMovieRepository) InvalidateAll(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *CachedMovieRepository) List(ctx context.Context, filter repository.MovieFilter) ([]domain.Movie, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.Movie
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieFilter) ([]domain.Movie, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieFilter) []domain.Movie); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Movie)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.MovieFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.MovieFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListRatingAggregates provides a mock function with given fields: ctx, afterID, limit
+func (_m *CachedMovieRepository) ListRatingAggregates(ctx context.Context, afterID uint, limit int) ([]domain.RatingAggregate, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRatingAggregates")
+	}
+
+	var r0 []domain.RatingAggregate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) ([]domain.RatingAggregate, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) []domain.RatingAggregate); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RatingAggregate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) error); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RatingPrior provides a mock function with given fields: ctx
+func (_m *CachedMovieRepository) RatingPrior(ctx context.Context) (float64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RatingPrior")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (float64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) float64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecomputeRatingAggregate provides a mock function with given fields: ctx, movieID
+func (_m *CachedMovieRepository) RecomputeRatingAggregate(ctx context.Context, movieID uint) error {
+	ret := _m.Called(ctx, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecomputeRatingAggregate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, movieID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshRatingPrior provides a mock function with given fields: ctx
+func (_m *CachedMovieRepository) RefreshRatingPrior(ctx context.Context) (float64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshRatingPrior")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (float64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) float64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, movie
+func (_m *CachedMovieRepository) Update(ctx context.Context, movie domain.Movie) error {
+	ret := _m.Called(ctx, movie)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) error); ok {
+		r0 = rf(ctx, movie)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRating provides a mock function with given fields: ctx, movieID, oldScore, newScore
+func (_m *CachedMovieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore float64, newScore float64) error {
+	ret := _m.Called(ctx, movieID, oldScore, newScore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRating")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, float64) error); ok {
+		r0 = rf(ctx, movieID, oldScore, newScore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWatchlistCount provides a mock function with given fields: ctx, movieID, delta
+func (_m *CachedMovieRepository) UpdateWatchlistCount(ctx context.Context, movieID uint, delta int) error {
+	ret := _m.Called(ctx, movieID, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWatchlistCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) error); ok {
+		r0 = rf(ctx, movieID, delta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCachedMovieRepository creates a new instance of CachedMovieRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCachedMovieRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CachedMovieRepository {
+	mock := &CachedMovieRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,152 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+
+	time "time"
+)
+
+// KeyRing is an autogenerated mock type for the KeyRing type
+type KeyRing struct {
+	mock.Mock
+}
+
+// JWKS provides a mock function with no fields
+func (_m *KeyRing) JWKS() response.JWKS {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 response.JWKS
+	if rf, ok := ret.Get(0).(func() response.JWKS); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(response.JWKS)
+	}
+
+	return r0
+}
+
+// Keyfunc provides a mock function with given fields: token
+func (_m *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Keyfunc")
+	}
+
+	var r0 interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*jwt.Token) (interface{}, error)); ok {
+		return rf(token)
+	}
+	if rf, ok := ret.Get(0).(func(*jwt.Token) interface{}); ok {
+		r0 = rf(token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*jwt.Token) error); ok {
+		r1 = rf(token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Methods provides a mock function with no fields
+func (_m *KeyRing) Methods() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Methods")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// Refresh provides a mock function with given fields: ctx
+func (_m *KeyRing) Refresh(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Run provides a mock function with given fields: ctx, interval
+func (_m *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
+// Sign provides a mock function with given fields: claims
+func (_m *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	ret := _m.Called(claims)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sign")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(jwt.Claims) (string, error)); ok {
+		return rf(claims)
+	}
+	if rf, ok := ret.Get(0).(func(jwt.Claims) string); ok {
+		r0 = rf(claims)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(jwt.Claims) error); ok {
+		r1 = rf(claims)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewKeyRing creates a new instance of KeyRing. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewKeyRing(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *KeyRing {
+	mock := &KeyRing{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
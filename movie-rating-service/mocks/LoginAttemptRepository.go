@@ -0,0 +1,122 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "movie-rating-service/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LoginAttemptRepository is an autogenerated mock type for the LoginAttemptRepository type
+type LoginAttemptRepository struct {
+	mock.Mock
+}
+
+// Acquire provides a mock function with given fields: ctx, key, at, window, maxFailures, lockouts
+func (_m *LoginAttemptRepository) Acquire(ctx context.Context, key domain.LoginAttemptKey, at time.Time, window time.Duration, maxFailures int, lockouts []time.Duration) (int, *time.Time, error) {
+	ret := _m.Called(ctx, key, at, window, maxFailures, lockouts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Acquire")
+	}
+
+	var r0 int
+	var r1 *time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginAttemptKey, time.Time, time.Duration, int, []time.Duration) (int, *time.Time, error)); ok {
+		return rf(ctx, key, at, window, maxFailures, lockouts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginAttemptKey, time.Time, time.Duration, int, []time.Duration) int); ok {
+		r0 = rf(ctx, key, at, window, maxFailures, lockouts)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.LoginAttemptKey, time.Time, time.Duration, int, []time.Duration) *time.Time); ok {
+		r1 = rf(ctx, key, at, window, maxFailures, lockouts)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*time.Time)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, domain.LoginAttemptKey, time.Time, time.Duration, int, []time.Duration) error); ok {
+		r2 = rf(ctx, key, at, window, maxFailures, lockouts)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeleteExpired provides a mock function with given fields: ctx, before
+func (_m *LoginAttemptRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Release provides a mock function with given fields: ctx, key, unlock
+func (_m *LoginAttemptRepository) Release(ctx context.Context, key domain.LoginAttemptKey, unlock bool) error {
+	ret := _m.Called(ctx, key, unlock)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginAttemptKey, bool) error); ok {
+		r0 = rf(ctx, key, unlock)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reset provides a mock function with given fields: ctx, key
+func (_m *LoginAttemptRepository) Reset(ctx context.Context, key domain.LoginAttemptKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.LoginAttemptKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoginAttemptRepository creates a new instance of LoginAttemptRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginAttemptRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginAttemptRepository {
+	mock := &LoginAttemptRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
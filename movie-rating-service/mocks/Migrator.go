@@ -0,0 +1,119 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	migration "movie-rating-service/internal/infrastructure/db/migration"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Migrator is an autogenerated mock type for the Migrator type
+type Migrator struct {
+	mock.Mock
+}
+
+// Down provides a mock function with given fields: ctx, steps
+func (_m *Migrator) Down(ctx context.Context, steps int) ([]migration.Migration, error) {
+	ret := _m.Called(ctx, steps)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Down")
+	}
+
+	var r0 []migration.Migration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]migration.Migration, error)); ok {
+		return rf(ctx, steps)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []migration.Migration); ok {
+		r0 = rf(ctx, steps)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Migration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, steps)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Status provides a mock function with given fields: ctx
+func (_m *Migrator) Status(ctx context.Context) ([]migration.Status, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 []migration.Status
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]migration.Status, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []migration.Status); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Up provides a mock function with given fields: ctx
+func (_m *Migrator) Up(ctx context.Context) ([]migration.Migration, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Up")
+	}
+
+	var r0 []migration.Migration
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]migration.Migration, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []migration.Migration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Migration)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMigrator creates a new instance of Migrator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMigrator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Migrator {
+	mock := &Migrator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
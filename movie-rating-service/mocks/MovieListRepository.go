@@ -0,0 +1,513 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "movie-rating-service/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	repository "movie-rating-service/internal/infrastructure/repository"
+)
+
+// MovieListRepository is an autogenerated mock type for the MovieListRepository type
+type MovieListRepository struct {
+	mock.Mock
+}
+
+// AddEditor provides a mock function with given fields: ctx, listID, userID
+func (_m *MovieListRepository) AddEditor(ctx context.Context, listID uint, userID uint) (bool, error) {
+	ret := _m.Called(ctx, listID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddEditor")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (bool, error)); ok {
+		return rf(ctx, listID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) bool); ok {
+		r0 = rf(ctx, listID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, listID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddItem provides a mock function with given fields: ctx, item
+func (_m *MovieListRepository) AddItem(ctx context.Context, item domain.MovieListItem) (bool, error) {
+	ret := _m.Called(ctx, item)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddItem")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MovieListItem) (bool, error)); ok {
+		return rf(ctx, item)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MovieListItem) bool); ok {
+		r0 = rf(ctx, item)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MovieListItem) error); ok {
+		r1 = rf(ctx, item)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CopyItems provides a mock function with given fields: ctx, fromListID, toListID, addedByID
+func (_m *MovieListRepository) CopyItems(ctx context.Context, fromListID uint, toListID uint, addedByID uint) error {
+	ret := _m.Called(ctx, fromListID, toListID, addedByID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyItems")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, uint) error); ok {
+		r0 = rf(ctx, fromListID, toListID, addedByID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountItems provides a mock function with given fields: ctx, listID
+func (_m *MovieListRepository) CountItems(ctx context.Context, listID uint) (int64, error) {
+	ret := _m.Called(ctx, listID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountItems")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int64, error)); ok {
+		return rf(ctx, listID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, listID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, listID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, list
+func (_m *MovieListRepository) Create(ctx context.Context, list domain.MovieList) (*domain.MovieList, error) {
+	ret := _m.Called(ctx, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *domain.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MovieList) (*domain.MovieList, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.MovieList) *domain.MovieList); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.MovieList) error); ok {
+		r1 = rf(ctx, list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *MovieListRepository) Delete(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, id
+func (_m *MovieListRepository) Get(ctx context.Context, id uint) (*domain.MovieList, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.MovieList, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.MovieList); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByOwnerID provides a mock function with given fields: ctx, ownerID
+func (_m *MovieListRepository) GetByOwnerID(ctx context.Context, ownerID uint) ([]domain.MovieList, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByOwnerID")
+	}
+
+	var r0 []domain.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]domain.MovieList, error)); ok {
+		return rf(ctx, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []domain.MovieList); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBySlug provides a mock function with given fields: ctx, slug
+func (_m *MovieListRepository) GetBySlug(ctx context.Context, slug string) (*domain.MovieList, error) {
+	ret := _m.Called(ctx, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySlug")
+	}
+
+	var r0 *domain.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.MovieList, error)); ok {
+		return rf(ctx, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.MovieList); ok {
+		r0 = rf(ctx, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *MovieListRepository) List(ctx context.Context, filter repository.MovieListFilter) ([]domain.MovieListSummary, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.MovieListSummary
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieListFilter) ([]domain.MovieListSummary, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieListFilter) []domain.MovieListSummary); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MovieListSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.MovieListFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.MovieListFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListItems provides a mock function with given fields: ctx, listID, limit, offset
+func (_m *MovieListRepository) ListItems(ctx context.Context, listID uint, limit int, offset int) ([]domain.MovieListItem, int64, error) {
+	ret := _m.Called(ctx, listID, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListItems")
+	}
+
+	var r0 []domain.MovieListItem
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) ([]domain.MovieListItem, int64, error)); ok {
+		return rf(ctx, listID, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int, int) []domain.MovieListItem); ok {
+		r0 = rf(ctx, listID, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MovieListItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int, int) int64); ok {
+		r1 = rf(ctx, listID, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, int, int) error); ok {
+		r2 = rf(ctx, listID, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListMovieIDs provides a mock function with given fields: ctx, listID
+func (_m *MovieListRepository) ListMovieIDs(ctx context.Context, listID uint) ([]uint, error) {
+	ret := _m.Called(ctx, listID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMovieIDs")
+	}
+
+	var r0 []uint
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]uint, error)); ok {
+		return rf(ctx, listID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []uint); ok {
+		r0 = rf(ctx, listID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, listID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Lock provides a mock function with given fields: ctx, id
+func (_m *MovieListRepository) Lock(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lock")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveEditor provides a mock function with given fields: ctx, listID, userID
+func (_m *MovieListRepository) RemoveEditor(ctx context.Context, listID uint, userID uint) (bool, error) {
+	ret := _m.Called(ctx, listID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveEditor")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (bool, error)); ok {
+		return rf(ctx, listID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) bool); ok {
+		r0 = rf(ctx, listID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, listID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveItem provides a mock function with given fields: ctx, listID, movieID
+func (_m *MovieListRepository) RemoveItem(ctx context.Context, listID uint, movieID uint) (bool, error) {
+	ret := _m.Called(ctx, listID, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveItem")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (bool, error)); ok {
+		return rf(ctx, listID, movieID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) bool); ok {
+		r0 = rf(ctx, listID, movieID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, listID, movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reorder provides a mock function with given fields: ctx, listID, movieIDs
+func (_m *MovieListRepository) Reorder(ctx context.Context, listID uint, movieIDs []uint) error {
+	ret := _m.Called(ctx, listID, movieIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reorder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []uint) error); ok {
+		r0 = rf(ctx, listID, movieIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, id, changes
+func (_m *MovieListRepository) Update(ctx context.Context, id uint, changes map[string]interface{}) error {
+	ret := _m.Called(ctx, id, changes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, map[string]interface{}) error); ok {
+		r0 = rf(ctx, id, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateItemNote provides a mock function with given fields: ctx, listID, movieID, note
+func (_m *MovieListRepository) UpdateItemNote(ctx context.Context, listID uint, movieID uint, note string) (bool, error) {
+	ret := _m.Called(ctx, listID, movieID, note)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateItemNote")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string) (bool, error)); ok {
+		return rf(ctx, listID, movieID, note)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, string) bool); ok {
+		r0 = rf(ctx, listID, movieID, note)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, string) error); ok {
+		r1 = rf(ctx, listID, movieID, note)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMovieListRepository creates a new instance of MovieListRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMovieListRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MovieListRepository {
+	mock := &MovieListRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
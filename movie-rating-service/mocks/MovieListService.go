@@ -0,0 +1,355 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// MovieListService is an autogenerated mock type for the MovieListService type
+type MovieListService struct {
+	mock.Mock
+}
+
+// AddEditor provides a mock function with given fields: ctx, req
+func (_m *MovieListService) AddEditor(ctx context.Context, req request.AddMovieListEditor) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddEditor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.AddMovieListEditor) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddItem provides a mock function with given fields: ctx, req
+func (_m *MovieListService) AddItem(ctx context.Context, req request.AddMovieListItem) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.AddMovieListItem) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Browse provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Browse(ctx context.Context, req request.BrowseMovieLists) (*response.ListMovieLists, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Browse")
+	}
+
+	var r0 *response.ListMovieLists
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.BrowseMovieLists) (*response.ListMovieLists, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.BrowseMovieLists) *response.ListMovieLists); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListMovieLists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.BrowseMovieLists) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Clone provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Clone(ctx context.Context, req request.CloneMovieList) (*response.MovieList, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clone")
+	}
+
+	var r0 *response.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.CloneMovieList) (*response.MovieList, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.CloneMovieList) *response.MovieList); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.CloneMovieList) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Create(ctx context.Context, req request.CreateMovieList) (*response.MovieList, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *response.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.CreateMovieList) (*response.MovieList, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.CreateMovieList) *response.MovieList); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.CreateMovieList) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Delete(ctx context.Context, req request.DeleteMovieList) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.DeleteMovieList) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Get(ctx context.Context, req request.GetMovieList) (*response.MovieList, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *response.MovieList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.GetMovieList) (*response.MovieList, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.GetMovieList) *response.MovieList); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MovieList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.GetMovieList) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListItems provides a mock function with given fields: ctx, req
+func (_m *MovieListService) ListItems(ctx context.Context, req request.ListMovieListItems) (*response.ListMovieListItems, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListItems")
+	}
+
+	var r0 *response.ListMovieListItems
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovieListItems) (*response.ListMovieListItems, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovieListItems) *response.ListMovieListItems); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListMovieListItems)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListMovieListItems) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMine provides a mock function with given fields: ctx, req
+func (_m *MovieListService) ListMine(ctx context.Context, req request.ListMyMovieLists) (*response.ListMovieLists, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMine")
+	}
+
+	var r0 *response.ListMovieLists
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMyMovieLists) (*response.ListMovieLists, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMyMovieLists) *response.ListMovieLists); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListMovieLists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListMyMovieLists) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveEditor provides a mock function with given fields: ctx, req
+func (_m *MovieListService) RemoveEditor(ctx context.Context, req request.RemoveMovieListEditor) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveEditor")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RemoveMovieListEditor) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveItem provides a mock function with given fields: ctx, req
+func (_m *MovieListService) RemoveItem(ctx context.Context, req request.RemoveMovieListItem) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RemoveMovieListItem) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reorder provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Reorder(ctx context.Context, req request.ReorderMovieList) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reorder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ReorderMovieList) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, req
+func (_m *MovieListService) Update(ctx context.Context, req request.UpdateMovieList) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateMovieList) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateItem provides a mock function with given fields: ctx, req
+func (_m *MovieListService) UpdateItem(ctx context.Context, req request.UpdateMovieListItem) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateItem")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateMovieListItem) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMovieListService creates a new instance of MovieListService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMovieListService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MovieListService {
+	mock := &MovieListService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
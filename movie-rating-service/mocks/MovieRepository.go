@@ -6,9 +6,9 @@ import (
 	context "context"
 	domain "movie-rating-service/internal/domain"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
+
+	repository "movie-rating-service/internal/infrastructure/repository"
 )
 
 // MovieRepository is an autogenerated mock type for the MovieRepository type
@@ -16,24 +16,17 @@ type MovieRepository struct {
 	mock.Mock
 }
 
-// AddRating provides a mock function with given fields: ctx, movieID, score, tx
-func (_m *MovieRepository) AddRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movieID, score)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// AddRating provides a mock function with given fields: ctx, movieID, score
+func (_m *MovieRepository) AddRating(ctx context.Context, movieID uint, score float64) error {
+	ret := _m.Called(ctx, movieID, score)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddRating")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, movieID, score, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64) error); ok {
+		r0 = rf(ctx, movieID, score)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -41,16 +34,9 @@ func (_m *MovieRepository) AddRating(ctx context.Context, movieID uint, score fl
 	return r0
 }
 
-// Create provides a mock function with given fields: ctx, movie, tx
-func (_m *MovieRepository) Create(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) (*domain.Movie, error) {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movie)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// Create provides a mock function with given fields: ctx, movie
+func (_m *MovieRepository) Create(ctx context.Context, movie domain.Movie) (*domain.Movie, error) {
+	ret := _m.Called(ctx, movie)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -58,19 +44,19 @@ func (_m *MovieRepository) Create(ctx context.Context, movie domain.Movie, tx ..
 
 	var r0 *domain.Movie
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie, ...*gorm.DB) (*domain.Movie, error)); ok {
-		return rf(ctx, movie, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) (*domain.Movie, error)); ok {
+		return rf(ctx, movie)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie, ...*gorm.DB) *domain.Movie); ok {
-		r0 = rf(ctx, movie, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) *domain.Movie); ok {
+		r0 = rf(ctx, movie)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Movie)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Movie, ...*gorm.DB) error); ok {
-		r1 = rf(ctx, movie, tx...)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Movie) error); ok {
+		r1 = rf(ctx, movie)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -78,24 +64,17 @@ func (_m *MovieRepository) Create(ctx context.Context, movie domain.Movie, tx ..
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: ctx, movie, tx
-func (_m *MovieRepository) Delete(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movie)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// Delete provides a mock function with given fields: ctx, movie
+func (_m *MovieRepository) Delete(ctx context.Context, movie domain.Movie) error {
+	ret := _m.Called(ctx, movie)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, movie, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) error); ok {
+		r0 = rf(ctx, movie)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -103,24 +82,17 @@ func (_m *MovieRepository) Delete(ctx context.Context, movie domain.Movie, tx ..
 	return r0
 }
 
-// DeleteRating provides a mock function with given fields: ctx, movieID, score, tx
-func (_m *MovieRepository) DeleteRating(ctx context.Context, movieID uint, score float64, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movieID, score)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// DeleteRating provides a mock function with given fields: ctx, movieID, score
+func (_m *MovieRepository) DeleteRating(ctx context.Context, movieID uint, score float64) error {
+	ret := _m.Called(ctx, movieID, score)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteRating")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, movieID, score, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64) error); ok {
+		r0 = rf(ctx, movieID, score)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -128,6 +100,34 @@ func (_m *MovieRepository) DeleteRating(ctx context.Context, movieID uint, score
 	return r0
 }
 
+// FixRatingAggregate provides a mock function with given fields: ctx, aggregate
+func (_m *MovieRepository) FixRatingAggregate(ctx context.Context, aggregate domain.RatingAggregate) (bool, error) {
+	ret := _m.Called(ctx, aggregate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FixRatingAggregate")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RatingAggregate) (bool, error)); ok {
+		return rf(ctx, aggregate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RatingAggregate) bool); ok {
+		r0 = rf(ctx, aggregate)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.RatingAggregate) error); ok {
+		r1 = rf(ctx, aggregate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: ctx, id
 func (_m *MovieRepository) Get(ctx context.Context, id uint) (*domain.Movie, error) {
 	ret := _m.Called(ctx, id)
@@ -158,27 +158,92 @@ func (_m *MovieRepository) Get(ctx context.Context, id uint) (*domain.Movie, err
 	return r0, r1
 }
 
-// List provides a mock function with given fields: ctx
-func (_m *MovieRepository) List(ctx context.Context) ([]domain.Movie, error) {
-	ret := _m.Called(ctx)
+// List provides a mock function with given fields: ctx, filter
+func (_m *MovieRepository) List(ctx context.Context, filter repository.MovieFilter) ([]domain.Movie, int64, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for List")
 	}
 
 	var r0 []domain.Movie
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Movie, error)); ok {
-		return rf(ctx)
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieFilter) ([]domain.Movie, int64, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []domain.Movie); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MovieFilter) []domain.Movie); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Movie)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, repository.MovieFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.MovieFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListRatingAggregates provides a mock function with given fields: ctx, afterID, limit
+func (_m *MovieRepository) ListRatingAggregates(ctx context.Context, afterID uint, limit int) ([]domain.RatingAggregate, error) {
+	ret := _m.Called(ctx, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRatingAggregates")
+	}
+
+	var r0 []domain.RatingAggregate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) ([]domain.RatingAggregate, error)); ok {
+		return rf(ctx, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) []domain.RatingAggregate); ok {
+		r0 = rf(ctx, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RatingAggregate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int) error); ok {
+		r1 = rf(ctx, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RatingPrior provides a mock function with given fields: ctx
+func (_m *MovieRepository) RatingPrior(ctx context.Context) (float64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RatingPrior")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (float64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) float64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
 		r1 = rf(ctx)
 	} else {
@@ -188,24 +253,63 @@ func (_m *MovieRepository) List(ctx context.Context) ([]domain.Movie, error) {
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, movie, tx
-func (_m *MovieRepository) Update(ctx context.Context, movie domain.Movie, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
+// RecomputeRatingAggregate provides a mock function with given fields: ctx, movieID
+func (_m *MovieRepository) RecomputeRatingAggregate(ctx context.Context, movieID uint) error {
+	ret := _m.Called(ctx, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecomputeRatingAggregate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, movieID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshRatingPrior provides a mock function with given fields: ctx
+func (_m *MovieRepository) RefreshRatingPrior(ctx context.Context) (float64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshRatingPrior")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (float64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) float64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
 	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movie)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, movie
+func (_m *MovieRepository) Update(ctx context.Context, movie domain.Movie) error {
+	ret := _m.Called(ctx, movie)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, movie, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Movie) error); ok {
+		r0 = rf(ctx, movie)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -213,24 +317,35 @@ func (_m *MovieRepository) Update(ctx context.Context, movie domain.Movie, tx ..
 	return r0
 }
 
-// UpdateRating provides a mock function with given fields: ctx, movieID, oldScore, newScore, tx
-func (_m *MovieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore float64, newScore float64, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, movieID, oldScore, newScore)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// UpdateRating provides a mock function with given fields: ctx, movieID, oldScore, newScore
+func (_m *MovieRepository) UpdateRating(ctx context.Context, movieID uint, oldScore float64, newScore float64) error {
+	ret := _m.Called(ctx, movieID, oldScore, newScore)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateRating")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, float64, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, movieID, oldScore, newScore, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, float64) error); ok {
+		r0 = rf(ctx, movieID, oldScore, newScore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWatchlistCount provides a mock function with given fields: ctx, movieID, delta
+func (_m *MovieRepository) UpdateWatchlistCount(ctx context.Context, movieID uint, delta int) error {
+	ret := _m.Called(ctx, movieID, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWatchlistCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int) error); ok {
+		r0 = rf(ctx, movieID, delta)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -94,6 +94,156 @@ func (_m *MovieService) Get(ctx context.Context, req request.GetMovie) (*respons
 	return r0, r1
 }
 
+// GetStats provides a mock function with given fields: ctx, req
+func (_m *MovieService) GetStats(ctx context.Context, req request.GetMovieStats) (*response.MovieStats, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStats")
+	}
+
+	var r0 *response.MovieStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.GetMovieStats) (*response.MovieStats, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.GetMovieStats) *response.MovieStats); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.MovieStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.GetMovieStats) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, req
+func (_m *MovieService) List(ctx context.Context, req request.ListMovies) (*response.ListMovies, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *response.ListMovies
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovies) (*response.ListMovies, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovies) *response.ListMovies); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListMovies)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListMovies) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, req
+func (_m *MovieService) Search(ctx context.Context, req request.SearchMovies) (*response.SearchMovies, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 *response.SearchMovies
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.SearchMovies) (*response.SearchMovies, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.SearchMovies) *response.SearchMovies); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.SearchMovies)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.SearchMovies) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Suggest provides a mock function with given fields: ctx, req
+func (_m *MovieService) Suggest(ctx context.Context, req request.SuggestMovies) ([]response.SuggestMovie, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Suggest")
+	}
+
+	var r0 []response.SuggestMovie
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.SuggestMovies) ([]response.SuggestMovie, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.SuggestMovies) []response.SuggestMovie); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.SuggestMovie)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.SuggestMovies) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Top provides a mock function with given fields: ctx, req
+func (_m *MovieService) Top(ctx context.Context, req request.TopMovies) ([]response.GetMovie, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Top")
+	}
+
+	var r0 []response.GetMovie
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.TopMovies) ([]response.GetMovie, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.TopMovies) []response.GetMovie); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]response.GetMovie)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.TopMovies) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, req
 func (_m *MovieService) Update(ctx context.Context, req request.UpdateMovie) error {
 	ret := _m.Called(ctx, req)
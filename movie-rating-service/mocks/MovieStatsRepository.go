@@ -0,0 +1,145 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "movie-rating-service/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MovieStatsRepository is an autogenerated mock type for the MovieStatsRepository type
+type MovieStatsRepository struct {
+	mock.Mock
+}
+
+// AddScore provides a mock function with given fields: ctx, movieID, score, ratedAt
+func (_m *MovieStatsRepository) AddScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error {
+	ret := _m.Called(ctx, movieID, score, ratedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddScore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, time.Time) error); ok {
+		r0 = rf(ctx, movieID, score, ratedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBuckets provides a mock function with given fields: ctx, movieID
+func (_m *MovieStatsRepository) GetBuckets(ctx context.Context, movieID uint) ([]domain.MovieRatingBucket, error) {
+	ret := _m.Called(ctx, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBuckets")
+	}
+
+	var r0 []domain.MovieRatingBucket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]domain.MovieRatingBucket, error)); ok {
+		return rf(ctx, movieID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []domain.MovieRatingBucket); ok {
+		r0 = rf(ctx, movieID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MovieRatingBucket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPeriods provides a mock function with given fields: ctx, movieID
+func (_m *MovieStatsRepository) GetPeriods(ctx context.Context, movieID uint) ([]domain.MovieRatingPeriod, error) {
+	ret := _m.Called(ctx, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPeriods")
+	}
+
+	var r0 []domain.MovieRatingPeriod
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]domain.MovieRatingPeriod, error)); ok {
+		return rf(ctx, movieID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []domain.MovieRatingPeriod); ok {
+		r0 = rf(ctx, movieID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.MovieRatingPeriod)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveScore provides a mock function with given fields: ctx, movieID, score, ratedAt
+func (_m *MovieStatsRepository) RemoveScore(ctx context.Context, movieID uint, score float64, ratedAt time.Time) error {
+	ret := _m.Called(ctx, movieID, score, ratedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveScore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, time.Time) error); ok {
+		r0 = rf(ctx, movieID, score, ratedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceScore provides a mock function with given fields: ctx, movieID, oldScore, newScore, ratedAt
+func (_m *MovieStatsRepository) ReplaceScore(ctx context.Context, movieID uint, oldScore float64, newScore float64, ratedAt time.Time) error {
+	ret := _m.Called(ctx, movieID, oldScore, newScore, ratedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceScore")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, float64, time.Time) error); ok {
+		r0 = rf(ctx, movieID, oldScore, newScore, ratedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMovieStatsRepository creates a new instance of MovieStatsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMovieStatsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MovieStatsRepository {
+	mock := &MovieStatsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
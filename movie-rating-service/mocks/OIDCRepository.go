@@ -0,0 +1,163 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	domain "movie-rating-service/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OIDCRepository is an autogenerated mock type for the OIDCRepository type
+type OIDCRepository struct {
+	mock.Mock
+}
+
+// ConsumeLogin provides a mock function with given fields: ctx, state
+func (_m *OIDCRepository) ConsumeLogin(ctx context.Context, state string) (*domain.OIDCLogin, error) {
+	ret := _m.Called(ctx, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeLogin")
+	}
+
+	var r0 *domain.OIDCLogin
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.OIDCLogin, error)); ok {
+		return rf(ctx, state)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.OIDCLogin); ok {
+		r0 = rf(ctx, state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OIDCLogin)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateIdentity provides a mock function with given fields: ctx, identity
+func (_m *OIDCRepository) CreateIdentity(ctx context.Context, identity domain.UserIdentity) error {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserIdentity) error); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateLogin provides a mock function with given fields: ctx, login
+func (_m *OIDCRepository) CreateLogin(ctx context.Context, login domain.OIDCLogin) error {
+	ret := _m.Called(ctx, login)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.OIDCLogin) error); ok {
+		r0 = rf(ctx, login)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredLogins provides a mock function with given fields: ctx, before
+func (_m *OIDCRepository) DeleteExpiredLogins(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredLogins")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetIdentity provides a mock function with given fields: ctx, issuer, subject
+func (_m *OIDCRepository) GetIdentity(ctx context.Context, issuer string, subject string) (*domain.UserIdentity, error) {
+	ret := _m.Called(ctx, issuer, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentity")
+	}
+
+	var r0 *domain.UserIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*domain.UserIdentity, error)); ok {
+		return rf(ctx, issuer, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *domain.UserIdentity); ok {
+		r0 = rf(ctx, issuer, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, issuer, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TouchIdentity provides a mock function with given fields: ctx, identityID, at
+func (_m *OIDCRepository) TouchIdentity(ctx context.Context, identityID uint, at time.Time) error {
+	ret := _m.Called(ctx, identityID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, identityID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewOIDCRepository creates a new instance of OIDCRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOIDCRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OIDCRepository {
+	mock := &OIDCRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,121 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// PrivacyService is an autogenerated mock type for the PrivacyService type
+type PrivacyService struct {
+	mock.Mock
+}
+
+// Erase provides a mock function with given fields: ctx, req
+func (_m *PrivacyService) Erase(ctx context.Context, req request.EraseUser) (*response.Erasure, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Erase")
+	}
+
+	var r0 *response.Erasure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.EraseUser) (*response.Erasure, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.EraseUser) *response.Erasure); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.Erasure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.EraseUser) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EraseAccount provides a mock function with given fields: ctx, req
+func (_m *PrivacyService) EraseAccount(ctx context.Context, req request.EraseAccount) (*response.Erasure, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseAccount")
+	}
+
+	var r0 *response.Erasure
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.EraseAccount) (*response.Erasure, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.EraseAccount) *response.Erasure); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.Erasure)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.EraseAccount) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Export provides a mock function with given fields: ctx, req
+func (_m *PrivacyService) Export(ctx context.Context, req request.ExportUserData) (*response.UserExport, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 *response.UserExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ExportUserData) (*response.UserExport, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ExportUserData) *response.UserExport); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.UserExport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ExportUserData) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPrivacyService creates a new instance of PrivacyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPrivacyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PrivacyService {
+	mock := &PrivacyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
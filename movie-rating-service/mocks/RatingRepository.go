@@ -6,8 +6,6 @@ import (
 	context "context"
 	domain "movie-rating-service/internal/domain"
 
-	gorm "gorm.io/gorm"
-
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -16,16 +14,9 @@ type RatingRepository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: ctx, rating, tx
-func (_m *RatingRepository) Create(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) (*domain.Rating, error) {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, rating)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// Create provides a mock function with given fields: ctx, rating
+func (_m *RatingRepository) Create(ctx context.Context, rating domain.Rating) (*domain.Rating, error) {
+	ret := _m.Called(ctx, rating)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -33,19 +24,19 @@ func (_m *RatingRepository) Create(ctx context.Context, rating domain.Rating, tx
 
 	var r0 *domain.Rating
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating, ...*gorm.DB) (*domain.Rating, error)); ok {
-		return rf(ctx, rating, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating) (*domain.Rating, error)); ok {
+		return rf(ctx, rating)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating, ...*gorm.DB) *domain.Rating); ok {
-		r0 = rf(ctx, rating, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating) *domain.Rating); ok {
+		r0 = rf(ctx, rating)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Rating)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Rating, ...*gorm.DB) error); ok {
-		r1 = rf(ctx, rating, tx...)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Rating) error); ok {
+		r1 = rf(ctx, rating)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -53,24 +44,17 @@ func (_m *RatingRepository) Create(ctx context.Context, rating domain.Rating, tx
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: ctx, rating, tx
-func (_m *RatingRepository) Delete(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, rating)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// CreateHelpfulVote provides a mock function with given fields: ctx, vote
+func (_m *RatingRepository) CreateHelpfulVote(ctx context.Context, vote domain.RatingHelpfulVote) error {
+	ret := _m.Called(ctx, vote)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Delete")
+		panic("no return value specified for CreateHelpfulVote")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, rating, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RatingHelpfulVote) error); ok {
+		r0 = rf(ctx, vote)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -78,16 +62,95 @@ func (_m *RatingRepository) Delete(ctx context.Context, rating domain.Rating, tx
 	return r0
 }
 
-// GetByUserID provides a mock function with given fields: ctx, userID, tx
-func (_m *RatingRepository) GetByUserID(ctx context.Context, userID uint, tx ...*gorm.DB) ([]domain.Rating, error) {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
+// Delete provides a mock function with given fields: ctx, id
+func (_m *RatingRepository) Delete(ctx context.Context, id uint) (int64, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int64, error)); ok {
+		return rf(ctx, id)
 	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, userID)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EraseByUserID provides a mock function with given fields: ctx, userID
+func (_m *RatingRepository) EraseByUserID(ctx context.Context, userID uint) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseByUserID")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *RatingRepository) GetByID(ctx context.Context, id uint) (*domain.Rating, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByID")
+	}
+
+	var r0 *domain.Rating
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Rating, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Rating); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Rating)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUserID provides a mock function with given fields: ctx, userID
+func (_m *RatingRepository) GetByUserID(ctx context.Context, userID uint) ([]domain.Rating, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByUserID")
@@ -95,19 +158,19 @@ func (_m *RatingRepository) GetByUserID(ctx context.Context, userID uint, tx ...
 
 	var r0 []domain.Rating
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, ...*gorm.DB) ([]domain.Rating, error)); ok {
-		return rf(ctx, userID, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]domain.Rating, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint, ...*gorm.DB) []domain.Rating); ok {
-		r0 = rf(ctx, userID, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []domain.Rating); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Rating)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint, ...*gorm.DB) error); ok {
-		r1 = rf(ctx, userID, tx...)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -115,16 +178,9 @@ func (_m *RatingRepository) GetByUserID(ctx context.Context, userID uint, tx ...
 	return r0, r1
 }
 
-// GetByUserIDAndMovieID provides a mock function with given fields: ctx, userID, movieID, tx
-func (_m *RatingRepository) GetByUserIDAndMovieID(ctx context.Context, userID uint, movieID uint, tx ...*gorm.DB) (*domain.Rating, error) {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
-	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, userID, movieID)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+// GetByUserIDAndMovieID provides a mock function with given fields: ctx, userID, movieID
+func (_m *RatingRepository) GetByUserIDAndMovieID(ctx context.Context, userID uint, movieID uint) (*domain.Rating, error) {
+	ret := _m.Called(ctx, userID, movieID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByUserIDAndMovieID")
@@ -132,19 +188,134 @@ func (_m *RatingRepository) GetByUserIDAndMovieID(ctx context.Context, userID ui
 
 	var r0 *domain.Rating
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, ...*gorm.DB) (*domain.Rating, error)); ok {
-		return rf(ctx, userID, movieID, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (*domain.Rating, error)); ok {
+		return rf(ctx, userID, movieID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *domain.Rating); ok {
+		r0 = rf(ctx, userID, movieID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Rating)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, userID, movieID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHelpfulVotesByUserID provides a mock function with given fields: ctx, userID
+func (_m *RatingRepository) GetHelpfulVotesByUserID(ctx context.Context, userID uint) ([]domain.RatingHelpfulVote, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHelpfulVotesByUserID")
+	}
+
+	var r0 []domain.RatingHelpfulVote
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]domain.RatingHelpfulVote, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []domain.RatingHelpfulVote); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.RatingHelpfulVote)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementHelpfulCount provides a mock function with given fields: ctx, ratingID
+func (_m *RatingRepository) IncrementHelpfulCount(ctx context.Context, ratingID uint) error {
+	ret := _m.Called(ctx, ratingID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementHelpfulCount")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, ratingID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListByMovieID provides a mock function with given fields: ctx, movieID, sort, limit, offset
+func (_m *RatingRepository) ListByMovieID(ctx context.Context, movieID uint, sort string, limit int, offset int) ([]domain.Rating, int64, error) {
+	ret := _m.Called(ctx, movieID, sort, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByMovieID")
+	}
+
+	var r0 []domain.Rating
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, int, int) ([]domain.Rating, int64, error)); ok {
+		return rf(ctx, movieID, sort, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, int, int) []domain.Rating); ok {
+		r0 = rf(ctx, movieID, sort, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Rating)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, int, int) int64); ok {
+		r1 = rf(ctx, movieID, sort, limit, offset)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uint, string, int, int) error); ok {
+		r2 = rf(ctx, movieID, sort, limit, offset)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// LockByID provides a mock function with given fields: ctx, id
+func (_m *RatingRepository) LockByID(ctx context.Context, id uint) (*domain.Rating, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockByID")
+	}
+
+	var r0 *domain.Rating
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*domain.Rating, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, ...*gorm.DB) *domain.Rating); ok {
-		r0 = rf(ctx, userID, movieID, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *domain.Rating); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Rating)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint, uint, ...*gorm.DB) error); ok {
-		r1 = rf(ctx, userID, movieID, tx...)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -152,24 +323,47 @@ func (_m *RatingRepository) GetByUserIDAndMovieID(ctx context.Context, userID ui
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, rating, tx
-func (_m *RatingRepository) Update(ctx context.Context, rating domain.Rating, tx ...*gorm.DB) error {
-	_va := make([]interface{}, len(tx))
-	for _i := range tx {
-		_va[_i] = tx[_i]
+// LockByUserIDAndMovieID provides a mock function with given fields: ctx, userID, movieID
+func (_m *RatingRepository) LockByUserIDAndMovieID(ctx context.Context, userID uint, movieID uint) (*domain.Rating, error) {
+	ret := _m.Called(ctx, userID, movieID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockByUserIDAndMovieID")
+	}
+
+	var r0 *domain.Rating
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (*domain.Rating, error)); ok {
+		return rf(ctx, userID, movieID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) *domain.Rating); ok {
+		r0 = rf(ctx, userID, movieID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Rating)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, userID, movieID)
+	} else {
+		r1 = ret.Error(1)
 	}
-	var _ca []interface{}
-	_ca = append(_ca, ctx, rating)
-	_ca = append(_ca, _va...)
-	ret := _m.Called(_ca...)
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, rating
+func (_m *RatingRepository) Update(ctx context.Context, rating domain.Rating) error {
+	ret := _m.Called(ctx, rating)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating, ...*gorm.DB) error); ok {
-		r0 = rf(ctx, rating, tx...)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Rating) error); ok {
+		r0 = rf(ctx, rating)
 	} else {
 		r0 = ret.Error(0)
 	}
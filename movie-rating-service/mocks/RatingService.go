@@ -94,6 +94,54 @@ func (_m *RatingService) GetRatingsByUserID(ctx context.Context, req request.Get
 	return r0, r1
 }
 
+// ListByMovieID provides a mock function with given fields: ctx, req
+func (_m *RatingService) ListByMovieID(ctx context.Context, req request.ListMovieRatings) (*response.ListMovieRatings, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByMovieID")
+	}
+
+	var r0 *response.ListMovieRatings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovieRatings) (*response.ListMovieRatings, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListMovieRatings) *response.ListMovieRatings); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListMovieRatings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListMovieRatings) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Moderate provides a mock function with given fields: ctx, req
+func (_m *RatingService) Moderate(ctx context.Context, req request.ModerateRating) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Moderate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ModerateRating) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, req
 func (_m *RatingService) Update(ctx context.Context, req request.UpdateRating) (*response.UpdateRating, error) {
 	ret := _m.Called(ctx, req)
@@ -124,6 +172,24 @@ func (_m *RatingService) Update(ctx context.Context, req request.UpdateRating) (
 	return r0, r1
 }
 
+// VoteHelpful provides a mock function with given fields: ctx, req
+func (_m *RatingService) VoteHelpful(ctx context.Context, req request.VoteRatingHelpful) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoteHelpful")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.VoteRatingHelpful) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRatingService creates a new instance of RatingService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRatingService(t interface {
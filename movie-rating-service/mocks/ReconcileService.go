@@ -0,0 +1,61 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// ReconcileService is an autogenerated mock type for the ReconcileService type
+type ReconcileService struct {
+	mock.Mock
+}
+
+// Reconcile provides a mock function with given fields: ctx, req
+func (_m *ReconcileService) Reconcile(ctx context.Context, req request.Reconcile) (*response.Reconcile, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconcile")
+	}
+
+	var r0 *response.Reconcile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.Reconcile) (*response.Reconcile, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.Reconcile) *response.Reconcile); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.Reconcile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.Reconcile) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewReconcileService creates a new instance of ReconcileService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewReconcileService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ReconcileService {
+	mock := &ReconcileService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// RevocationChecker is an autogenerated mock type for the RevocationChecker type
+type RevocationChecker struct {
+	mock.Mock
+}
+
+// IsRevoked provides a mock function with given fields: ctx, jti, userID, issuedAt
+func (_m *RevocationChecker) IsRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, jti, userID, issuedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint, time.Time) (bool, error)); ok {
+		return rf(ctx, jti, userID, issuedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint, time.Time) bool); ok {
+		r0 = rf(ctx, jti, userID, issuedAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint, time.Time) error); ok {
+		r1 = rf(ctx, jti, userID, issuedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRevocationChecker creates a new instance of RevocationChecker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocationChecker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RevocationChecker {
+	mock := &RevocationChecker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	jwt "github.com/golang-jwt/jwt/v5"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Signer is an autogenerated mock type for the Signer type
+type Signer struct {
+	mock.Mock
+}
+
+// Sign provides a mock function with given fields: claims
+func (_m *Signer) Sign(claims jwt.Claims) (string, error) {
+	ret := _m.Called(claims)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Sign")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(jwt.Claims) (string, error)); ok {
+		return rf(claims)
+	}
+	if rf, ok := ret.Get(0).(func(jwt.Claims) string); ok {
+		r0 = rf(claims)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(jwt.Claims) error); ok {
+		r1 = rf(claims)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSigner creates a new instance of Signer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSigner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Signer {
+	mock := &Signer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,168 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// TwoFactorRepository is an autogenerated mock type for the TwoFactorRepository type
+type TwoFactorRepository struct {
+	mock.Mock
+}
+
+// DisableTOTP provides a mock function with given fields: ctx, userID
+func (_m *TwoFactorRepository) DisableTOTP(ctx context.Context, userID uint) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableTOTP provides a mock function with given fields: ctx, userID, at, step
+func (_m *TwoFactorRepository) EnableTOTP(ctx context.Context, userID uint, at time.Time, step int64) error {
+	ret := _m.Called(ctx, userID, at, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnableTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time, int64) error); ok {
+		r0 = rf(ctx, userID, at, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceRecoveryCodes provides a mock function with given fields: ctx, userID, hashes
+func (_m *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	ret := _m.Called(ctx, userID, hashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceRecoveryCodes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []string) error); ok {
+		r0 = rf(ctx, userID, hashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPendingSecret provides a mock function with given fields: ctx, userID, secret
+func (_m *TwoFactorRepository) SetPendingSecret(ctx context.Context, userID uint, secret []byte) (bool, error) {
+	ret := _m.Called(ctx, userID, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPendingSecret")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []byte) (bool, error)); ok {
+		return rf(ctx, userID, secret)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []byte) bool); ok {
+		r0 = rf(ctx, userID, secret)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, []byte) error); ok {
+		r1 = rf(ctx, userID, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseRecoveryCode provides a mock function with given fields: ctx, userID, hash, at
+func (_m *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string, at time.Time) (bool, error) {
+	ret := _m.Called(ctx, userID, hash, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) (bool, error)); ok {
+		return rf(ctx, userID, hash, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) bool); ok {
+		r0 = rf(ctx, userID, hash, at)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, time.Time) error); ok {
+		r1 = rf(ctx, userID, hash, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UseTOTPStep provides a mock function with given fields: ctx, userID, step
+func (_m *TwoFactorRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	ret := _m.Called(ctx, userID, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseTOTPStep")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) (bool, error)); ok {
+		return rf(ctx, userID, step)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, int64) bool); ok {
+		r0 = rf(ctx, userID, step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, int64) error); ok {
+		r1 = rf(ctx, userID, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTwoFactorRepository creates a new instance of TwoFactorRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTwoFactorRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TwoFactorRepository {
+	mock := &TwoFactorRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
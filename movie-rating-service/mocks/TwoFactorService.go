@@ -0,0 +1,199 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// TwoFactorService is an autogenerated mock type for the TwoFactorService type
+type TwoFactorService struct {
+	mock.Mock
+}
+
+// Challenge provides a mock function with given fields: ctx, user
+func (_m *TwoFactorService) Challenge(ctx context.Context, user response.GetUser) (*response.Login, error) {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Challenge")
+	}
+
+	var r0 *response.Login
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, response.GetUser) (*response.Login, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, response.GetUser) *response.Login); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.Login)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, response.GetUser) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Confirm provides a mock function with given fields: ctx, req
+func (_m *TwoFactorService) Confirm(ctx context.Context, req request.ConfirmTwoFactor) (*response.RecoveryCodes, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Confirm")
+	}
+
+	var r0 *response.RecoveryCodes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ConfirmTwoFactor) (*response.RecoveryCodes, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ConfirmTwoFactor) *response.RecoveryCodes); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.RecoveryCodes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ConfirmTwoFactor) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Disable provides a mock function with given fields: ctx, req
+func (_m *TwoFactorService) Disable(ctx context.Context, req request.DisableTwoFactor) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Disable")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.DisableTwoFactor) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Enroll provides a mock function with given fields: ctx, req
+func (_m *TwoFactorService) Enroll(ctx context.Context, req request.EnrollTwoFactor) (*response.TwoFactorEnrollment, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enroll")
+	}
+
+	var r0 *response.TwoFactorEnrollment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.EnrollTwoFactor) (*response.TwoFactorEnrollment, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.EnrollTwoFactor) *response.TwoFactorEnrollment); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.TwoFactorEnrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.EnrollTwoFactor) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegenerateRecoveryCodes provides a mock function with given fields: ctx, req
+func (_m *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, req request.RegenerateRecoveryCodes) (*response.RecoveryCodes, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegenerateRecoveryCodes")
+	}
+
+	var r0 *response.RecoveryCodes
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RegenerateRecoveryCodes) (*response.RecoveryCodes, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.RegenerateRecoveryCodes) *response.RecoveryCodes); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.RecoveryCodes)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.RegenerateRecoveryCodes) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyLogin provides a mock function with given fields: ctx, req
+func (_m *TwoFactorService) VerifyLogin(ctx context.Context, req request.LoginTwoFactor) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyLogin")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.LoginTwoFactor) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.LoginTwoFactor) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.LoginTwoFactor) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTwoFactorService creates a new instance of TwoFactorService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTwoFactorService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TwoFactorService {
+	mock := &TwoFactorService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
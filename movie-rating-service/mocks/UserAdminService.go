@@ -0,0 +1,169 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// UserAdminService is an autogenerated mock type for the UserAdminService type
+type UserAdminService struct {
+	mock.Mock
+}
+
+// ForcePasswordReset provides a mock function with given fields: ctx, req
+func (_m *UserAdminService) ForcePasswordReset(ctx context.Context, req request.ForcePasswordReset) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForcePasswordReset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ForcePasswordReset) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GrantRole provides a mock function with given fields: ctx, req
+func (_m *UserAdminService) GrantRole(ctx context.Context, req request.UserRole) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantRole")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserRole) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserRole) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.UserRole) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, req
+func (_m *UserAdminService) List(ctx context.Context, req request.ListUsers) (*response.ListUsers, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *response.ListUsers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListUsers) (*response.ListUsers, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListUsers) *response.ListUsers); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListUsers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListUsers) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeRole provides a mock function with given fields: ctx, req
+func (_m *UserAdminService) RevokeRole(ctx context.Context, req request.UserRole) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRole")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserRole) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.UserRole) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.UserRole) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, req
+func (_m *UserAdminService) UpdateStatus(ctx context.Context, req request.UpdateUserStatus) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateUserStatus) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateUserStatus) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.UpdateUserStatus) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewUserAdminService creates a new instance of UserAdminService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserAdminService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserAdminService {
+	mock := &UserAdminService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
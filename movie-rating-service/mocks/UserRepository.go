@@ -7,6 +7,10 @@ import (
 	domain "movie-rating-service/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	repository "movie-rating-service/internal/infrastructure/repository"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -14,6 +18,42 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// AddRole provides a mock function with given fields: ctx, userID, roleID
+func (_m *UserRepository) AddRole(ctx context.Context, userID uint, roleID uint) error {
+	ret := _m.Called(ctx, userID, roleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, userID, roleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Anonymize provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) Anonymize(ctx context.Context, userID uint) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Anonymize")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, user
 func (_m *UserRepository) Create(ctx context.Context, user domain.User) (*domain.User, error) {
 	ret := _m.Called(ctx, user)
@@ -44,6 +84,24 @@ func (_m *UserRepository) Create(ctx context.Context, user domain.User) (*domain
 	return r0, r1
 }
 
+// Erase provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) Erase(ctx context.Context, userID uint) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Erase")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetByID provides a mock function with given fields: ctx, userID
 func (_m *UserRepository) GetByID(ctx context.Context, userID uint) (*domain.User, error) {
 	ret := _m.Called(ctx, userID)
@@ -104,6 +162,245 @@ func (_m *UserRepository) GetByUsername(ctx context.Context, username string) (*
 	return r0, r1
 }
 
+// GetTokensRevokedAt provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) GetTokensRevokedAt(ctx context.Context, userID uint) (time.Time, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokensRevokedAt")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (time.Time, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) time.Time); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, filter
+func (_m *UserRepository) List(ctx context.Context, filter repository.UserFilter) ([]domain.User, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.UserFilter) ([]domain.User, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.UserFilter) []domain.User); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.UserFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repository.UserFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListByEmail provides a mock function with given fields: ctx, email
+func (_m *UserRepository) ListByEmail(ctx context.Context, email string) ([]domain.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByEmail")
+	}
+
+	var r0 []domain.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkEmailVerified provides a mock function with given fields: ctx, userID, at
+func (_m *UserRepository) MarkEmailVerified(ctx context.Context, userID uint, at time.Time) error {
+	ret := _m.Called(ctx, userID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkEmailVerified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, userID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RehashPassword provides a mock function with given fields: ctx, userID, oldHash, newHash
+func (_m *UserRepository) RehashPassword(ctx context.Context, userID uint, oldHash string, newHash string) error {
+	ret := _m.Called(ctx, userID, oldHash, newHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RehashPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, userID, oldHash, newHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveRole provides a mock function with given fields: ctx, userID, roleID
+func (_m *UserRepository) RemoveRole(ctx context.Context, userID uint, roleID uint) error {
+	ret := _m.Called(ctx, userID, roleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, userID, roleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeTokens provides a mock function with given fields: ctx, userID, at
+func (_m *UserRepository) RevokeTokens(ctx context.Context, userID uint, at time.Time) error {
+	ret := _m.Called(ctx, userID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeTokens")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, userID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPasswordResetRequired provides a mock function with given fields: ctx, userID, required
+func (_m *UserRepository) SetPasswordResetRequired(ctx context.Context, userID uint, required bool) error {
+	ret := _m.Called(ctx, userID, required)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPasswordResetRequired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, bool) error); ok {
+		r0 = rf(ctx, userID, required)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, userID, hash
+func (_m *UserRepository) UpdatePassword(ctx context.Context, userID uint, hash string) error {
+	ret := _m.Called(ctx, userID, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, userID, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProfile provides a mock function with given fields: ctx, userID, changes
+func (_m *UserRepository) UpdateProfile(ctx context.Context, userID uint, changes map[string]interface{}) error {
+	ret := _m.Called(ctx, userID, changes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, map[string]interface{}) error); ok {
+		r0 = rf(ctx, userID, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, userID, status, reason
+func (_m *UserRepository) UpdateStatus(ctx context.Context, userID uint, status string, reason string) error {
+	ret := _m.Called(ctx, userID, status, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, userID, status, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
@@ -16,6 +16,36 @@ type UserService struct {
 	mock.Mock
 }
 
+// ChangePassword provides a mock function with given fields: ctx, req
+func (_m *UserService) ChangePassword(ctx context.Context, req request.ChangePassword) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ChangePassword) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ChangePassword) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ChangePassword) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, req
 func (_m *UserService) Create(ctx context.Context, req request.CreateUser) (*response.CreateUser, error) {
 	ret := _m.Called(ctx, req)
@@ -46,6 +76,24 @@ func (_m *UserService) Create(ctx context.Context, req request.CreateUser) (*res
 	return r0, r1
 }
 
+// Delete provides a mock function with given fields: ctx, req
+func (_m *UserService) Delete(ctx context.Context, req request.DeleteAccount) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.DeleteAccount) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, req
 func (_m *UserService) Get(ctx context.Context, req request.GetUser) (*response.GetUser, error) {
 	ret := _m.Called(ctx, req)
@@ -106,6 +154,36 @@ func (_m *UserService) IsAuthorized(ctx context.Context, req request.Login) (*re
 	return r0, r1
 }
 
+// UpdateProfile provides a mock function with given fields: ctx, req
+func (_m *UserService) UpdateProfile(ctx context.Context, req request.UpdateProfile) (*response.GetUser, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProfile")
+	}
+
+	var r0 *response.GetUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateProfile) (*response.GetUser, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.UpdateProfile) *response.GetUser); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.GetUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.UpdateProfile) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {
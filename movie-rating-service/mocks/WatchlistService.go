@@ -0,0 +1,115 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	request "movie-rating-service/internal/application/models/request"
+
+	mock "github.com/stretchr/testify/mock"
+
+	response "movie-rating-service/internal/application/models/response"
+)
+
+// WatchlistService is an autogenerated mock type for the WatchlistService type
+type WatchlistService struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, req
+func (_m *WatchlistService) Add(ctx context.Context, req request.AddToWatchlist) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.AddToWatchlist) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// List provides a mock function with given fields: ctx, req
+func (_m *WatchlistService) List(ctx context.Context, req request.ListWatchlist) (*response.ListWatchlist, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 *response.ListWatchlist
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListWatchlist) (*response.ListWatchlist, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, request.ListWatchlist) *response.ListWatchlist); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*response.ListWatchlist)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, request.ListWatchlist) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Remove provides a mock function with given fields: ctx, req
+func (_m *WatchlistService) Remove(ctx context.Context, req request.RemoveFromWatchlist) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Remove")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.RemoveFromWatchlist) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reorder provides a mock function with given fields: ctx, req
+func (_m *WatchlistService) Reorder(ctx context.Context, req request.ReorderWatchlist) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reorder")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, request.ReorderWatchlist) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWatchlistService creates a new instance of WatchlistService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWatchlistService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WatchlistService {
+	mock := &WatchlistService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}